@@ -0,0 +1,124 @@
+package configo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// aliasedField is a field tagged with `alias:"..."`, the old names of a
+// renamed key.
+type aliasedField struct {
+	BindKey string
+	// Aliases holds the bind keys of the old names, next to BindKey.
+	Aliases []string
+}
+
+// aliasedFields collects the fields with aliases, descending into nested
+// structs and pointer sections.
+func aliasedFields(t reflect.Type, parentBindKey string, expanding walker.Expanding) []aliasedField {
+	expanding[t] = true
+	defer delete(expanding, t)
+
+	var fields []aliasedField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported and ignored fields
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		if isFlattenedField(field) {
+			fields = append(fields, aliasedFields(field.Type, parentBindKey, expanding)...)
+			continue
+		}
+
+		bindKey := childBindKey(field, parentBindKey)
+
+		if tag := field.Tag.Get("alias"); tag != "" {
+			f := aliasedField{BindKey: bindKey}
+			for _, alias := range strings.Split(tag, ",") {
+				if alias = strings.TrimSpace(alias); alias != "" {
+					f.Aliases = append(f.Aliases, childBindKey(reflect.StructField{Name: alias}, parentBindKey))
+				}
+			}
+			fields = append(fields, f)
+		}
+
+		switch {
+		case field.Type.Kind() == reflect.Struct && field.Type != timeType && !walker.TextType(field.Type):
+			fields = append(fields, aliasedFields(field.Type, bindKey, expanding)...)
+		case walker.SectionPointer(field.Type) && !expanding.Recursive(field.Type):
+			fields = append(fields, aliasedFields(field.Type.Elem(), bindKey, expanding)...)
+		}
+	}
+
+	return fields
+}
+
+// applyAliases copies the values the config file sets under the old names
+// of renamed keys to their current keys, so that they decode into the same
+// field with the priority of the file. When both names are set the current
+// key wins. Either way a deprecation warning is returned along with the
+// bind keys of the aliases, which are not unknown keys in strict mode.
+func applyAliases(v *viper.Viper, t reflect.Type) ([]Warning, []string, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil, nil
+	}
+
+	var warnings []Warning
+	var aliases []string
+	for _, f := range aliasedFields(t, "", walker.Expanding{}) {
+		for _, alias := range f.Aliases {
+			aliases = append(aliases, alias)
+			if !v.InConfig(alias) {
+				continue
+			}
+			if v.InConfig(f.BindKey) {
+				warnings = append(warnings, Warning{
+					Path:    f.BindKey,
+					Message: fmt.Sprintf("deprecated: old key %q is ignored, %q is set", alias, f.BindKey),
+				})
+				continue
+			}
+			if err := v.MergeConfigMap(nestedValue(f.BindKey, v.Get(alias))); err != nil {
+				return nil, nil, fmt.Errorf("%w: %s: %w", ConfigParsingError, alias, err)
+			}
+			warnings = append(warnings, Warning{
+				Path:    f.BindKey,
+				Message: fmt.Sprintf("deprecated: set under the old key %q, rename it to %q", alias, f.BindKey),
+			})
+		}
+	}
+	return warnings, aliases, nil
+}
+
+// nestedValue returns value as a nested map under the dotted key, e.g.
+// {"meta": {"version": value}} for "meta.version".
+func nestedValue(key string, value interface{}) map[string]interface{} {
+	parts := strings.Split(key, ".")
+	m := map[string]interface{}{parts[len(parts)-1]: value}
+	for i := len(parts) - 2; i >= 0; i-- {
+		m = map[string]interface{}{parts[i]: m}
+	}
+	return m
+}
+
+// withoutAliases drops the keys set under an alias, or below one, from the
+// unused keys reported by the decoder.
+func withoutAliases(unused, aliases []string) []string {
+	var keys []string
+	for _, key := range unused {
+		if !underAnyKey(key, aliases) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,230 @@
+package configo
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"github.com/vsysa/configo/internal/parser/walker"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// CanonicalizeOption configures a single call to Canonicalize.
+type CanonicalizeOption func(*canonicalizer)
+
+type canonicalizer struct {
+	keepUnknown bool
+}
+
+// WithUnknownKeysKept makes Canonicalize keep the keys that don't map to any
+// struct field instead of failing. They follow the known keys of their
+// mapping in their original order.
+func WithUnknownKeysKept() CanonicalizeOption {
+	return func(c *canonicalizer) {
+		c.keepUnknown = true
+	}
+}
+
+// Canonicalize rewrites a YAML config file for the config struct cfg in a
+// canonical form, so that reviews of config changes only show real changes:
+//
+//   - keys are ordered like the struct fields and written as the struct
+//     names them, e.g. "Host" becomes "host";
+//   - keys of maps are sorted;
+//   - scalars are quoted only when needed and multi-line strings are written
+//     as literal blocks;
+//   - comments are removed and mappings are indented by two spaces.
+//
+// Only the keys set in the file are written; defaults are not added, and
+// values are kept as written, e.g. "30s" stays "30s". Elements of slices keep
+// their order, and so do the keys of opaque interface{} values.
+//
+// Keys that don't map to any struct field are reported in a single
+// UnknownKeysError, like WithStrict does for Load, unless
+// WithUnknownKeysKept is given. A file whose values don't decode into cfg
+// gives a ConfigParsingError.
+func Canonicalize(cfg interface{}, yamlBytes []byte, opts ...CanonicalizeOption) ([]byte, error) {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected a struct, got %T", ConfigParsingError, cfg)
+	}
+	c := &canonicalizer{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(yamlBytes, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	if len(doc.Content) == 0 {
+		return []byte{}, nil
+	}
+
+	// The file is decoded like Load decodes it, which finds the unknown keys
+	// and the values of the wrong type.
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(yamlBytes)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	if err := parseEncodedFields(v, t, ""); err != nil {
+		return nil, err
+	}
+	var metadata mapstructure.Metadata
+	collectMetadata := func(c *mapstructure.DecoderConfig) {
+		c.Metadata = &metadata
+	}
+	if err := v.Unmarshal(reflect.New(t).Interface(), decoderConfig, collectMetadata); err != nil {
+		return nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	if !c.keepUnknown && len(metadata.Unused) > 0 {
+		return nil, fmt.Errorf("%w: %s", UnknownKeysError, strings.Join(unknownKeys(metadata.Unused), ", "))
+	}
+
+	root := doc.Content[0]
+	canonicalNode(root, t)
+
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// canonicalField is a key of a struct mapping and the type of its value.
+type canonicalField struct {
+	Key  string
+	Type reflect.Type
+}
+
+// canonicalNode rewrites a node holding a value of type t, or of any type if
+// t is nil, in place.
+func canonicalNode(node *yamlv3.Node, t reflect.Type) {
+	node.HeadComment, node.LineComment, node.FootComment = "", "", ""
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && (t.Kind() == reflect.Interface || isTextValue(t)) {
+		t = nil
+	}
+
+	switch node.Kind {
+	case yamlv3.ScalarNode:
+		node.Style = 0
+		if node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+			node.Style = yamlv3.LiteralStyle
+		}
+
+	case yamlv3.SequenceNode:
+		node.Style = 0
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for _, item := range node.Content {
+			canonicalNode(item, elem)
+		}
+
+	case yamlv3.MappingNode:
+		node.Style = 0
+		switch {
+		case t == nil:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				canonicalNode(node.Content[i], nil)
+				canonicalNode(node.Content[i+1], nil)
+			}
+		case t.Kind() == reflect.Map:
+			canonicalMap(node, t)
+		case t.Kind() == reflect.Struct:
+			canonicalStruct(node, structFields(t))
+		}
+	}
+}
+
+// canonicalStruct orders the keys of a struct mapping like the fields. Keys
+// without a field, kept with WithUnknownKeysKept, go last.
+func canonicalStruct(node *yamlv3.Node, fields []canonicalField) {
+	content := make([]*yamlv3.Node, 0, len(node.Content))
+	used := make([]bool, len(node.Content))
+	for _, field := range fields {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if used[i] || !strings.EqualFold(node.Content[i].Value, field.Key) {
+				continue
+			}
+			used[i] = true
+			key, value := node.Content[i], node.Content[i+1]
+			canonicalNode(key, nil)
+			key.Value = field.Key
+			canonicalNode(value, field.Type)
+			content = append(content, key, value)
+		}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !used[i] {
+			canonicalNode(node.Content[i], nil)
+			canonicalNode(node.Content[i+1], nil)
+			content = append(content, node.Content[i], node.Content[i+1])
+		}
+	}
+	node.Content = content
+}
+
+// canonicalMap sorts the keys of a map mapping.
+func canonicalMap(node *yamlv3.Node, t reflect.Type) {
+	type entry struct{ key, value *yamlv3.Node }
+	entries := make([]entry, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		canonicalNode(node.Content[i], nil)
+		canonicalNode(node.Content[i+1], t.Elem())
+		entries = append(entries, entry{node.Content[i], node.Content[i+1]})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key.Value < entries[j].key.Value
+	})
+	node.Content = node.Content[:0]
+	for _, e := range entries {
+		node.Content = append(node.Content, e.key, e.value)
+	}
+}
+
+// structFields lists the keys of a struct in field order, with the fields
+// of embedded and squashed structs in place of the struct.
+func structFields(t reflect.Type) []canonicalField {
+	var fields []canonicalField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+		if isFlattenedField(field) {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			fields = append(fields, structFields(embedded)...)
+			continue
+		}
+		fields = append(fields, canonicalField{Key: childBindKey(field, ""), Type: field.Type})
+	}
+	return fields
+}
+
+// isTextValue reports whether values of type t are configured as a single
+// string although t is a struct or a slice, e.g. time.Time or url.URL.
+func isTextValue(t reflect.Type) bool {
+	return t == timeType || t == bytesType || walker.TextType(t) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
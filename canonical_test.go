@@ -0,0 +1,117 @@
+package configo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type CanonicalServer struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
+
+type CanonicalBase struct {
+	Name string `mapstructure:"name"`
+}
+
+type CanonicalTestConfig struct {
+	CanonicalBase `mapstructure:",squash"`
+	Version       string                 `mapstructure:"version"`
+	Timeout       time.Duration          `mapstructure:"timeout"`
+	Started       time.Time              `mapstructure:"started"`
+	Servers       []CanonicalServer      `mapstructure:"servers"`
+	Labels        map[string]string      `mapstructure:"labels"`
+	Banner        string                 `mapstructure:"banner"`
+	Extra         map[string]interface{} `mapstructure:"extra"`
+}
+
+func TestCanonicalize(t *testing.T) {
+	input := `# Servers first
+servers:
+  - port: 8080 # main
+    Host: 'a.example.com'
+labels: {zone: "b", env: 'prod'}
+version: "1.0"
+Name: "app"
+banner: "Hello\nWorld\n"
+timeout: 30s
+started: 2024-01-01T00:00:00Z
+extra:
+  z: 1
+  a: [x, "y"]
+`
+	expected := `name: app
+version: "1.0"
+timeout: 30s
+started: 2024-01-01T00:00:00Z
+servers:
+  - host: a.example.com
+    port: 8080
+labels:
+  env: prod
+  zone: b
+banner: |
+  Hello
+  World
+extra:
+  a:
+    - x
+    - y
+  z: 1
+`
+	out, err := Canonicalize(CanonicalTestConfig{}, []byte(input))
+	if err != nil {
+		t.Fatalf("Failed to canonicalize: %v", err)
+	}
+	if string(out) != expected {
+		t.Errorf("Unexpected canonical form:\n%s\nexpected:\n%s", out, expected)
+	}
+
+	// Каноническая форма не меняется при повторном применении
+	again, err := Canonicalize(&CanonicalTestConfig{}, out)
+	if err != nil {
+		t.Fatalf("Failed to canonicalize twice: %v", err)
+	}
+	if string(again) != string(out) {
+		t.Errorf("Canonical form is not stable:\n%s", again)
+	}
+}
+
+func TestCanonicalize_UnknownKeys(t *testing.T) {
+	input := "version: \"1.0\"\nverson: \"2.0\"\nservers:\n  - hots: a\n"
+
+	_, err := Canonicalize(CanonicalTestConfig{}, []byte(input))
+	if !errors.Is(err, UnknownKeysError) {
+		t.Fatalf("Expected UnknownKeysError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "servers.0.hots, verson") {
+		t.Errorf("Expected the unknown keys in the error, got %v", err)
+	}
+
+	out, err := Canonicalize(CanonicalTestConfig{}, []byte(input), WithUnknownKeysKept())
+	if err != nil {
+		t.Fatalf("Failed to canonicalize: %v", err)
+	}
+	expected := "version: \"1.0\"\nservers:\n  - hots: a\nverson: \"2.0\"\n"
+	if string(out) != expected {
+		t.Errorf("Expected unknown keys to be kept last, got:\n%s", out)
+	}
+}
+
+func TestCanonicalize_Errors(t *testing.T) {
+	if _, err := Canonicalize("not a struct", []byte("a: 1\n")); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for a non-struct, got %v", err)
+	}
+	if _, err := Canonicalize(CanonicalTestConfig{}, []byte("version: [\n")); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for invalid YAML, got %v", err)
+	}
+	if _, err := Canonicalize(CanonicalTestConfig{}, []byte("servers: 5\n")); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for a value of the wrong type, got %v", err)
+	}
+	out, err := Canonicalize(CanonicalTestConfig{}, nil)
+	if err != nil || len(out) != 0 {
+		t.Errorf("Expected an empty file to stay empty, got %q, %v", out, err)
+	}
+}
@@ -5,14 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"github.com/vsysa/configo/internal/parser/defaultValues"
 	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/walker"
 	"github.com/vsysa/configo/notifier"
 )
 
@@ -103,9 +107,19 @@ func (r *ConfigManager[T]) loadConfig() (*T, error) {
 	}
 
 	var cfg T
-	if err := Viper.Unmarshal(&cfg); err != nil {
+	if _, _, err := applyAliases(Viper, reflect.TypeOf(cfg)); err != nil {
+		return nil, err
+	}
+	if err := setSectionDefaults(Viper, cfg); err != nil {
+		return nil, err
+	}
+	if err := parseEncodedFields(Viper, reflect.TypeOf(cfg), ""); err != nil {
+		return nil, err
+	}
+	if err := Viper.Unmarshal(&cfg, decoderConfig); err != nil {
 		return nil, fmt.Errorf("Unable to decode into struct: %v", err)
 	}
+	allocateEnabledSections(reflect.ValueOf(&cfg))
 
 	if err := callValidateIfExists(cfg); err != nil {
 		return nil, fmt.Errorf("Validation error: %w", err)
@@ -123,16 +137,20 @@ func (r *ConfigManager[T]) setupViper(configPath string) error {
 	Viper.SetConfigFile(configPath)
 
 	var configStruct T
-	defaults, err := defaultValues.GetDefaultValues(configStruct)
-	if err != nil {
-		return fmt.Errorf("%w: %w", ConfigParsingError, err)
-	}
-	for _, v := range defaults {
-		Viper.SetDefault(v.BindKey, v.DefaultValue)
+	return bindDefaultsAndEnv(Viper, configStruct, env.Naming{})
+}
+
+// bindDefaultsAndEnv registers the `default` tag values of cfg and binds
+// every field to its environment variable, named according to naming, e.g.
+// MYAPP_META_VERSION. Fixed names from an `env:"NAME,fixed"` tag are never
+// prefixed.
+func bindDefaultsAndEnv(v *viper.Viper, cfg interface{}, naming env.Naming) error {
+	if err := setDefaults(v, cfg); err != nil {
+		return err
 	}
 
-	for _, v := range env.GetEnvs(configStruct) {
-		err := Viper.BindEnv(v.BindKey, v.EnvVar)
+	for _, e := range env.GetEnvsWithNaming(cfg, naming) {
+		err := v.BindEnv(e.BindKey, e.EnvVar)
 		if err != nil {
 			return fmt.Errorf("error binding env var: %w", err)
 		}
@@ -141,6 +159,89 @@ func (r *ConfigManager[T]) setupViper(configPath string) error {
 	return nil
 }
 
+// setDefaults registers the `default` tag values of cfg.
+func setDefaults(v *viper.Viper, cfg interface{}) error {
+	defaults, err := defaultValues.GetDefaultValues(cfg)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	for _, d := range defaults {
+		if d.Section == "" {
+			v.SetDefault(d.BindKey, d.DefaultValue)
+		}
+	}
+	return nil
+}
+
+// setSectionDefaults registers the `default` tag values of the fields of
+// optional sections, i.e. pointers to structs without `default:"enabled"`,
+// that a source sets. Sections no source sets are left out, so they stay nil.
+func setSectionDefaults(v *viper.Viper, cfg interface{}) error {
+	defaults, err := defaultValues.GetDefaultValues(cfg)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+
+	// Sections are checked before any of their defaults is registered, which
+	// would make them look set.
+	set := make(map[string]bool)
+	for _, d := range defaults {
+		if _, ok := set[d.Section]; d.Section != "" && !ok {
+			set[d.Section] = isKeySet(v, d.Section)
+		}
+	}
+	for _, d := range defaults {
+		if set[d.Section] {
+			v.SetDefault(d.BindKey, d.DefaultValue)
+		}
+	}
+	return nil
+}
+
+// isKeySet reports whether key, or any key below it, is set in v.
+func isKeySet(v *viper.Viper, key string) bool {
+	if v.IsSet(key) {
+		return true
+	}
+	for _, k := range v.AllKeys() {
+		if strings.HasPrefix(k, key+".") && v.IsSet(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// allocateEnabledSections allocates the nil pointers to structs tagged with
+// `default:"enabled"` in the struct v, which have no value to decode when
+// none of their fields has a default or is set.
+func allocateEnabledSections(v reflect.Value) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+		if walker.SectionPointer(field.Type) {
+			if fv := v.Field(i); fv.IsNil() && field.Tag.Get("default") == defaultValues.EnabledSection {
+				fv.Set(reflect.New(field.Type.Elem()))
+			}
+		}
+		if field.Type.Kind() == reflect.Struct || walker.SectionPointer(field.Type) {
+			allocateEnabledSections(v.Field(i))
+		}
+	}
+}
+
 func (r *ConfigManager[T]) setupWatcher() {
 	Viper := r.v
 	Viper.OnConfigChange(func(e fsnotify.Event) {
@@ -161,6 +262,117 @@ func (r *ConfigManager[T]) setupWatcher() {
 	Viper.WatchConfig()
 }
 
+// decoderConfig makes mapstructure decode untagged embedded structs as if
+// their fields were declared in the parent struct, matching the generated
+// templates, parse strings into registered types (see RegisterType) and
+// RFC3339 strings into time.Time fields, take strings as raw bytes for []byte
+// fields and parse the string forms of url.URL, net.IP and net.IPNet.
+func decoderConfig(c *mapstructure.DecoderConfig) {
+	c.DecodeHook = mapstructure.ComposeDecodeHookFunc(
+		embeddedFieldsHook,
+		stringToRegisteredTypeHook,
+		stringToBytesHook,
+		stringToURLHook,
+		mapstructure.StringToIPHookFunc(),
+		mapstructure.StringToIPNetHookFunc(),
+		c.DecodeHook,
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+	)
+}
+
+// embeddedFieldsHook moves the keys of the fields of untagged embedded
+// structs (see isFlattenedField) under the name of the embedded field, where
+// mapstructure looks them up. Fields tagged with `mapstructure:",squash"` are
+// decoded at the parent level by mapstructure itself, and embedded structs
+// with a name stay nested. A key the parent decodes too is copied rather than
+// moved, so that both fields get the value.
+func embeddedFieldsHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.Map || to.Kind() != reflect.Struct {
+		return data, nil
+	}
+	values, ok := data.(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+
+	parentKeys := decodedKeys(to, false)
+	var moved map[string]interface{}
+	for i := 0; i < to.NumField(); i++ {
+		field := to.Field(i)
+		if !field.Anonymous || !isFlattenedField(field) || isSquashed(field) {
+			continue
+		}
+		embedded := make(map[string]interface{})
+		embeddedKeys := decodedKeys(field.Type, true)
+		for key, value := range values {
+			if embeddedKeys[strings.ToLower(key)] {
+				embedded[key] = value
+			}
+		}
+		if len(embedded) == 0 {
+			continue
+		}
+		if moved == nil {
+			moved = make(map[string]interface{}, len(values))
+			for key, value := range values {
+				moved[key] = value
+			}
+		}
+		for key := range embedded {
+			if !parentKeys[strings.ToLower(key)] {
+				delete(moved, key)
+			}
+		}
+		moved[field.Name] = embedded
+	}
+	if moved == nil {
+		return data, nil
+	}
+	return moved, nil
+}
+
+// decodedKeys returns the lowercase keys mapstructure decodes the fields of
+// the struct type t from, including the fields of squashed structs and, with
+// embedded set, of untagged embedded ones.
+func decodedKeys(t reflect.Type, embedded bool) map[string]bool {
+	keys := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+		if isSquashed(field) || embedded && isFlattenedField(field) {
+			for key := range decodedKeys(field.Type, embedded) {
+				keys[key] = true
+			}
+			continue
+		}
+		keys[childBindKey(field, "")] = true
+	}
+	return keys
+}
+
+// stringToBytesHook converts strings to []byte before the default hooks
+// would split them into a list at commas.
+func stringToBytesHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() == reflect.String && to == bytesType {
+		return []byte(reflect.ValueOf(data).String()), nil
+	}
+	return data, nil
+}
+
+// stringToURLHook parses strings into url.URL fields.
+func stringToURLHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != urlType {
+		return data, nil
+	}
+	u, err := url.Parse(reflect.ValueOf(data).String())
+	if err != nil {
+		return nil, err
+	}
+	return *u, nil
+}
+
 func callValidateIfExists(in interface{}) error {
 
 	// Ищем метод Validate
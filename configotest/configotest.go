@@ -0,0 +1,138 @@
+// Package configotest helps downstream tests lock the templates of their
+// config structs against golden files.
+package configotest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vsysa/configo"
+)
+
+// Option configures a single call to AssertTemplateGolden.
+type Option func(*golden)
+
+type golden struct {
+	format       configo.Format
+	withComments bool
+	templateOpts []configo.TemplateOption
+}
+
+// WithFormat selects the template compared to the golden file:
+// configo.YAML (the default), configo.JSON or configo.TOML.
+func WithFormat(format configo.Format) Option {
+	return func(g *golden) {
+		g.format = format
+	}
+}
+
+// WithComments sets whether help texts are written into the template. They
+// are by default.
+func WithComments(enabled bool) Option {
+	return func(g *golden) {
+		g.withComments = enabled
+	}
+}
+
+// WithTemplateOptions passes template options, e.g. configo.WithEnvNames(),
+// to the YAML and TOML generators. The JSON generator takes none.
+func WithTemplateOptions(opts ...configo.TemplateOption) Option {
+	return func(g *golden) {
+		g.templateOpts = append(g.templateOpts, opts...)
+	}
+}
+
+// AssertTemplateGolden generates the template of cfg and compares it to the
+// golden file at goldenPath, failing t with the first differing line when
+// they don't match. With update set the golden file (and its directory) is
+// written instead, so a test typically passes a flag of its own:
+//
+//	var update = flag.Bool("update", false, "rewrite golden files")
+//
+//	func TestConfigTemplate(t *testing.T) {
+//		configotest.AssertTemplateGolden(t, Config{}, "testdata/config.yaml.golden", *update)
+//	}
+func AssertTemplateGolden(t testing.TB, cfg interface{}, goldenPath string, update bool, opts ...Option) {
+	t.Helper()
+
+	g := &golden{format: configo.YAML, withComments: true}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	got, err := g.template(cfg)
+	if err != nil {
+		t.Fatalf("configotest: %v", err)
+		return
+	}
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("configotest: %v", err)
+			return
+		}
+		if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+			t.Fatalf("configotest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("configotest: golden file %s does not exist, run the test with -update to create it", goldenPath)
+		return
+	}
+	if err != nil {
+		t.Fatalf("configotest: %v", err)
+		return
+	}
+
+	if diff := firstDiff(string(want), got); diff != "" {
+		t.Errorf("configotest: template doesn't match %s (run the test with -update to rewrite it)\n%s\n\ngot:\n%s",
+			goldenPath, diff, got)
+	}
+}
+
+// template generates the template of cfg in the selected format.
+func (g *golden) template(cfg interface{}) (string, error) {
+	switch g.format {
+	case configo.YAML:
+		return configo.GenerateYAMLTemplateE(cfg, g.withComments, g.templateOpts...)
+	case configo.JSON:
+		return configo.GenerateJSONTemplate(cfg, g.withComments), nil
+	case configo.TOML:
+		return configo.GenerateTOMLTemplate(cfg, g.withComments, g.templateOpts...), nil
+	default:
+		return "", fmt.Errorf("unknown format %d", g.format)
+	}
+}
+
+// firstDiff describes the first line where want and got differ, or returns
+// "" if they are equal.
+func firstDiff(want, got string) string {
+	if want == got {
+		return ""
+	}
+
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		wantLine, gotLine := lineAt(wantLines, i), lineAt(gotLines, i)
+		if wantLine != gotLine {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, wantLine, gotLine)
+		}
+	}
+	return ""
+}
+
+// lineAt returns the i-th line, or a marker past the end of the file.
+func lineAt(lines []string, i int) string {
+	if i >= len(lines) {
+		return "<end of file>"
+	}
+	return fmt.Sprintf("%q", lines[i])
+}
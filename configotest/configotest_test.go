@@ -0,0 +1,102 @@
+package configotest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vsysa/configo"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+type goldenServer struct {
+	Host string `mapstructure:"host" default:"localhost" help:"Server host"`
+	Port int    `mapstructure:"port" default:"8080" help:"Server port"`
+}
+
+type goldenConfig struct {
+	Name   string       `mapstructure:"name" default:"app" env:"APP_NAME,fixed" help:"Application name"`
+	Server goldenServer `mapstructure:"server"`
+	Tags   []string     `mapstructure:"tags" default:"a,b"`
+}
+
+// recorder captures the failures reported to it instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+	fatal    bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+	r.fatal = true
+}
+
+func TestAssertTemplateGolden(t *testing.T) {
+	AssertTemplateGolden(t, goldenConfig{}, "testdata/config.yaml.golden", *update)
+	AssertTemplateGolden(t, goldenConfig{}, "testdata/config_plain.yaml.golden", *update, WithComments(false))
+	AssertTemplateGolden(t, goldenConfig{}, "testdata/config_env.yaml.golden", *update,
+		WithTemplateOptions(configo.WithEnvNames()))
+	AssertTemplateGolden(t, goldenConfig{}, "testdata/config.json.golden", *update, WithFormat(configo.JSON))
+	AssertTemplateGolden(t, goldenConfig{}, "testdata/config.toml.golden", *update, WithFormat(configo.TOML))
+}
+
+func TestAssertTemplateGolden_Update(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.yaml.golden")
+
+	r := &recorder{TB: t}
+	AssertTemplateGolden(r, goldenConfig{}, path, true)
+	require.Empty(t, r.failures)
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, configo.GenerateYAMLTemplate(goldenConfig{}, true), string(written))
+
+	AssertTemplateGolden(r, goldenConfig{}, path, false)
+	assert.Empty(t, r.failures)
+}
+
+func TestAssertTemplateGolden_Mismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml.golden")
+	require.NoError(t, os.WriteFile(path, []byte("name: \"app\"\nserver:\n  host: \"example.com\"\n"), 0o644))
+
+	r := &recorder{TB: t}
+	AssertTemplateGolden(r, goldenConfig{}, path, false, WithComments(false))
+	require.Len(t, r.failures, 1)
+	assert.False(t, r.fatal)
+	assert.Contains(t, r.failures[0], "line 3:\n- \"  host: \\\"example.com\\\"\"\n+ \"  host: \\\"localhost\\\"\"")
+}
+
+func TestAssertTemplateGolden_Errors(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertTemplateGolden(r, goldenConfig{}, filepath.Join(t.TempDir(), "missing.golden"), false)
+	require.Len(t, r.failures, 1)
+	assert.True(t, r.fatal)
+	assert.Contains(t, r.failures[0], "does not exist, run the test with -update to create it")
+
+	r = &recorder{TB: t}
+	AssertTemplateGolden(r, 42, filepath.Join(t.TempDir(), "int.golden"), true)
+	assert.True(t, r.fatal)
+
+	r = &recorder{TB: t}
+	AssertTemplateGolden(r, goldenConfig{}, filepath.Join(t.TempDir(), "x.golden"), true, WithFormat(configo.Format(99)))
+	require.Len(t, r.failures, 1)
+	assert.Contains(t, r.failures[0], "unknown format 99")
+}
+
+func TestFirstDiff(t *testing.T) {
+	assert.Equal(t, "", firstDiff("a\nb\n", "a\nb\n"))
+	assert.Equal(t, "line 2:\n- \"b\"\n+ \"c\"", firstDiff("a\nb\n", "a\nc\n"))
+	assert.Equal(t, "line 2:\n- \"\"\n+ <end of file>", firstDiff("a\n", "a"))
+}
@@ -0,0 +1,68 @@
+package configo
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/viper"
+	"github.com/vsysa/configo/internal/parser/defaultValues"
+)
+
+// DefaultsOnly resets cfg, which must be a non-nil pointer to a struct, and
+// fills it from its `default` tags alone, without reading a file, the
+// environment or flags. Defaults are parsed the way Load parses them, so
+// durations, slices, times and nested structs work the same, and cfg ends up
+// as Load would leave it if no source set a value: fields without a default
+// keep their zero value, optional sections stay nil and sections tagged with
+// `default:"enabled"` are allocated. Neither validation nor required fields
+// are checked.
+func DefaultsOnly(cfg interface{}) error {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected a non-nil pointer to a struct, got %T", ConfigParsingError, cfg)
+	}
+	rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+
+	v := viper.New()
+	if err := setDefaults(v, cfg); err != nil {
+		return err
+	}
+	if err := parseEncodedFields(v, rv.Elem().Type(), ""); err != nil {
+		return err
+	}
+	if err := v.Unmarshal(cfg, decoderConfig); err != nil {
+		return fmt.Errorf("%w: unable to decode into struct: %w", ConfigParsingError, err)
+	}
+	allocateEnabledSections(rv)
+	return nil
+}
+
+// ParseDefault parses tag, the value of a `default` tag such as "1,2,3", into
+// a value of type t the way Load parses defaults: `${VAR}` references are
+// expanded, slices are split by commas unless they are a JSON array, maps are
+// JSON objects, durations are written like "30s", times in RFC3339, and
+// URLs, IP addresses, networks and registered types (see RegisterType) are
+// parsed from their text form. Pointer types get a pointer to the parsed
+// value, and an empty tag gives the zero value of t.
+//
+// Other tags of the field, like `timeformat`, `encoding` or `format`, don't
+// apply. Values that can't be parsed as t, and structs, whose fields have
+// defaults of their own, give an InvalidValueError.
+func ParseDefault(tag string, t reflect.Type) (reflect.Value, error) {
+	if t == nil {
+		return reflect.Value{}, fmt.Errorf("%w: nil type", InvalidValueError)
+	}
+	result := reflect.New(t).Elem()
+	if tag == "" {
+		return result, nil
+	}
+
+	value, err := defaultValues.ParseValue(t, tag)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: %w", InvalidValueError, err)
+	}
+	if err := decodeValue(result, value); err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: cannot parse default value %q as %s: %w", InvalidValueError, tag, t, err)
+	}
+	return result, nil
+}
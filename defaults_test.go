@@ -0,0 +1,152 @@
+package configo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type DefaultsOnlyConfig struct {
+	Host    string            `mapstructure:"host" default:"localhost" env:"DEFAULTS_ONLY_HOST"`
+	Port    int               `mapstructure:"port" default:"8080" required:"true"`
+	Debug   bool              `mapstructure:"debug" default:"true"`
+	Timeout time.Duration     `mapstructure:"timeout" default:"30s"`
+	Tags    []string          `mapstructure:"tags" default:"a,b"`
+	Started time.Time         `mapstructure:"started" default:"2024-01-02" timeformat:"2006-01-02"`
+	Retries *int              `mapstructure:"retries" default:"3"`
+	Name    string            `mapstructure:"name"`
+	Labels  map[string]string `mapstructure:"labels"`
+	Meta    struct {
+		Version string `mapstructure:"version" default:"1.0"`
+	} `mapstructure:"meta"`
+	Sections LoaderSectionsConfig `mapstructure:"sections"`
+}
+
+func TestDefaultsOnly(t *testing.T) {
+	// Переменные окружения не учитываются
+	setEnv(t, "DEFAULTS_ONLY_HOST", "example.com")
+	defer unsetEnv(t, "DEFAULTS_ONLY_HOST")
+
+	cfg := DefaultsOnlyConfig{Name: "stale", Labels: map[string]string{"a": "b"}}
+	if err := DefaultsOnly(&cfg); err != nil {
+		t.Fatalf("DefaultsOnly failed: %v", err)
+	}
+
+	if cfg.Host != "localhost" || cfg.Port != 8080 || !cfg.Debug || cfg.Timeout != 30*time.Second {
+		t.Errorf("unexpected scalars: %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Errorf("expected tags [a b], got %v", cfg.Tags)
+	}
+	if !cfg.Started.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected started 2024-01-02, got %v", cfg.Started)
+	}
+	if cfg.Retries == nil || *cfg.Retries != 3 {
+		t.Errorf("expected retries to be allocated with 3, got %v", cfg.Retries)
+	}
+	if cfg.Name != "" || cfg.Labels != nil {
+		t.Errorf("expected fields without defaults to be reset, got %q and %v", cfg.Name, cfg.Labels)
+	}
+	if cfg.Meta.Version != "1.0" {
+		t.Errorf("expected meta.version 1.0, got %q", cfg.Meta.Version)
+	}
+	if cfg.Sections.TLS != nil {
+		t.Errorf("expected optional section to stay nil, got %+v", cfg.Sections.TLS)
+	}
+	if cfg.Sections.Backup == nil || *cfg.Sections.Backup != (LoaderTLSConfig{Cert: "cert.pem", Port: 443}) {
+		t.Errorf("expected enabled section with its defaults, got %+v", cfg.Sections.Backup)
+	}
+}
+
+func TestDefaultsOnly_Errors(t *testing.T) {
+	if err := DefaultsOnly(DefaultsOnlyConfig{}); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("expected ConfigParsingError for a non-pointer, got %v", err)
+	}
+
+	type BadDefault struct {
+		Port int `mapstructure:"port" default:"http"`
+	}
+	if err := DefaultsOnly(&BadDefault{}); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("expected ConfigParsingError for an invalid default, got %v", err)
+	}
+}
+
+func TestParseDefault(t *testing.T) {
+	retries := 3
+	tests := []struct {
+		tag  string
+		want interface{}
+	}{
+		{"8080", 8080},
+		{"255", uint8(255)},
+		{"0.5", 0.5},
+		{"true", true},
+		{"localhost", "localhost"},
+		{"1,2,3", []int{1, 2, 3}},
+		{`["a,b","c"]`, []string{"a,b", "c"}},
+		{`{"env":"prod"}`, map[string]string{"env": "prod"}},
+		{"30s", 30 * time.Second},
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"secret", []byte("secret")},
+		{"3", &retries},
+		{"", 0},
+		{"", []string(nil)},
+	}
+	for _, tt := range tests {
+		typ := reflect.TypeOf(tt.want)
+		got, err := ParseDefault(tt.tag, typ)
+		if err != nil {
+			t.Errorf("ParseDefault(%q, %s): %v", tt.tag, typ, err)
+			continue
+		}
+		if got.Type() != typ || !reflect.DeepEqual(got.Interface(), tt.want) {
+			t.Errorf("ParseDefault(%q, %s) = %#v, want %#v", tt.tag, typ, got.Interface(), tt.want)
+		}
+	}
+
+	// Значение можно присвоить полю нужного типа
+	var cfg DefaultsOnlyConfig
+	value, err := ParseDefault("a, b", reflect.TypeOf(cfg.Tags))
+	if err != nil {
+		t.Fatalf("ParseDefault failed: %v", err)
+	}
+	reflect.ValueOf(&cfg).Elem().FieldByName("Tags").Set(value)
+	if !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Errorf("expected tags [a b], got %v", cfg.Tags)
+	}
+}
+
+func TestParseDefault_RegisteredType(t *testing.T) {
+	got, err := ParseDefault("$4.99", reflect.TypeOf(Money{}))
+	if err != nil {
+		t.Fatalf("ParseDefault failed: %v", err)
+	}
+	if got.Interface() != (Money{Cents: 499}) {
+		t.Errorf("expected 499 cents, got %v", got.Interface())
+	}
+	if _, err := ParseDefault("free", reflect.TypeOf(Money{})); !errors.Is(err, InvalidValueError) {
+		t.Errorf("expected InvalidValueError, got %v", err)
+	}
+}
+
+func TestParseDefault_Errors(t *testing.T) {
+	tests := []struct {
+		tag string
+		t   reflect.Type
+	}{
+		{"http", reflect.TypeOf(0)},
+		{"256", reflect.TypeOf(uint8(0))},
+		{"soon", reflect.TypeOf(time.Duration(0))},
+		{"2024-01-02", reflect.TypeOf(time.Time{})},
+		{"1,x", reflect.TypeOf([]int{})},
+		{"{", reflect.TypeOf(map[string]int{})},
+		{"x", reflect.TypeOf(DefaultsOnlyConfig{})},
+		{"x", nil},
+	}
+	for _, tt := range tests {
+		if _, err := ParseDefault(tt.tag, tt.t); !errors.Is(err, InvalidValueError) {
+			t.Errorf("ParseDefault(%q, %v): expected InvalidValueError, got %v", tt.tag, tt.t, err)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package configo
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// FieldDiff describes a config field whose value in a YAML file differs from
+// its default, or which is omitted from the file.
+type FieldDiff struct {
+	// Path is the dotted bind key of the field, e.g. "meta.version".
+	// Slice elements get their index in the path, e.g. "servers.0.port".
+	Path string
+	// Default is the value the field gets from its `default` tag, or nil.
+	Default interface{}
+	// Value is the value set in the file. It is nil when UsingDefault is set.
+	Value interface{}
+	// UsingDefault is set when the file doesn't set the field.
+	UsingDefault bool
+}
+
+// Diff compares a YAML file against the defaults of the config struct cfg.
+// It returns an entry for every field the file sets to a value other than
+// its default and for every field the file omits. Fields set to their
+// default value are not reported.
+//
+// Nested structs are compared field by field, except structs configured as a
+// single string like time.Time or url.URL, which are compared as a whole.
+// Slices set in the file are
+// compared element-wise: primitive elements against the elements of the
+// default slice, struct elements against the defaults of the element type.
+func Diff(cfg interface{}, yamlBytes []byte) ([]FieldDiff, error) {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected a struct, got %T", ConfigParsingError, cfg)
+	}
+
+	var raw map[string]interface{}
+	if err := yamlv3.Unmarshal(yamlBytes, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+
+	defaults, err := decodeDefaults(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file is decoded over the defaults, so that values are compared
+	// after the same type conversions the loader applies.
+	v := viper.New()
+	if err := setDefaults(v, reflect.New(t).Interface()); err != nil {
+		return nil, err
+	}
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(yamlBytes)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	values := reflect.New(t)
+	if err := v.Unmarshal(values.Interface(), decoderConfig); err != nil {
+		return nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+
+	var diffs []FieldDiff
+	if err := diffStruct(defaults, values.Elem(), raw, "", &diffs); err != nil {
+		return nil, err
+	}
+	return diffs, nil
+}
+
+// decodeDefaults returns a value of type t populated from its `default` tags.
+func decodeDefaults(t reflect.Type) (reflect.Value, error) {
+	v := viper.New()
+	ptr := reflect.New(t)
+	if err := setDefaults(v, ptr.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	if err := v.Unmarshal(ptr.Interface(), decoderConfig); err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	return ptr.Elem(), nil
+}
+
+// diffStruct compares the fields of two struct values of the same type.
+// raw is the corresponding mapping of the file and tells which keys are set.
+func diffStruct(defaults, values reflect.Value, raw map[string]interface{}, parentPath string, diffs *[]FieldDiff) error {
+	t := defaults.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported and ignored fields
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		defaultValue := derefValue(defaults.Field(i))
+		fileValue := derefValue(values.Field(i))
+
+		// Embedded and squashed structs share the parent mapping.
+		if isFlattenedField(field) {
+			if err := diffStruct(defaultValue, fileValue, raw, parentPath, diffs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := childBindKey(field, "")
+		path := childBindKey(field, parentPath)
+		rawValue, present := lookupKey(raw, key)
+
+		if err := diffValue(defaultValue, fileValue, rawValue, present, path, diffs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffValue compares a single value and appends the differences found.
+func diffValue(defaultValue, fileValue reflect.Value, rawValue interface{}, present bool, path string, diffs *[]FieldDiff) error {
+	switch {
+	case defaultValue.Kind() == reflect.Struct && !isTextStruct(defaultValue.Type()):
+		rawMap, _ := rawValue.(map[string]interface{})
+		return diffStruct(defaultValue, fileValue, rawMap, path, diffs)
+
+	case !present:
+		*diffs = append(*diffs, FieldDiff{Path: path, Default: interfaceOf(defaultValue), UsingDefault: true})
+		return nil
+
+	case defaultValue.Kind() == reflect.Slice:
+		return diffSlice(defaultValue, fileValue, rawValue, path, diffs)
+
+	default:
+		if !reflect.DeepEqual(interfaceOf(defaultValue), interfaceOf(fileValue)) {
+			*diffs = append(*diffs, FieldDiff{Path: path, Default: interfaceOf(defaultValue), Value: interfaceOf(fileValue)})
+		}
+		return nil
+	}
+}
+
+// diffSlice compares a slice set in the file element by element.
+func diffSlice(defaultValue, fileValue reflect.Value, rawValue interface{}, path string, diffs *[]FieldDiff) error {
+	rawItems, _ := rawValue.([]interface{})
+	elemType := defaultValue.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	// Struct elements have no default slice; each of them is compared
+	// against the defaults of the element type instead.
+	if elemType.Kind() == reflect.Struct {
+		elemDefaults, err := decodeDefaults(elemType)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < fileValue.Len(); i++ {
+			var rawItem interface{}
+			if i < len(rawItems) {
+				rawItem = rawItems[i]
+			}
+			elemPath := path + "." + strconv.Itoa(i)
+			if err := diffValue(elemDefaults, derefValue(fileValue.Index(i)), rawItem, true, elemPath, diffs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	n := max(defaultValue.Len(), fileValue.Len())
+	for i := 0; i < n; i++ {
+		var def, val interface{}
+		if i < defaultValue.Len() {
+			def = interfaceOf(defaultValue.Index(i))
+		}
+		if i < fileValue.Len() {
+			val = interfaceOf(fileValue.Index(i))
+		}
+		if !reflect.DeepEqual(def, val) {
+			*diffs = append(*diffs, FieldDiff{Path: path + "." + strconv.Itoa(i), Default: def, Value: val})
+		}
+	}
+	return nil
+}
+
+// lookupKey finds a key in a YAML mapping. Like Viper, it falls back to
+// a case-insensitive match.
+func lookupKey(raw map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := raw[key]; ok {
+		return value, true
+	}
+	for k, value := range raw {
+		if strings.EqualFold(k, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// derefValue dereferences pointers. A nil pointer yields the zero value of
+// the element type, so that nested structs can still be walked.
+func derefValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Zero(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// interfaceOf returns the value as an interface, with nil for empty slices
+// and maps, so that unset collections compare equal.
+func interfaceOf(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+	}
+	return v.Interface()
+}
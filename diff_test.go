@@ -0,0 +1,105 @@
+package configo
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type DiffServerConfig struct {
+	Host string `mapstructure:"host" default:"localhost"`
+	Port int    `mapstructure:"port" default:"8080"`
+}
+
+type DiffTestConfig struct {
+	Name    string             `mapstructure:"name" default:"app"`
+	Tags    []string           `mapstructure:"tags" default:"a,b"`
+	Meta    LoaderMetaConfig   `mapstructure:"meta"`
+	Servers []DiffServerConfig `mapstructure:"servers"`
+}
+
+func TestDiff(t *testing.T) {
+	yamlContent := `
+name: app
+tags: [a, c, d]
+meta:
+  version: "2.0"
+servers:
+  - host: example.com
+  - port: 8080
+`
+	diffs, err := Diff(DiffTestConfig{}, []byte(yamlContent))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	expected := []FieldDiff{
+		{Path: "tags.1", Default: "b", Value: "c"},
+		{Path: "tags.2", Default: nil, Value: "d"},
+		{Path: "meta.version", Default: "1.0", Value: "2.0"},
+		{Path: "meta.build", Default: "dev", UsingDefault: true},
+		{Path: "servers.0.host", Default: "localhost", Value: "example.com"},
+		{Path: "servers.0.port", Default: 8080, UsingDefault: true},
+		{Path: "servers.1.host", Default: "localhost", UsingDefault: true},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Unexpected diff:\n got: %+v\nwant: %+v", diffs, expected)
+	}
+}
+
+func TestDiff_OmittedKeys(t *testing.T) {
+	diffs, err := Diff(&LoaderTestConfig{}, []byte("port: 9090\n"))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	expected := []FieldDiff{
+		{Path: "host", Default: "localhost", UsingDefault: true},
+		{Path: "port", Default: 8080, Value: 9090},
+		{Path: "meta.version", Default: "1.0", UsingDefault: true},
+		{Path: "meta.build", Default: "dev", UsingDefault: true},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Unexpected diff:\n got: %+v\nwant: %+v", diffs, expected)
+	}
+}
+
+// time.Time и url.URL сравниваются целиком
+func TestDiff_TextStructs(t *testing.T) {
+	type Config struct {
+		Start    time.Time `mapstructure:"start"`
+		Endpoint url.URL   `mapstructure:"endpoint" default:"https://example.com"`
+	}
+
+	diffs, err := Diff(Config{}, []byte("start: 2024-01-02T03:04:05Z\n"))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	expected := []FieldDiff{
+		{Path: "start", Default: time.Time{}, Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Path: "endpoint", Default: url.URL{Scheme: "https", Host: "example.com"}, UsingDefault: true},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Unexpected diff:\n got: %+v\nwant: %+v", diffs, expected)
+	}
+
+	diffs, err = Diff(Config{}, []byte("endpoint: https://example.com\n"))
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	expected = []FieldDiff{{Path: "start", Default: time.Time{}, UsingDefault: true}}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Unexpected diff:\n got: %+v\nwant: %+v", diffs, expected)
+	}
+}
+
+func TestDiff_Errors(t *testing.T) {
+	if _, err := Diff(42, nil); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for non-struct config, got %v", err)
+	}
+	if _, err := Diff(LoaderTestConfig{}, []byte("host: [")); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for invalid YAML, got %v", err)
+	}
+}
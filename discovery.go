@@ -0,0 +1,110 @@
+package configo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// WithName makes Load search for the config file instead of reading the one
+// set with WithFile: the first existing file named name with an extension of
+// one of the formats (see WithFormats) in one of the search paths (see
+// WithSearchPaths) is read. E.g. WithName("config") with the search paths
+// "/etc/app" and "." tries /etc/app/config.yaml, /etc/app/config.yml, ...
+// and then ./config.yaml. If none exists, Load fails with
+// ConfigFileNotFoundError, unless WithOptionalFile is set.
+func WithName(name string) LoaderOption {
+	return func(l *loader) {
+		l.name = name
+	}
+}
+
+// WithSearchPaths sets the directories searched for the config file named
+// with WithName, in order. Defaults to the working directory.
+func WithSearchPaths(paths ...string) LoaderOption {
+	return func(l *loader) {
+		l.searchPaths = append(l.searchPaths, paths...)
+	}
+}
+
+// WithFormats sets the formats of the config file named with WithName, in
+// order of preference. Defaults to YAML, JSON and TOML. YAML files may end in
+// .yaml or .yml.
+func WithFormats(formats ...Format) LoaderOption {
+	return func(l *loader) {
+		l.formats = append(l.formats, formats...)
+	}
+}
+
+// WithOptionalFile lets Load go on with the defaults files, the environment
+// and flags when the config file, set with WithFile or searched for with
+// WithName, doesn't exist.
+func WithOptionalFile() LoaderOption {
+	return func(l *loader) {
+		l.optionalFile = true
+	}
+}
+
+// extensions returns the file extensions of the format.
+func (f Format) extensions() []string {
+	if f == YAML {
+		return []string{".yaml", ".yml"}
+	}
+	return []string{"." + f.configType()}
+}
+
+// readFile reads the config file of the loader into v: the one found with
+// WithName, or the one set with WithFile.
+func (l *loader) readFile(v *viper.Viper) error {
+	path := l.configFilePath
+	if l.name != "" {
+		var err error
+		if path, err = l.findFile(); err != nil {
+			if l.optionalFile && errors.Is(err, ConfigFileNotFoundError) {
+				return nil
+			}
+			return err
+		}
+	} else if l.optionalFile {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+	}
+	return readConfigFile(v, path)
+}
+
+// findFile returns the path of the first existing config file named with
+// WithName.
+func (l *loader) findFile() (string, error) {
+	paths := l.searchPaths
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	formats := l.formats
+	if len(formats) == 0 {
+		formats = []Format{YAML, JSON, TOML}
+	}
+
+	var tried []string
+	for _, dir := range paths {
+		for _, format := range formats {
+			if format.configType() == "" {
+				return "", fmt.Errorf("%w: unknown config format %d", ConfigParsingError, format)
+			}
+			for _, ext := range format.extensions() {
+				path := filepath.Join(dir, l.name+ext)
+				info, err := os.Stat(path)
+				if err == nil && !info.IsDir() {
+					return path, nil
+				}
+				tried = append(tried, path)
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: tried %s", ConfigFileNotFoundError, strings.Join(tried, ", "))
+}
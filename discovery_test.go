@@ -0,0 +1,117 @@
+package configo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type DiscoveryTestConfig struct {
+	Host string `mapstructure:"host" default:"localhost"`
+	Port int    `mapstructure:"port" default:"8080"`
+}
+
+func writeDiscoveryFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestLoad_WithName(t *testing.T) {
+	etc, local := t.TempDir(), t.TempDir()
+	localPath := writeDiscoveryFile(t, local, "config.yaml", "host: local\n")
+	writeDiscoveryFile(t, local, "config.json", `{"host": "json"}`)
+
+	var cfg DiscoveryTestConfig
+	result, err := LoadWithResult(&cfg, WithName("config"), WithSearchPaths(etc, local))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "local" || result.File != localPath {
+		t.Errorf("expected %s to be read, got host %q from %q", localPath, cfg.Host, result.File)
+	}
+
+	// Первый путь поиска важнее следующих
+	etcPath := writeDiscoveryFile(t, etc, "config.yml", "host: etc\n")
+	result, err = LoadWithResult(&cfg, WithName("config"), WithSearchPaths(etc, local))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "etc" || result.File != etcPath {
+		t.Errorf("expected %s to be read, got host %q from %q", etcPath, cfg.Host, result.File)
+	}
+	if source, origin := result.Source("host"); source != SourceFile || origin != etcPath {
+		t.Errorf("expected host from %s, got %v (%s)", etcPath, source, origin)
+	}
+}
+
+func TestLoad_WithFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeDiscoveryFile(t, dir, "app.yaml", "host: yaml\n")
+	jsonPath := writeDiscoveryFile(t, dir, "app.json", `{"host": "json", "port": 9090}`)
+	writeDiscoveryFile(t, dir, "app.toml", "host = \"toml\"\n")
+
+	var cfg DiscoveryTestConfig
+	result, err := LoadWithResult(&cfg, WithName("app"), WithSearchPaths(dir), WithFormats(JSON, YAML))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "json" || cfg.Port != 9090 || result.File != jsonPath {
+		t.Errorf("expected %s to be read, got %+v from %q", jsonPath, cfg, result.File)
+	}
+
+	if err := Load(&cfg, WithName("app"), WithSearchPaths(dir), WithFormats(Format(42))); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("expected ConfigParsingError for an unknown format, got %v", err)
+	}
+}
+
+func TestLoad_WithNameNotFound(t *testing.T) {
+	dir := t.TempDir()
+	// Каталог с подходящим именем не считается файлом
+	if err := os.Mkdir(filepath.Join(dir, "config.yaml"), 0o755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	var cfg DiscoveryTestConfig
+	err := Load(&cfg, WithName("config"), WithSearchPaths(dir), WithFormats(YAML))
+	if !errors.Is(err, ConfigFileNotFoundError) {
+		t.Fatalf("expected ConfigFileNotFoundError, got %v", err)
+	}
+	for _, name := range []string{"config.yaml", "config.yml"} {
+		if !strings.Contains(err.Error(), filepath.Join(dir, name)) {
+			t.Errorf("expected the error to list %s, got %v", name, err)
+		}
+	}
+
+	result, err := LoadWithResult(&cfg, WithName("config"), WithSearchPaths(dir), WithOptionalFile())
+	if err != nil {
+		t.Fatalf("expected defaults with WithOptionalFile, got %v", err)
+	}
+	if cfg != (DiscoveryTestConfig{Host: "localhost", Port: 8080}) || result.File != "" {
+		t.Errorf("expected defaults and no file, got %+v from %q", cfg, result.File)
+	}
+}
+
+func TestLoad_WithOptionalFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.yml")
+
+	var cfg DiscoveryTestConfig
+	if err := Load(&cfg, WithFile(missing)); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+
+	setEnv(t, "PORT", "7070")
+	defer unsetEnv(t, "PORT")
+	result, err := LoadWithResult(&cfg, WithFile(missing), WithOptionalFile())
+	if err != nil {
+		t.Fatalf("expected defaults and env with WithOptionalFile, got %v", err)
+	}
+	if cfg.Host != "localhost" || cfg.Port != 7070 || result.File != "" {
+		t.Errorf("expected defaults and env, got %+v from %q", cfg, result.File)
+	}
+}
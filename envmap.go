@@ -0,0 +1,152 @@
+package configo
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/env"
+)
+
+// ToEnvMap returns the current values of the config struct cfg (or a pointer
+// to one) as environment variables, e.g. "META_VERSION" → "1.0", to launch a
+// subprocess or write a .env file. The names are derived like the loader
+// derives them: pass the env options given to Load, e.g. WithEnvPrefix, to
+// get the same prefix, separator and case. Explicit `env` tags are honored.
+//
+// Values are written in the form of FlattenKeys, and slices of scalars as a
+// comma-separated list (TAGS=a,b), which the loader reads back. Slices of
+// structs, maps and lists nested in lists have no form the loader reads from
+// a single variable, so they are flattened into indexed and keyed names:
+// SERVERS_0_HOST for servers.0.host and LABELS_ENV for labels.env, joined
+// and cased like the other names. The fields of such elements always get
+// derived names, even with an `env` tag.
+//
+// Nil pointers and empty slices and maps produce no variables. Secret fields
+// are not masked. A cfg that is not a struct gives an empty map.
+func ToEnvMap(cfg interface{}, opts ...LoaderOption) map[string]string {
+	vars := make(map[string]string)
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return vars
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return vars
+	}
+
+	naming := envNamingOf(opts)
+	envStruct(vars, v, naming)
+	return vars
+}
+
+// envStruct adds the variables of the fields of a struct value, named with
+// naming.
+func envStruct(vars map[string]string, v reflect.Value, naming env.Naming) {
+	for _, info := range env.GetEnvsWithNaming(v.Interface(), naming) {
+		value, tag, ok := lookupPath(v, info.BindKey)
+		if ok {
+			envValue(vars, info.EnvVar, value, tag, naming)
+		}
+	}
+}
+
+// envValue adds a single value as the variable name. Slices of structs and
+// maps add one variable per element. tag is the tag of the field the value
+// belongs to.
+func envValue(vars map[string]string, name string, v reflect.Value, tag reflect.StructTag, naming env.Naming) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	if text, ok := flatString(v, tag); ok {
+		vars[name] = text
+		return
+	}
+
+	element := naming
+	element.InElement = true
+	join := func(key string) string {
+		if !naming.KeepCase {
+			key = strings.ToUpper(key)
+		}
+		return name + envSeparator(naming) + key
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		element.Prefix = name
+		envStruct(vars, v, element)
+	case reflect.Slice, reflect.Array:
+		if items, ok := envList(v, tag); ok {
+			if len(items) > 0 {
+				vars[name] = strings.Join(items, ",")
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			envValue(vars, join(strconv.Itoa(i)), v.Index(i), tag, element)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			envValue(vars, join(fmt.Sprint(iter.Key().Interface())), iter.Value(), tag, element)
+		}
+	}
+}
+
+// envList renders the items of a slice of scalars. It returns false if an
+// item is a struct, slice or map.
+func envList(v reflect.Value, tag reflect.StructTag) ([]string, bool) {
+	items := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		for (item.Kind() == reflect.Ptr || item.Kind() == reflect.Interface) && !item.IsNil() {
+			item = item.Elem()
+		}
+		if item.Kind() == reflect.Ptr || item.Kind() == reflect.Interface {
+			items = append(items, "")
+			continue
+		}
+		text, ok := flatString(item, tag)
+		if !ok {
+			return nil, false
+		}
+		items = append(items, text)
+	}
+	return items, true
+}
+
+// envSeparator returns the separator of nested names, "_" by default.
+func envSeparator(naming env.Naming) string {
+	if naming.Separator == "" {
+		return "_"
+	}
+	return naming.Separator
+}
+
+// lookupPath returns the field of the struct value v at the dotted bind key
+// path, along with its tag. It returns false when a nil pointer is on the
+// path.
+func lookupPath(v reflect.Value, path string) (reflect.Value, reflect.StructTag, bool) {
+	var tag reflect.StructTag
+	for _, key := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, "", false
+			}
+			v = v.Elem()
+		}
+		var ok bool
+		if v, tag, ok = lookupField(v, key); !ok {
+			return reflect.Value{}, "", false
+		}
+	}
+	return v, tag, true
+}
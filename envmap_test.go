@@ -0,0 +1,116 @@
+package configo
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type EnvMapServer struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port" env:"SERVER_PORT,fixed"`
+}
+
+type EnvMapTestConfig struct {
+	Meta struct {
+		Version string `mapstructure:"version"`
+	} `mapstructure:"meta"`
+	URL      string            `mapstructure:"url" env:"DATABASE_URL,fixed"`
+	Debug    bool              `mapstructure:"debug"`
+	Timeout  time.Duration     `mapstructure:"timeout"`
+	Started  time.Time         `mapstructure:"started" timeformat:"2006-01-02"`
+	Mode     uint32            `mapstructure:"mode" format:"octal"`
+	Endpoint url.URL           `mapstructure:"endpoint"`
+	Addr     net.IP            `mapstructure:"addr"`
+	Tags     []string          `mapstructure:"tags"`
+	Ports    []int             `mapstructure:"ports"`
+	Servers  []EnvMapServer    `mapstructure:"servers"`
+	Labels   map[string]string `mapstructure:"labels"`
+	Backup   *EnvMapServer     `mapstructure:"backup"`
+	Empty    []string          `mapstructure:"empty"`
+	Hidden   string            `mapstructure:"hidden" env:"-"`
+}
+
+func TestToEnvMap(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/api")
+	cfg := EnvMapTestConfig{
+		URL:      "postgres://db",
+		Debug:    true,
+		Timeout:  30 * time.Second,
+		Started:  time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Mode:     0o644,
+		Endpoint: *endpoint,
+		Addr:     net.ParseIP("10.0.0.1"),
+		Tags:     []string{"a", "b"},
+		Ports:    []int{80, 443},
+		Servers:  []EnvMapServer{{Host: "one", Port: 80}, {Host: "two"}},
+		Labels:   map[string]string{"env": "prod"},
+		Hidden:   "x",
+	}
+	cfg.Meta.Version = "1.0"
+
+	expected := map[string]string{
+		"META_VERSION":          "1.0",
+		"DATABASE_URL":          "postgres://db",
+		"DEBUG":                 "true",
+		"TIMEOUT":               "30s",
+		"STARTED":               "2024-01-02",
+		"MODE":                  "0644",
+		"ENDPOINT":              "https://example.com/api",
+		"ADDR":                  "10.0.0.1",
+		"TAGS":                  "a,b",
+		"PORTS":                 "80,443",
+		"SERVERS_0_HOST":        "one",
+		"SERVERS_0_SERVER_PORT": "80",
+		"SERVERS_1_HOST":        "two",
+		"SERVERS_1_SERVER_PORT": "0",
+		"LABELS_ENV":            "prod",
+	}
+	// Указатель и значение дают одинаковый результат
+	for _, in := range []interface{}{cfg, &cfg} {
+		if got := ToEnvMap(in); !reflect.DeepEqual(got, expected) {
+			t.Errorf("ToEnvMap(%T):\n got %v\nwant %v", in, got, expected)
+		}
+	}
+
+	cfg = EnvMapTestConfig{Backup: &EnvMapServer{Host: "spare", Port: 9090}}
+	got := ToEnvMap(cfg, WithEnvPrefix("app"), WithEnvSeparator("__"))
+	for name, value := range map[string]string{"APP__BACKUP__HOST": "spare", "SERVER_PORT": "9090", "APP__DEBUG": "false"} {
+		if got[name] != value {
+			t.Errorf("expected %s=%s, got %q in %v", name, value, got[name], got)
+		}
+	}
+
+	if got := ToEnvMap(nil); len(got) != 0 {
+		t.Errorf("expected no variables for nil, got %v", got)
+	}
+}
+
+// Переменные из ToEnvMap загружаются обратно в ту же конфигурацию.
+func TestToEnvMap_LoadRoundTrip(t *testing.T) {
+	cfg := EnvMapTestConfig{
+		URL:     "postgres://db",
+		Debug:   true,
+		Timeout: time.Minute,
+		Started: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Mode:    0o755,
+		Addr:    net.ParseIP("10.0.0.1"),
+		Tags:    []string{"a", "b"},
+		Ports:   []int{80, 443},
+	}
+	cfg.Meta.Version = "2.0"
+
+	for name, value := range ToEnvMap(cfg, WithEnvPrefix("ROUNDTRIP")) {
+		setEnv(t, name, value)
+		defer unsetEnv(t, name)
+	}
+	var loaded EnvMapTestConfig
+	if err := LoadFromBytes(&loaded, []byte("{}"), JSON, WithEnvPrefix("ROUNDTRIP")); err != nil {
+		t.Fatalf("LoadFromBytes failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, cfg) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", loaded, cfg)
+	}
+}
@@ -0,0 +1,171 @@
+package configo
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/vsysa/configo/internal/parser/defaultValues"
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// durationType is used to detect time.Duration fields, which get duration
+// flags instead of int64 ones.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// RegisterFlags defines a flag on fs for every leaf field of the config struct
+// cfg, named after its dotted bind key (--meta.version). The `default` tag is
+// the default shown in the usage and `help` the usage string. Defaults of
+// secret fields are not shown.
+//
+// Fields of kind string, bool, int, uint, float, time.Duration and []string get
+// a flag of the same kind. Fields of other types are skipped. It is an error to
+// register a flag name fs already has.
+//
+// Flags don't change the config by themselves: call BindFlags after parsing
+// and loading to apply the flags the user set.
+func RegisterFlags(fs *pflag.FlagSet, cfg interface{}) error {
+	defaults, err := defaultValues.GetDefaultValues(cfg)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	defaultByKey := make(map[string]interface{}, len(defaults))
+	for _, d := range defaults {
+		defaultByKey[d.BindKey] = d.DefaultValue
+	}
+
+	return walker.Walk(reflect.TypeOf(cfg), func(f walker.Field) error {
+		if !isFlagKind(f.Type) {
+			return nil
+		}
+		if fs.Lookup(f.BindKey) != nil {
+			return fmt.Errorf("flag %q is already defined", f.BindKey)
+		}
+
+		value := defaultByKey[f.BindKey]
+		if isSecretField(f) {
+			value = nil
+		}
+		defineFlag(fs, f, value)
+		return nil
+	})
+}
+
+// BindFlags applies the flags registered by RegisterFlags that were set on the
+// command line to cfg, which must be a non-nil pointer to a loaded config
+// struct. Flags have the highest precedence: they override the file, the
+// environment and the defaults. Flags left unset don't change cfg.
+func BindFlags(fs *pflag.FlagSet, cfg interface{}) error {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected a non-nil pointer to a struct, got %T", ConfigParsingError, cfg)
+	}
+
+	v := viper.New()
+	if _, err := setFlags(v, rv.Elem().Type(), fs); err != nil {
+		return err
+	}
+
+	// Only the keys of the set flags are decoded, so every other field of
+	// cfg keeps its loaded value.
+	if err := v.Unmarshal(cfg, decoderConfig); err != nil {
+		return fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	return nil
+}
+
+// setFlags sets the values of the flags of the fields of t that were set on
+// the command line in v, returning the names of the flags by bind key.
+func setFlags(v *viper.Viper, t reflect.Type, fs *pflag.FlagSet) (map[string]string, error) {
+	set := make(map[string]string)
+	err := walker.Walk(t, func(f walker.Field) error {
+		if !isFlagKind(f.Type) {
+			return nil
+		}
+		flag := fs.Lookup(f.BindKey)
+		if flag == nil || !flag.Changed {
+			return nil
+		}
+
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			v.Set(f.BindKey, slice.GetSlice())
+		} else {
+			v.Set(f.BindKey, flag.Value.String())
+		}
+		set[f.BindKey] = flag.Name
+		return nil
+	})
+	return set, err
+}
+
+// isFlagKind reports whether a field of type t gets a flag.
+func isFlagKind(t reflect.Type) bool {
+	if t == durationType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// defineFlag defines the flag of a field. value is the parsed default of the
+// field, or nil.
+func defineFlag(fs *pflag.FlagSet, f walker.Field, value interface{}) {
+	name, usage := f.BindKey, f.Help
+
+	if f.Type == durationType {
+		var d time.Duration
+		if s, ok := value.(string); ok {
+			d, _ = time.ParseDuration(s)
+		}
+		fs.Duration(name, d, usage)
+		return
+	}
+
+	switch f.Type.Kind() {
+	case reflect.String:
+		s, _ := value.(string)
+		fs.String(name, s, usage)
+	case reflect.Bool:
+		b, _ := value.(bool)
+		fs.Bool(name, b, usage)
+	case reflect.Int:
+		i, _ := value.(int64)
+		fs.Int(name, int(i), usage)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, _ := value.(int64)
+		fs.Int64(name, i, usage)
+	case reflect.Uint:
+		u, _ := value.(uint64)
+		fs.Uint(name, uint(u), usage)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, _ := value.(uint64)
+		fs.Uint64(name, u, usage)
+	case reflect.Float32, reflect.Float64:
+		fl, _ := value.(float64)
+		fs.Float64(name, fl, usage)
+	case reflect.Slice:
+		items, _ := value.([]string)
+		fs.StringSlice(name, items, usage)
+	}
+}
+
+// isSecretField reports whether the field or one of its parents is tagged
+// with `secret:"true"`.
+func isSecretField(f walker.Field) bool {
+	for p := &f; p != nil; p = p.Parent {
+		if p.Tag.Get("secret") == "true" {
+			return true
+		}
+	}
+	return false
+}
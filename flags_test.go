@@ -0,0 +1,115 @@
+package configo
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type FlagsMetaConfig struct {
+	Version string `mapstructure:"version" default:"1.0" help:"App version"`
+	Debug   bool   `mapstructure:"debug"`
+}
+
+type FlagsTestConfig struct {
+	Host     string            `mapstructure:"host" default:"localhost" help:"The hostname"`
+	Port     int               `mapstructure:"port" default:"8080"`
+	Ratio    float64           `mapstructure:"ratio" default:"0.5"`
+	Timeout  time.Duration     `mapstructure:"timeout" default:"30s"`
+	Tags     []string          `mapstructure:"tags" default:"a,b"`
+	Password string            `mapstructure:"password" default:"hunter2" secret:"true"`
+	Meta     FlagsMetaConfig   `mapstructure:"meta"`
+	Labels   map[string]string `mapstructure:"labels"`
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := RegisterFlags(fs, FlagsTestConfig{}); err != nil {
+		t.Fatalf("Failed to register flags: %v", err)
+	}
+
+	expected := map[string]string{
+		"host":         "localhost",
+		"port":         "8080",
+		"ratio":        "0.5",
+		"timeout":      "30s",
+		"tags":         "[a,b]",
+		"password":     "",
+		"meta.version": "1.0",
+		"meta.debug":   "false",
+	}
+	for name, defValue := range expected {
+		flag := fs.Lookup(name)
+		if flag == nil {
+			t.Errorf("Flag --%s is not registered", name)
+			continue
+		}
+		if flag.DefValue != defValue {
+			t.Errorf("Expected --%s default %q, got %q", name, defValue, flag.DefValue)
+		}
+	}
+	if fs.Lookup("labels") != nil {
+		t.Errorf("Maps should not get a flag")
+	}
+	if usage := fs.Lookup("meta.version").Usage; usage != "App version" {
+		t.Errorf("Expected usage from the help tag, got %q", usage)
+	}
+
+	// Повторная регистрация тех же флагов — ошибка
+	if err := RegisterFlags(fs, FlagsTestConfig{}); err == nil {
+		t.Errorf("Expected an error for already defined flags")
+	}
+}
+
+// Флаги имеют наивысший приоритет: flag > env > file > default
+func TestBindFlags(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "host: filehost\nport: 9090\nmeta:\n  version: \"2.0\"\n")
+	defer os.Remove(configPath)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := RegisterFlags(fs, &FlagsTestConfig{}); err != nil {
+		t.Fatalf("Failed to register flags: %v", err)
+	}
+	args := []string{"--port=7070", "--timeout=1m", "--tags=x,y", "--meta.debug", "--meta.version=3.0"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	var cfg FlagsTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if err := BindFlags(fs, &cfg); err != nil {
+		t.Fatalf("Failed to bind flags: %v", err)
+	}
+
+	if cfg.Host != "filehost" {
+		t.Errorf("Unset flag should keep the file value, got Host %q", cfg.Host)
+	}
+	if cfg.Port != 7070 {
+		t.Errorf("Expected Port to be 7070, got %d", cfg.Port)
+	}
+	if cfg.Timeout != time.Minute {
+		t.Errorf("Expected Timeout to be 1m, got %v", cfg.Timeout)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"x", "y"}) {
+		t.Errorf("Expected Tags to be [x y], got %v", cfg.Tags)
+	}
+	if !cfg.Meta.Debug || cfg.Meta.Version != "3.0" {
+		t.Errorf("Unexpected Meta: %+v", cfg.Meta)
+	}
+	if cfg.Password != "hunter2" || cfg.Ratio != 0.5 {
+		t.Errorf("Unset flags should keep the defaults: %+v", cfg)
+	}
+}
+
+func TestBindFlags_InvalidTarget(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := BindFlags(fs, FlagsTestConfig{}); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package configo
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/vsysa/configo/internal/parser/defaultValues"
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// FlattenKeys returns the current values of the config struct cfg (or a
+// pointer to one) as a flat map of dotted bind keys to strings, e.g.
+// "meta.version" → "1.0", as needed by key-value stores like Consul or etcd.
+//
+// Slice elements get their index in the key (servers.0.host) and map entries
+// their map key (labels.env). Nil pointers and empty slices and maps produce
+// no keys. Values are written the way the loader reads them: durations like
+// "30s", times in their `timeformat` layout (RFC3339 by default), []byte raw
+// or base64 with `encoding:"base64"`, integers in the base of their `format`
+// tag, e.g. "0644" with `format:"octal"`, and URLs, IP addresses, networks,
+// registered types (see RegisterType) and encoding.TextMarshaler types in
+// their text form.
+//
+// Secret fields are not masked. A cfg that is not a struct gives an empty map.
+func FlattenKeys(cfg interface{}) map[string]string {
+	keys := make(map[string]string)
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return keys
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		flattenStruct(keys, v, "")
+	}
+	return keys
+}
+
+// flattenStruct adds the fields of a struct value under parentKey.
+func flattenStruct(keys map[string]string, v reflect.Value, parentKey string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		if isFlattenedField(field) {
+			flattenStruct(keys, v.Field(i), parentKey)
+			continue
+		}
+		flattenValue(keys, childBindKey(field, parentKey), v.Field(i), field.Tag)
+	}
+}
+
+// flattenValue adds a single value under key. Structs, slices and maps add
+// one key per nested value. tag is the tag of the field the value belongs to.
+func flattenValue(keys map[string]string, key string, v reflect.Value, tag reflect.StructTag) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	if text, ok := flatString(v, tag); ok {
+		keys[key] = text
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		flattenStruct(keys, v, key)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			flattenValue(keys, key+"."+strconv.Itoa(i), v.Index(i), tag)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			flattenValue(keys, key+"."+fmt.Sprint(iter.Key().Interface()), iter.Value(), tag)
+		}
+	}
+}
+
+// flatString renders a single value as a string. It returns false for
+// structs, slices and maps that are flattened into several keys.
+func flatString(v reflect.Value, tag reflect.StructTag) (string, bool) {
+	switch {
+	case walker.TextType(v.Type()):
+		return walker.TextString(v), true
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String(), true
+	case v.Type() == timeType:
+		return v.Interface().(time.Time).Format(defaultValues.TimeLayout(tag)), true
+	case v.Type() == bytesType:
+		if tag.Get("encoding") == "base64" {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), true
+		}
+		return string(v.Bytes()), true
+	}
+
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text), true
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if base := walker.IntBase(tag); base != 10 {
+			return walker.FormatInt(v.Int(), base, walker.YAML), true
+		}
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if base := walker.IntBase(tag); base != 10 {
+			return walker.FormatUint(v.Uint(), base, walker.YAML), true
+		}
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), true
+	default:
+		return "", false
+	}
+}
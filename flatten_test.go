@@ -0,0 +1,108 @@
+package configo
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type FlattenServer struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
+
+type FlattenMeta struct {
+	Version string `mapstructure:"version"`
+}
+
+type FlattenTestConfig struct {
+	FlattenMeta `mapstructure:",squash"`
+
+	Name     string            `mapstructure:"name"`
+	Debug    bool              `mapstructure:"debug"`
+	Ratio    float64           `mapstructure:"ratio"`
+	Timeout  time.Duration     `mapstructure:"timeout"`
+	Started  time.Time         `mapstructure:"started" timeformat:"2006-01-02"`
+	Key      []byte            `mapstructure:"key" encoding:"base64"`
+	Endpoint url.URL           `mapstructure:"endpoint"`
+	Addr     net.IP            `mapstructure:"addr"`
+	Tags     []string          `mapstructure:"tags"`
+	Servers  []FlattenServer   `mapstructure:"servers"`
+	Labels   map[string]string `mapstructure:"labels"`
+	Backup   *FlattenServer    `mapstructure:"backup"`
+	Empty    []string          `mapstructure:"empty"`
+	Ignored  string            `mapstructure:"-"`
+	internal string
+}
+
+func TestFlattenKeys(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/api")
+	cfg := FlattenTestConfig{
+		FlattenMeta: FlattenMeta{Version: "1.0"},
+		Name:        "app",
+		Debug:       true,
+		Ratio:       0.5,
+		Timeout:     30 * time.Second,
+		Started:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Key:         []byte("secret"),
+		Endpoint:    *endpoint,
+		Addr:        net.ParseIP("10.0.0.1"),
+		Tags:        []string{"a", "b"},
+		Servers:     []FlattenServer{{Host: "one", Port: 80}, {Host: "two", Port: 81}},
+		Labels:      map[string]string{"env": "prod"},
+		Ignored:     "x",
+		internal:    "y",
+	}
+
+	expected := map[string]string{
+		"version":        "1.0",
+		"name":           "app",
+		"debug":          "true",
+		"ratio":          "0.5",
+		"timeout":        "30s",
+		"started":        "2024-01-02",
+		"key":            "c2VjcmV0",
+		"endpoint":       "https://example.com/api",
+		"addr":           "10.0.0.1",
+		"tags.0":         "a",
+		"tags.1":         "b",
+		"servers.0.host": "one",
+		"servers.0.port": "80",
+		"servers.1.host": "two",
+		"servers.1.port": "81",
+		"labels.env":     "prod",
+	}
+
+	// Указатель и значение дают одинаковый результат
+	for _, in := range []interface{}{cfg, &cfg} {
+		got := FlattenKeys(in)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("FlattenKeys(%T):\ngot:  %v\nwant: %v", in, got, expected)
+		}
+	}
+}
+
+func TestFlattenKeys_PointerAndNonStruct(t *testing.T) {
+	cfg := FlattenTestConfig{Backup: &FlattenServer{Host: "backup"}}
+	got := FlattenKeys(&cfg)
+	if got["backup.host"] != "backup" || got["backup.port"] != "0" {
+		t.Errorf("expected backup keys, got %v", got)
+	}
+
+	if got := FlattenKeys((*FlattenTestConfig)(nil)); len(got) != 0 {
+		t.Errorf("expected no keys for a nil pointer, got %v", got)
+	}
+	if got := FlattenKeys("not a struct"); len(got) != 0 {
+		t.Errorf("expected no keys for a non-struct, got %v", got)
+	}
+}
+
+// Большие числа с плавающей точкой записываются без экспоненты
+func TestFlattenKeys_LargeFloat(t *testing.T) {
+	got := FlattenKeys(FlattenTestConfig{Ratio: 100000000000})
+	if got["ratio"] != "100000000000" {
+		t.Errorf("expected ratio without an exponent, got %q", got["ratio"])
+	}
+}
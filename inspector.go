@@ -2,16 +2,312 @@ package configo
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
+	"github.com/vsysa/configo/internal/parser/configmap"
+	"github.com/vsysa/configo/internal/parser/defaultValues"
 	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/json"
+	"github.com/vsysa/configo/internal/parser/markdown"
+	"github.com/vsysa/configo/internal/parser/options"
+	"github.com/vsysa/configo/internal/parser/properties"
+	"github.com/vsysa/configo/internal/parser/schema"
+	"github.com/vsysa/configo/internal/parser/toml"
 	"github.com/vsysa/configo/internal/parser/yaml"
 
 	"unicode/utf8"
 )
 
-func GenerateYAMLTemplate(cfg interface{}, printDescription bool) string {
-	return yaml.GenerateYAMLTemplate(cfg, printDescription)
+// TemplateOption configures template generation. The options apply to every
+// format that supports them.
+type TemplateOption = options.Option
+
+// Alignment controls how help comments are aligned in generated templates.
+type Alignment = options.Alignment
+
+const (
+	// AlignGlobal aligns every comment of the template to a single column.
+	AlignGlobal = options.AlignGlobal
+
+	// AlignPerBlock aligns comments of sibling keys only, so every nested
+	// section gets its own comment column.
+	AlignPerBlock = options.AlignPerBlock
+)
+
+// WithAlignment selects the comment alignment mode. AlignGlobal is the default.
+func WithAlignment(alignment Alignment) TemplateOption {
+	return options.WithAlignment(alignment)
+}
+
+// StringQuoting controls how string values are quoted in YAML templates.
+type StringQuoting = options.StringQuoting
+
+const (
+	// QuoteAlways writes every string in double quotes, e.g.
+	// `host: "localhost"`.
+	QuoteAlways = options.QuoteAlways
+
+	// QuoteWhenNeeded quotes only the strings YAML would misread as another
+	// value or that would break the document: strings looking like numbers,
+	// booleans or null ("123", "true", "on", "~"), strings with leading or
+	// trailing spaces and strings with special characters like "a: b".
+	QuoteWhenNeeded = options.QuoteWhenNeeded
+
+	// QuoteNever writes every string as is. Strings YAML misreads change
+	// their meaning, so use it only for templates with known values.
+	QuoteNever = options.QuoteNever
+)
+
+// WithStringQuoting selects how string values, including durations, times
+// and other values written as text, are quoted in YAML templates and in
+// GenerateYAMLFromValues. QuoteAlways is the default.
+func WithStringQuoting(policy StringQuoting) TemplateOption {
+	return options.WithStringQuoting(policy)
+}
+
+// CommentPlacement controls where help comments are written in YAML templates.
+type CommentPlacement = options.CommentPlacement
+
+const (
+	// CommentInline writes the comment on the line of its key, e.g.
+	// `port: 8080 # The port`.
+	CommentInline = options.CommentInline
+
+	// CommentAbove writes the comment on lines of its own above its key,
+	// indented like the key. Each line of a multi-line help text gets its
+	// own comment line.
+	CommentAbove = options.CommentAbove
+)
+
+// WithCommentPlacement selects where help comments go in YAML templates and
+// in GenerateYAMLFromValues. CommentInline is the default.
+func WithCommentPlacement(placement CommentPlacement) TemplateOption {
+	return options.WithCommentPlacement(placement)
+}
+
+// WithCommentWidth wraps the comments written above their keys with
+// CommentAbove at word boundaries, so that their lines, indentation included,
+// are at most width characters long. A single word longer than that is kept
+// whole. It has no effect on inline comments.
+func WithCommentWidth(width int) TemplateOption {
+	return options.WithCommentWidth(width)
+}
+
+// WithBoolHints appends "(true|false)" to the comments of bool fields.
+func WithBoolHints() TemplateOption {
+	return options.WithBoolHints()
+}
+
+// WithEnvNames appends "# env: NAME" to the comments of fields that have
+// a fixed `env:"NAME,fixed"` tag.
+func WithEnvNames() TemplateOption {
+	return options.WithEnvNames()
+}
+
+// WithSecretPlaceholders renders secret fields in YAML templates as empty
+// strings instead of "***", with "(set via NAME env var)" appended to the
+// help text of those with a fixed `env:"NAME,fixed"` tag.
+func WithSecretPlaceholders() TemplateOption {
+	return options.WithSecretPlaceholders()
+}
+
+// WithCommentPrefix sets the string starting every help comment, e.g. "##".
+// The default is "#".
+func WithCommentPrefix(prefix string) TemplateOption {
+	return options.WithCommentPrefix(prefix)
+}
+
+// WithAlignChar sets the character padding lines up to the comment column,
+// e.g. '\t' to align comments at the next tab stop. The default is a space.
+func WithAlignChar(c byte) TemplateOption {
+	return options.WithAlignChar(c)
+}
+
+// WithNullPointers renders nil pointers as null in GenerateYAMLFromValues
+// instead of the zero value of the pointed-to type.
+func WithNullPointers() TemplateOption {
+	return options.WithNullPointers()
+}
+
+// WithOptionalCommented renders the optional fields of YAML templates commented
+// out line by line, so operators can uncomment what they need: pointers to
+// structs and fields tagged with `optional:"true"`. Required fields are never
+// commented out.
+func WithOptionalCommented(enabled bool) TemplateOption {
+	return options.WithOptionalCommented(enabled)
+}
+
+// WithOmitEmptyDefaults renders minimal YAML templates: fields whose default
+// is the zero value of their type (no default, `default:"false"`,
+// `default:"0"`, ...) are left out unless they are required or have a help
+// text, and so are nested structs left without fields.
+func WithOmitEmptyDefaults(enabled bool) TemplateOption {
+	return options.WithOmitEmptyDefaults(enabled)
+}
+
+// WithEmptyCollections renders truly optional collections literally in YAML
+// templates: slices of scalars without a default become `options: []` and
+// maps of scalars without `example_key` or `example_value` tags become
+// `settings: {}`, instead of an invented sample element. Help comments are
+// rendered as usual. Collections of structs keep their sample element, which
+// documents their fields.
+func WithEmptyCollections(enabled bool) TemplateOption {
+	return options.WithEmptyCollections(enabled)
+}
+
+// WithProfiles sets the active profiles of YAML templates and
+// GenerateYAMLFromValues, e.g. WithProfiles("community"). A field tagged with
+// `profiles:"enterprise,cloud"` is rendered only if any of its profiles is
+// active, together with its nested fields; fields without the tag always
+// are. Without WithProfiles every field is rendered.
+func WithProfiles(profiles ...string) TemplateOption {
+	return options.WithProfiles(profiles...)
+}
+
+// WithHeader writes header verbatim before the first key of the generated
+// YAML, TOML, .properties and .env templates and of GenerateYAMLFromValues,
+// e.g. a license banner. The caller supplies the comment prefixes:
+// WithHeader("# Copyright Example"). A newline is added if the header doesn't
+// end with one. JSON has no comments, so GenerateJSONTemplate takes no
+// template options and never writes a header.
+func WithHeader(header string) TemplateOption {
+	return options.WithHeader(header)
+}
+
+// WithFooter writes footer verbatim after the last line of the generated
+// templates, like WithHeader does at the top, and likewise not in JSON
+// templates. The trailing newline policy applies to the footer.
+func WithFooter(footer string) TemplateOption {
+	return options.WithFooter(footer)
+}
+
+// WithoutTrailingNewline drops the newline ending the generated YAML, TOML,
+// .properties and .env templates, e.g. to embed them into a larger document.
+// Without it a non-empty template ends with exactly one newline; a struct
+// without fields always gives an empty template.
+func WithoutTrailingNewline() TemplateOption {
+	return options.WithoutTrailingNewline()
+}
+
+// WithEnvNamingFrom derives the environment variable names shown in the docs
+// (GenerateEnvHelp, GenerateEnvTemplate and the WithEnvNames annotations)
+// from the env options among opts, e.g. WithEnvPrefix and WithEnvSeparator.
+// Passing the options given to Load keeps the docs in sync with the loader.
+func WithEnvNamingFrom(opts ...LoaderOption) TemplateOption {
+	return options.WithEnvNaming(envNamingOf(opts))
+}
+
+// envNamingOf returns the environment variable naming set by the loader options.
+func envNamingOf(opts []LoaderOption) env.Naming {
+	l := &loader{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l.envNaming
+}
+
+// GenerateYAMLTemplate generates a YAML template from the `default` and `help`
+// tags of cfg. Errors are logged and an empty string is returned; use
+// GenerateYAMLTemplateE to handle them.
+func GenerateYAMLTemplate(cfg interface{}, printDescription bool, opts ...TemplateOption) string {
+	return yaml.GenerateYAMLTemplate(cfg, printDescription, opts...)
+}
+
+// GenerateYAMLTemplateE works like GenerateYAMLTemplate, but returns an error
+// when cfg is not a struct or its fields can't be rendered.
+func GenerateYAMLTemplateE(cfg interface{}, printDescription bool, opts ...TemplateOption) (string, error) {
+	return yaml.GenerateYAMLTemplateE(cfg, printDescription, opts...)
+}
+
+// GenerateYAMLFromValues generates a commented YAML document from the current
+// values of a populated config struct instead of its `default` tags.
+func GenerateYAMLFromValues(cfg interface{}, printDescription bool, opts ...TemplateOption) string {
+	return yaml.GenerateYAMLFromValues(cfg, printDescription, opts...)
+}
+
+// DumpConfig serializes a populated config struct to YAML. When maskSecrets is
+// true, values of fields tagged with `secret:"true"` are replaced by "***".
+func DumpConfig(cfg interface{}, maskSecrets bool) (string, error) {
+	return yaml.DumpConfig(cfg, maskSecrets)
+}
+
+// GenerateJSONTemplate generates a JSON template for the config struct.
+// When withComments is true, help texts are emitted as "_<key>_comment" members.
+// JSON has no comments, so the header, footer and comment options don't
+// apply and it takes no TemplateOption.
+func GenerateJSONTemplate(cfg interface{}, withComments bool) string {
+	return json.GenerateJSONTemplate(cfg, withComments)
+}
+
+// GenerateTOMLTemplate generates a TOML template for the config struct.
+// Nested structs become [table] headers and slices of structs [[array]] tables.
+// The comment options (WithCommentPrefix, WithAlignChar) apply.
+func GenerateTOMLTemplate(cfg interface{}, withComments bool, opts ...TemplateOption) string {
+	return toml.GenerateTOMLTemplate(cfg, withComments, opts...)
+}
+
+// GeneratePropertiesTemplate generates a .properties template for the config
+// struct. Nested keys are joined with dots and help texts become comments
+// above each line. Slices of primitives are comma-joined, slices of structs
+// use indexed keys (servers.0.host). WithCommentPrefix applies.
+func GeneratePropertiesTemplate(cfg interface{}, opts ...TemplateOption) string {
+	return properties.GeneratePropertiesTemplate(cfg, opts...)
+}
+
+// MarkdownOption configures GenerateMarkdownDocs.
+type MarkdownOption = markdown.Option
+
+// WithSectionTables emits one Markdown table per top-level struct, each under
+// its own "## key" heading, instead of a single table.
+func WithSectionTables() MarkdownOption {
+	return markdown.WithSectionTables()
+}
+
+// WithMarkdownEnvNamingFrom derives the names of the Env Var column from the
+// env options among opts, like WithEnvNamingFrom does for templates.
+func WithMarkdownEnvNamingFrom(opts ...LoaderOption) MarkdownOption {
+	return markdown.WithEnvNaming(envNamingOf(opts))
+}
+
+// GenerateMarkdownDocs generates a Markdown reference table of the config
+// struct with the columns Key, Type, Default, Env Var, Required and
+// Description. Nested structs produce section header rows. The output is
+// deterministic, so it can be committed to the repository.
+func GenerateMarkdownDocs(cfg interface{}, opts ...MarkdownOption) string {
+	return markdown.GenerateMarkdownDocs(cfg, opts...)
+}
+
+// ConfigMapOption configures GenerateConfigMap.
+type ConfigMapOption = configmap.Option
+
+// WithSecretManifest makes GenerateConfigMap append a Secret manifest named
+// "<name>-secret" with one base64-encoded placeholder per field tagged with
+// `secret:"true"`, keyed by its environment variable name.
+func WithSecretManifest() ConfigMapOption {
+	return configmap.WithSecretManifest()
+}
+
+// GenerateConfigMap generates a Kubernetes ConfigMap manifest embedding the
+// YAML template (see GenerateYAMLTemplate) under the "config.yaml" data key.
+// The output can be applied with kubectl apply; the namespace is omitted
+// when empty.
+func GenerateConfigMap(cfg interface{}, name, namespace string, opts ...ConfigMapOption) ([]byte, error) {
+	return configmap.GenerateConfigMap(cfg, name, namespace, opts...)
+}
+
+// DefaultValueOf returns the default value of a struct field the way Configo
+// resolves it, as a JSON literal that is also valid YAML, e.g. `"localhost"`,
+// `8080` or `["a","b"]`. The bool reports whether the field has a `default`
+// tag; without one the literal of the zero value is returned.
+func DefaultValueOf(field reflect.StructField) (string, bool) {
+	return defaultValues.DefaultValueOf(field)
+}
+
+// GenerateJSONSchema generates a JSON Schema (draft 2020-12) describing the
+// config struct, e.g. for editor autocompletion. The output is deterministic.
+func GenerateJSONSchema(cfg interface{}) ([]byte, error) {
+	return schema.GenerateJSONSchema(cfg)
 }
 
 // EnvHelpFormat defines the type of output format for environment variable docs.
@@ -35,9 +331,12 @@ const (
 // a struct and has an `env` tag, that tag becomes the prefix for the nested fields.
 //
 // For example, if a parent struct has `env:"db"` and the nested struct has a field
-// with `env:"host"`, it will generate `DB_HOST`.
-func GenerateEnvHelp(cfg interface{}, format EnvHelpFormat) string {
-	lines := env.GetEnvs(cfg)
+// with `mapstructure:"host"` or `env:"host"`, it will generate `DB_HOST`. An `env`
+// tag with the "fixed" option on a non-struct field is used as is, e.g.
+// `env:"DATABASE_URL,fixed"` stays `DATABASE_URL`.
+// WithEnvNamingFrom applies.
+func GenerateEnvHelp(cfg interface{}, format EnvHelpFormat, opts ...TemplateOption) string {
+	lines := env.GetEnvsWithNaming(cfg, options.New(opts...).EnvNaming)
 
 	// Choose the output format based on the 'format' parameter
 	switch format {
@@ -52,6 +351,48 @@ func GenerateEnvHelp(cfg interface{}, format EnvHelpFormat) string {
 	}
 }
 
+// GenerateEnvTemplate generates a .env-style template with one line per leaf
+// field, using the same variable names as GenerateEnvHelp:
+//
+//	META_VERSION=1.0 # App version
+//
+// Nested structs are flattened into prefixed names (META_VERSION). Values are
+// written in the form the loader accepts for a single variable: slices of
+// primitives as the comma-separated list (or JSON array) from the `default`
+// tag, maps and slices of structs as JSON. Values with spaces, quotes or
+// other special characters are quoted. WithCommentPrefix and
+// WithEnvNamingFrom apply.
+func GenerateEnvTemplate(cfg interface{}, opts ...TemplateOption) string {
+	o := options.New(opts...)
+	var sb strings.Builder
+	for _, info := range env.GetEnvsWithNaming(cfg, o.EnvNaming) {
+		value := info.DefaultValue
+		if value == "[]" {
+			value = ""
+		}
+
+		line := info.EnvVar + "=" + quoteEnvValue(value)
+		if info.HelpText != "" {
+			line += " " + o.Comment(info.HelpText)
+		}
+		sb.WriteString(line + "\n")
+	}
+	return o.Finish(sb.String())
+}
+
+// quoteEnvValue quotes a .env value if needed: single quotes keep the value
+// literal, double quotes are used when the value itself has single quotes.
+func quoteEnvValue(value string) string {
+	if !strings.ContainsAny(value, " \t#\"'\\$`") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return `"` + replacer.Replace(value) + `"`
+}
+
 // formatEnvHelpInline displays each environment variable on a single line.
 // Example:
 //
@@ -0,0 +1,255 @@
+package configo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type EnvTemplateTestConfig struct {
+	Name    string            `mapstructure:"name" default:"my app" help:"Application name"`
+	Meta    LoaderMetaConfig  `mapstructure:"meta"`
+	Tags    []string          `mapstructure:"tags" default:"a,b"`
+	Hosts   []string          `mapstructure:"hosts"`
+	Labels  map[string]string `mapstructure:"labels"`
+	URL     string            `mapstructure:"url" env:"DATABASE_URL,fixed" help:"Database URL"`
+	Secret  string            `mapstructure:"secret" env:"-"`
+	Message string            `mapstructure:"message" default:"it's $HOME"`
+}
+
+func TestGenerateEnvTemplate(t *testing.T) {
+	expected := `NAME='my app' # Application name
+META_VERSION=1.0
+META_BUILD=dev
+TAGS=a,b
+HOSTS=
+LABELS='{"key":"value"}'
+DATABASE_URL= # Database URL
+MESSAGE="it's \$HOME"
+`
+
+	if got := GenerateEnvTemplate(EnvTemplateTestConfig{}); got != expected {
+		t.Errorf("Unexpected env template:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+// Значения секретов не попадают ни в .env шаблон, ни в справку
+func TestGenerateEnvTemplate_Secret(t *testing.T) {
+	cfg := struct {
+		Host     string `mapstructure:"host" default:"localhost"`
+		Password string `mapstructure:"password" env:"DB_PASSWORD,fixed" default:"hunter2" secret:"true" help:"The DB password"`
+		Auth     struct {
+			Token string `mapstructure:"token" default:"t0ken"`
+		} `mapstructure:"auth" secret:"true"`
+	}{}
+
+	expected := `HOST=localhost
+DB_PASSWORD= # The DB password
+AUTH_TOKEN=
+`
+	if got := GenerateEnvTemplate(cfg); got != expected {
+		t.Errorf("Unexpected env template:\n%s\nwant:\n%s", got, expected)
+	}
+
+	for _, format := range []EnvHelpFormat{AsciiTable, Inline, MarkdownTable} {
+		help := GenerateEnvHelp(cfg, format)
+		if strings.Contains(help, "hunter2") || strings.Contains(help, "t0ken") {
+			t.Errorf("Secret default leaked into the env help (format %d):\n%s", format, help)
+		}
+		if !strings.Contains(help, "localhost") {
+			t.Errorf("Expected the default of host in the env help (format %d):\n%s", format, help)
+		}
+	}
+}
+
+func TestGenerateEnvTemplate_CommentPrefix(t *testing.T) {
+	cfg := struct {
+		Name string `mapstructure:"name" default:"app" help:"Application name"`
+	}{}
+
+	expected := "NAME=app ## Application name\n"
+	if got := GenerateEnvTemplate(cfg, WithCommentPrefix("##")); got != expected {
+		t.Errorf("Unexpected env template:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestGenerateEnvTemplate_TrailingNewline(t *testing.T) {
+	cfg := struct {
+		Name string `mapstructure:"name" default:"app"`
+		Port int    `mapstructure:"port" default:"8080"`
+	}{}
+
+	if got := GenerateEnvTemplate(cfg, WithoutTrailingNewline()); got != "NAME=app\nPORT=8080" {
+		t.Errorf("Unexpected env template: %q", got)
+	}
+	// Пустая структура даёт пустой шаблон
+	if got := GenerateEnvTemplate(struct{}{}); got != "" {
+		t.Errorf("Expected an empty env template, got %q", got)
+	}
+}
+
+func TestDefaultValueOf(t *testing.T) {
+	typ := reflect.TypeOf(EnvTemplateTestConfig{})
+
+	name, _ := typ.FieldByName("Name")
+	if value, ok := DefaultValueOf(name); value != `"my app"` || !ok {
+		t.Errorf("Unexpected default of Name: %s, %v", value, ok)
+	}
+	tags, _ := typ.FieldByName("Tags")
+	if value, ok := DefaultValueOf(tags); value != `["a","b"]` || !ok {
+		t.Errorf("Unexpected default of Tags: %s, %v", value, ok)
+	}
+	// Без тега default возвращается нулевое значение
+	hosts, _ := typ.FieldByName("Hosts")
+	if value, ok := DefaultValueOf(hosts); value != "[]" || ok {
+		t.Errorf("Unexpected default of Hosts: %s, %v", value, ok)
+	}
+}
+
+func TestGenerateEnvTemplate_EnvNaming(t *testing.T) {
+	cfg := struct {
+		Meta LoaderMetaConfig `mapstructure:"meta"`
+		URL  string           `mapstructure:"url" env:"DATABASE_URL,fixed"`
+	}{}
+
+	expected := `MYAPP__META__VERSION=1.0
+MYAPP__META__BUILD=dev
+DATABASE_URL=
+`
+	got := GenerateEnvTemplate(cfg, WithEnvNamingFrom(WithEnvPrefix("myapp"), WithEnvSeparator("__")))
+	if got != expected {
+		t.Errorf("Unexpected env template:\n%s\nwant:\n%s", got, expected)
+	}
+
+	help := GenerateEnvHelp(cfg, Inline, WithEnvNamingFrom(WithEnvPrefix("myapp")))
+	if !strings.Contains(help, "MYAPP_META_VERSION") {
+		t.Errorf("Env help should use the prefix:\n%s", help)
+	}
+}
+
+type ScalarTemplateTestConfig struct {
+	Port    int       `mapstructure:"port" yaml:"port" json:"port" default:"08080"`
+	Enabled bool      `mapstructure:"enabled" yaml:"enabled" json:"enabled" default:"TRUE"`
+	Ratio   float64   `mapstructure:"ratio" yaml:"ratio" json:"ratio" default:"1"`
+	Count   uint      `mapstructure:"count" yaml:"count" json:"count" default:"10"`
+	Weights []float64 `mapstructure:"weights" yaml:"weights" json:"weights" default:"1,2.5"`
+	Name    string    `mapstructure:"name" yaml:"name" json:"name" default:"8080"`
+}
+
+// Одна и та же структура даёт одинаковые типизированные значения во всех форматах.
+func TestTemplates_ScalarLiterals(t *testing.T) {
+	templates := map[Format]string{
+		YAML: GenerateYAMLTemplate(ScalarTemplateTestConfig{}, false),
+		JSON: GenerateJSONTemplate(ScalarTemplateTestConfig{}, false),
+		TOML: GenerateTOMLTemplate(ScalarTemplateTestConfig{}, false),
+	}
+	expected := ScalarTemplateTestConfig{
+		Port: 8080, Enabled: true, Ratio: 1, Count: 10, Weights: []float64{1, 2.5}, Name: "8080",
+	}
+
+	for format, template := range templates {
+		if !strings.Contains(template, `"8080"`) || strings.Contains(template, `"true"`) {
+			t.Errorf("%s: expected a quoted string and unquoted literals:\n%s", format.configType(), template)
+		}
+		for _, literal := range []string{"8080", "true", "10"} {
+			if !strings.Contains(template, " "+literal) {
+				t.Errorf("%s: expected the literal %s:\n%s", format.configType(), literal, template)
+			}
+		}
+
+		var cfg ScalarTemplateTestConfig
+		if err := LoadFromBytes(&cfg, []byte(template), format); err != nil {
+			t.Fatalf("%s: %v\n%s", format.configType(), err, template)
+		}
+		if !reflect.DeepEqual(cfg, expected) {
+			t.Errorf("%s: expected %+v, got %+v\n%s", format.configType(), expected, cfg, template)
+		}
+	}
+}
+
+type IntBaseTemplateTestConfig struct {
+	Mode  uint32 `mapstructure:"mode" yaml:"mode" json:"mode" default:"0644" format:"octal"`
+	Flags int    `mapstructure:"flags" yaml:"flags" json:"flags" default:"0x1F" format:"hex"`
+	Count int    `mapstructure:"count" yaml:"count" json:"count" default:"0644"`
+}
+
+// Восьмеричные и шестнадцатеричные значения переживают шаблон и загрузку.
+func TestTemplates_IntBases(t *testing.T) {
+	templates := map[Format]struct{ template, mode, flags string }{
+		YAML: {GenerateYAMLTemplate(IntBaseTemplateTestConfig{}, false), "mode: 0644", "flags: 0x1F"},
+		JSON: {GenerateJSONTemplate(IntBaseTemplateTestConfig{}, false), `"mode": 420`, `"flags": 31`},
+		TOML: {GenerateTOMLTemplate(IntBaseTemplateTestConfig{}, false), "mode = 0o644", "flags = 0x1F"},
+	}
+	expected := IntBaseTemplateTestConfig{Mode: 0o644, Flags: 0x1F, Count: 644}
+
+	for format, tt := range templates {
+		if !strings.Contains(tt.template, tt.mode) || !strings.Contains(tt.template, tt.flags) {
+			t.Errorf("%s: expected %s and %s:\n%s", format.configType(), tt.mode, tt.flags, tt.template)
+		}
+
+		var cfg IntBaseTemplateTestConfig
+		if err := LoadFromBytes(&cfg, []byte(tt.template), format); err != nil {
+			t.Fatalf("%s: %v\n%s", format.configType(), err, tt.template)
+		}
+		if cfg != expected {
+			t.Errorf("%s: expected %+v, got %+v\n%s", format.configType(), expected, cfg, tt.template)
+		}
+	}
+}
+
+type ProfilesTemplateTestConfig struct {
+	Host  string `mapstructure:"host" yaml:"host" default:"localhost"`
+	Seats int    `mapstructure:"seats" yaml:"seats" default:"100" profiles:"enterprise"`
+}
+
+// Шаблон редакции без чужих полей загружается обратно.
+func TestGenerateYAMLTemplate_Profiles(t *testing.T) {
+	template := GenerateYAMLTemplate(ProfilesTemplateTestConfig{}, false, WithProfiles("community"))
+	if strings.Contains(template, "seats") {
+		t.Errorf("expected no seats in the community template:\n%s", template)
+	}
+	if !strings.Contains(GenerateYAMLTemplate(ProfilesTemplateTestConfig{}, false, WithProfiles("enterprise")), "seats: 100") {
+		t.Errorf("expected seats in the enterprise template")
+	}
+
+	var cfg ProfilesTemplateTestConfig
+	if err := LoadFromBytes(&cfg, []byte(template), YAML); err != nil {
+		t.Fatalf("LoadFromBytes failed: %v", err)
+	}
+	if cfg.Host != "localhost" || cfg.Seats != 100 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+// Заголовок и подпись не мешают загрузке шаблона.
+func TestTemplates_HeaderFooter(t *testing.T) {
+	opts := []TemplateOption{
+		WithHeader("# Copyright Example Corp.\n# SPDX-License-Identifier: MIT\n"),
+		WithFooter("# Generated, do not edit."),
+	}
+	templates := map[Format]string{
+		YAML: GenerateYAMLTemplate(ProfilesTemplateTestConfig{}, true, opts...),
+		TOML: GenerateTOMLTemplate(ProfilesTemplateTestConfig{}, true, opts...),
+	}
+	for format, template := range templates {
+		if !strings.HasPrefix(template, "# Copyright Example Corp.\n# SPDX-License-Identifier: MIT\nhost") {
+			t.Errorf("%s: expected the header before the first key:\n%s", format.configType(), template)
+		}
+		if !strings.HasSuffix(template, "\n# Generated, do not edit.\n") {
+			t.Errorf("%s: expected the footer at the end:\n%s", format.configType(), template)
+		}
+
+		var cfg ProfilesTemplateTestConfig
+		if err := LoadFromBytes(&cfg, []byte(template), format); err != nil {
+			t.Fatalf("%s: %v\n%s", format.configType(), err, template)
+		}
+		if cfg.Host != "localhost" || cfg.Seats != 100 {
+			t.Errorf("%s: unexpected config %+v", format.configType(), cfg)
+		}
+	}
+
+	properties := GeneratePropertiesTemplate(ProfilesTemplateTestConfig{}, WithHeader("# Banner"))
+	if !strings.HasPrefix(properties, "# Banner\n") {
+		t.Errorf("expected the header in the properties template:\n%s", properties)
+	}
+}
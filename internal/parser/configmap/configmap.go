@@ -0,0 +1,152 @@
+package configmap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/walker"
+	"github.com/vsysa/configo/internal/parser/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// DataKey is the ConfigMap data key holding the YAML template.
+const DataKey = "config.yaml"
+
+// secretPlaceholder is the value of every key of the generated Secret.
+const secretPlaceholder = "CHANGE_ME"
+
+// Options configures manifest generation.
+type Options struct {
+	// SecretManifest emits a Secret manifest for the fields tagged with
+	// `secret:"true"` after the ConfigMap.
+	SecretManifest bool
+}
+
+// Option configures manifest generation.
+type Option func(*Options)
+
+// WithSecretManifest appends a Secret manifest named "<name>-secret" holding
+// one key per secret field, named after its environment variable, with a
+// base64-encoded placeholder value. Mounted with envFrom, the Secret overrides
+// the masked values of the ConfigMap.
+func WithSecretManifest() Option {
+	return func(o *Options) {
+		o.SecretManifest = true
+	}
+}
+
+// GenerateConfigMap generates a Kubernetes ConfigMap manifest embedding the
+// YAML template of cfg under the "config.yaml" data key. The namespace is
+// omitted when empty. Values of secret fields are masked in the template.
+func GenerateConfigMap(cfg interface{}, name, namespace string, opts ...Option) ([]byte, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if name == "" {
+		return nil, errors.New("configmap name is required")
+	}
+
+	template, err := yaml.GenerateYAMLTemplateE(cfg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := []*yamlv3.Node{
+		manifest("ConfigMap", name, namespace, "data", []keyValue{{Key: DataKey, Value: template}}),
+	}
+	if o.SecretManifest {
+		var data []keyValue
+		placeholder := base64.StdEncoding.EncodeToString([]byte(secretPlaceholder))
+		for _, envVar := range secretEnvVars(cfg) {
+			data = append(data, keyValue{Key: envVar, Value: placeholder})
+		}
+		if len(data) > 0 {
+			secret := manifest("Secret", name+"-secret", namespace, "data", data)
+			secret.Content = append(secret.Content, scalar("type"), scalar("Opaque"))
+			docs = append(docs, secret)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// keyValue is a single entry of the data of a manifest.
+type keyValue struct {
+	Key   string
+	Value string
+}
+
+// manifest builds a v1 manifest of the given kind with its data entries.
+// Multi-line values are rendered as literal blocks.
+func manifest(kind, name, namespace, dataField string, data []keyValue) *yamlv3.Node {
+	metadata := mapping(scalar("name"), scalar(name))
+	if namespace != "" {
+		metadata.Content = append(metadata.Content, scalar("namespace"), scalar(namespace))
+	}
+
+	values := mapping()
+	for _, kv := range data {
+		value := scalar(kv.Value)
+		if strings.Contains(kv.Value, "\n") {
+			value.Style = yamlv3.LiteralStyle
+		}
+		values.Content = append(values.Content, scalar(kv.Key), value)
+	}
+
+	return mapping(
+		scalar("apiVersion"), scalar("v1"),
+		scalar("kind"), scalar(kind),
+		scalar("metadata"), metadata,
+		scalar(dataField), values,
+	)
+}
+
+// mapping builds a mapping node from alternating key and value nodes.
+func mapping(content ...*yamlv3.Node) *yamlv3.Node {
+	return &yamlv3.Node{Kind: yamlv3.MappingNode, Content: content}
+}
+
+// scalar builds a string scalar node.
+func scalar(value string) *yamlv3.Node {
+	return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// secretEnvVars returns the environment variable names of the fields tagged
+// with `secret:"true"`, including the fields of secret structs.
+func secretEnvVars(cfg interface{}) []string {
+	secret := make(map[string]bool)
+	walker.Walk(reflect.TypeOf(cfg), func(f walker.Field) error {
+		for p := &f; p != nil; p = p.Parent {
+			if p.Tag.Get("secret") == "true" {
+				secret[f.BindKey] = true
+				break
+			}
+		}
+		return nil
+	})
+
+	var envVars []string
+	for _, info := range env.GetEnvs(cfg) {
+		if secret[info.BindKey] {
+			envVars = append(envVars, info.EnvVar)
+		}
+	}
+	return envVars
+}
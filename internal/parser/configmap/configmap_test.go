@@ -0,0 +1,113 @@
+package configmap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vsysa/configo/internal/parser/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+type manifestDoc struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Data map[string]string `yaml:"data"`
+	Type string            `yaml:"type"`
+}
+
+type cmDatabase struct {
+	Host     string `yaml:"host" default:"db" help:"Database host"`
+	Password string `yaml:"password" default:"hunter2" secret:"true"`
+}
+
+type cmConfig struct {
+	Name     string     `yaml:"name" default:"app" help:"App name"`
+	Database cmDatabase `yaml:"database"`
+	Token    string     `yaml:"token" env:"API_TOKEN" secret:"true"`
+}
+
+func decodeManifests(t *testing.T, data []byte) []manifestDoc {
+	t.Helper()
+	var docs []manifestDoc
+	decoder := yamlv3.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc manifestDoc
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs
+		}
+		require.NoError(t, err)
+		docs = append(docs, doc)
+	}
+}
+
+func TestGenerateConfigMap(t *testing.T) {
+	out, err := GenerateConfigMap(cmConfig{}, "app-config", "prod")
+	require.NoError(t, err)
+
+	docs := decodeManifests(t, out)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "v1", docs[0].APIVersion)
+	assert.Equal(t, "ConfigMap", docs[0].Kind)
+	assert.Equal(t, "app-config", docs[0].Metadata.Name)
+	assert.Equal(t, "prod", docs[0].Metadata.Namespace)
+
+	template := yaml.GenerateYAMLTemplate(cmConfig{}, true)
+	assert.Equal(t, map[string]string{"config.yaml": template}, docs[0].Data)
+	assert.NotContains(t, string(out), "hunter2")
+	assert.Contains(t, string(out), "  config.yaml: |\n    name: \"app\"")
+}
+
+func TestGenerateConfigMap_NoNamespace(t *testing.T) {
+	out, err := GenerateConfigMap(cmConfig{}, "app-config", "")
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "namespace:")
+}
+
+func TestGenerateConfigMap_SecretManifest(t *testing.T) {
+	out, err := GenerateConfigMap(&cmConfig{}, "app", "prod", WithSecretManifest())
+	require.NoError(t, err)
+
+	docs := decodeManifests(t, out)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "ConfigMap", docs[0].Kind)
+
+	secret := docs[1]
+	assert.Equal(t, "Secret", secret.Kind)
+	assert.Equal(t, "app-secret", secret.Metadata.Name)
+	assert.Equal(t, "prod", secret.Metadata.Namespace)
+	assert.Equal(t, "Opaque", secret.Type)
+
+	placeholder := base64.StdEncoding.EncodeToString([]byte("CHANGE_ME"))
+	assert.Equal(t, map[string]string{
+		"DATABASE_PASSWORD": placeholder,
+		"API_TOKEN":         placeholder,
+	}, secret.Data)
+}
+
+func TestGenerateConfigMap_NoSecrets(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost"`
+	}{}
+
+	out, err := GenerateConfigMap(cfg, "app", "", WithSecretManifest())
+	require.NoError(t, err)
+	assert.Len(t, decodeManifests(t, out), 1)
+}
+
+func TestGenerateConfigMap_Errors(t *testing.T) {
+	_, err := GenerateConfigMap(cmConfig{}, "", "prod")
+	assert.Error(t, err)
+
+	_, err = GenerateConfigMap(42, "app", "prod")
+	assert.Error(t, err)
+}
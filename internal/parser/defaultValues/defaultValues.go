@@ -1,25 +1,55 @@
 package defaultValues
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/vsysa/configo/internal/parser/walker"
 )
 
+// durationType is used to detect time.Duration fields, which would otherwise
+// be treated as plain int64 values.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// timeType is used to detect time.Time fields, which are structs but are
+// configured as a single RFC3339 string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// bytesType is used to detect []byte fields, whose defaults are a single
+// string rather than a list.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// EnabledSection is the `default` tag value of a pointer to a struct that is
+// always allocated, with the defaults of its fields, even if no source sets it.
+const EnabledSection = "enabled"
+
 type DefaultInfo struct {
 	BindKey      string
 	DefaultValue interface{}
+	// Section is the bind key of the optional section holding the field: the
+	// innermost pointer to a struct without `default:"enabled"`. Its default
+	// applies only once a source sets the section. Empty outside of one.
+	Section string
 }
 
 func GetDefaultValues(cfg interface{}) ([]DefaultInfo, error) {
 	var lines []DefaultInfo
-	err := parseDefaultValues(reflect.TypeOf(cfg), "", &lines)
+	err := parseDefaultValues(reflect.TypeOf(cfg), "", "", walker.Expanding{}, &lines)
 	return lines, err
 }
 
-func parseDefaultValues(t reflect.Type, parentBindKey string, lines *[]DefaultInfo) error {
+// parseDefaultValues collects the defaults of the fields of t. expanding
+// holds the struct types on the current path, so that a pointer referring
+// back to one of them is not expanded forever.
+func parseDefaultValues(t reflect.Type, parentBindKey, section string, expanding walker.Expanding, lines *[]DefaultInfo) error {
 	// If the type is a pointer, unwrap it to its element type.
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -27,16 +57,15 @@ func parseDefaultValues(t reflect.Type, parentBindKey string, lines *[]DefaultIn
 	if t.Kind() != reflect.Struct {
 		return fmt.Errorf("not a struct")
 	}
+	expanding[t] = true
+	defer delete(expanding, t)
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-
-		// Skip unexported fields
-		if field.PkgPath != "" {
-			continue
-		}
+	// Fields tagged with `mapstructure:"-"` are skipped, embedded structs
+	// without an explicit name are flattened like in templates.
+	for _, f := range walker.FieldsFunc(t, nil, isIgnored) {
+		field := f.StructField
 
-		msKey := getMapstructureKey(field)
+		msKey := f.BindKey
 		// Build the full bind key
 		childBindKey := parentBindKey
 		if childBindKey != "" && msKey != "" {
@@ -50,104 +79,375 @@ func parseDefaultValues(t reflect.Type, parentBindKey string, lines *[]DefaultIn
 
 		// Recurse deeper if it's a struct (and not a map or slice).
 		// We assume *non*-map, non-slice struct fields can have nested env variables.
-		if fieldKind == reflect.Struct {
+		if fieldKind == reflect.Struct && field.Type != timeType && !walker.TextType(field.Type) {
 			// Recurse into nested struct.
-			err := parseDefaultValues(field.Type, childBindKey, lines)
+			err := parseDefaultValues(field.Type, childBindKey, section, expanding, lines)
 			if err != nil {
 				return err
 			}
 			continue
 		}
 
-		defaultValStr := getDefaultValue(field.Tag)
-		if defaultValStr == "" {
-			continue
-		}
-
-		var defaultValue interface{}
-
-		if fieldKind == reflect.Slice {
-			if !isPrimitive(field.Type.Elem().Kind()) {
-				// array of non primitives not allowed
+		// A pointer to a struct is an optional section: its fields take their
+		// defaults once the section is set, unless it is tagged with
+		// `default:"enabled"`, which always allocates it.
+		if walker.SectionPointer(field.Type) {
+			if expanding.Recursive(field.Type) {
 				continue
 			}
-			if string(defaultValStr[0]) == "[" && string(defaultValStr[len(defaultValStr)-1]) == "]" {
-				// Creating a new slice using reflect
-				sliceType := reflect.SliceOf(field.Type.Elem())
-				slicePtr := reflect.New(sliceType)
-
-				// Decompressing JSON into a slice
-				err := json.Unmarshal([]byte(defaultValStr), slicePtr.Interface())
-				if err != nil {
-					fmt.Printf("cannot unmarshal default value \"%s\" as %s: %s", defaultValStr, field.Type.String(), err)
-					continue
-				}
-				defaultValue = slicePtr.Elem().Interface()
-			} else {
-				defaultValue = strings.Split(defaultValStr, ",")
+			fieldSection := childBindKey
+			switch value := getDefaultValue(field.Tag); value {
+			case "":
+			case EnabledSection:
+				fieldSection = section
+			default:
+				return fmt.Errorf("%s: invalid default %q for a pointer to a struct, expected %q", childBindKey, value, EnabledSection)
 			}
-
-		} else if field.Type.Kind() == reflect.Map {
-			// Creating a map type
-			mapType := reflect.MapOf(field.Type.Key(), field.Type.Elem())
-			mapPtr := reflect.New(mapType)
-
-			err := json.Unmarshal([]byte(defaultValStr), mapPtr.Interface())
+			err := parseDefaultValues(derefType(field.Type), childBindKey, fieldSection, expanding, lines)
 			if err != nil {
-				fmt.Printf("cannot unmarshal default value \"%s\" as %s: %s", defaultValStr, field.Type.String(), err)
-				continue
+				return err
 			}
+			continue
+		}
 
-			defaultValue = mapPtr.Elem().Interface()
-		} else {
-			// Processing of single values (primitives)
-			switch fieldKind {
-			case reflect.String:
-				defaultValue = defaultValStr
-			case reflect.Int, reflect.Int32, reflect.Int64:
-				intValue, err := strconv.ParseInt(defaultValStr, 10, 64)
-				if err != nil {
-					fmt.Printf("cannot parse default value '%s' as integer", defaultValStr)
-					continue
-				}
-				defaultValue = intValue
-			case reflect.Bool:
-				boolValue, err := strconv.ParseBool(defaultValStr)
-				if err != nil {
-					fmt.Printf("cannot parse default value '%s' as boolean", defaultValStr)
-					continue
-				}
-				defaultValue = boolValue
-			case reflect.Float32, reflect.Float64:
-				floatValue, err := strconv.ParseFloat(defaultValStr, 64)
-				if err != nil {
-					fmt.Printf("cannot parse default value '%s' as float", defaultValStr)
-					continue
-				}
-				defaultValue = floatValue
-			default:
-				defaultValue = defaultValStr
-			}
+		if err := walker.CheckMapKeys(field.Type); err != nil {
+			return fmt.Errorf("%s: %w", childBindKey, err)
+		}
+
+		defaultValStr := expandEnv(getDefaultValue(field.Tag))
+		if defaultValStr == "" {
+			continue
+		}
+
+		defaultValue, err := parseDefault(field, defaultValStr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", childBindKey, err)
+		}
+		if defaultValue == nil {
+			continue
 		}
 
 		*lines = append(*lines, DefaultInfo{
 			BindKey:      childBindKey,
 			DefaultValue: defaultValue,
+			Section:      section,
 		})
 	}
 	return nil
 }
 
-// getMapstructureKey returns the part of the key used for Viper bind keys
-// based on mapstructure or the field name, but does not uppercase it.
-// We want something like `db` or `host`, so the final key might be `db.host`.
-func getMapstructureKey(field reflect.StructField) string {
-	msVal := field.Tag.Get("mapstructure")
-	if msVal == "" {
-		// fallback to the lowercase field name
-		return strings.ToLower(field.Name)
+// parseDefault converts the default value of a field to the value bound in
+// Viper. It returns nil for defaults that are skipped, i.e. those of slices
+// of structs. A default that doesn't parse as the field type is an error.
+func parseDefault(field reflect.StructField, value string) (interface{}, error) {
+	switch {
+	case walker.TextType(derefType(field.Type)):
+		// The string form is decoded by the loader, but a malformed default
+		// is reported early.
+		if err := checkText(derefType(field.Type), value); err != nil {
+			return nil, err
+		}
+		return value, nil
+
+	case field.Type == bytesType:
+		// The default is the raw bytes of the string, or base64 with
+		// `encoding:"base64"`.
+		if field.Tag.Get("encoding") != "base64" {
+			return []byte(value), nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode default value %q as base64: %w", value, err)
+		}
+		return decoded, nil
+
+	case field.Type.Kind() == reflect.Slice:
+		elemType := field.Type.Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if !isPrimitive(elemType.Kind()) {
+			// array of non primitives not allowed
+			return nil, nil
+		}
+		// A leading "[" marks a JSON array, which allows items containing commas.
+		if strings.HasPrefix(value, "[") {
+			// Creating a new slice using reflect
+			sliceType := reflect.SliceOf(field.Type.Elem())
+			slicePtr := reflect.New(sliceType)
+
+			// Decompressing JSON into a slice
+			err := json.Unmarshal([]byte(value), slicePtr.Interface())
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse default value %q as %s: %w", value, field.Type, err)
+			}
+			return slicePtr.Elem().Interface(), nil
+		}
+		return splitList(elemType, value, walker.IntBase(field.Tag))
+
+	case field.Type.Kind() == reflect.Map:
+		// Creating a map type
+		mapType := reflect.MapOf(field.Type.Key(), field.Type.Elem())
+		mapPtr := reflect.New(mapType)
+
+		err := json.Unmarshal([]byte(value), mapPtr.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse default value %q as %s: %w", value, field.Type, err)
+		}
+		return mapPtr.Elem().Interface(), nil
+
+	case field.Type == timeType:
+		layout := TimeLayout(field.Tag)
+		parsed, err := time.Parse(layout, value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse default value %q as time with layout %q: %w", value, layout, err)
+		}
+		return parsed, nil
+
+	default:
+		// Processing of single values (primitives)
+		return parsePrimitive(field.Type, value, walker.IntBase(field.Tag))
+	}
+}
+
+// splitList splits the comma-separated default of a slice of elemType into
+// its trimmed items, each of which must parse as elemType. Empty items are
+// an error, including the one left by a leading or trailing comma, as in
+// "a,b,": an empty string item is written as a JSON array, e.g. `["a", ""]`.
+func splitList(elemType reflect.Type, value string, base int) ([]string, error) {
+	items := strings.Split(value, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+		if items[i] == "" {
+			return nil, fmt.Errorf("cannot parse default value %q: item %d is empty", value, i)
+		}
+		if _, err := parsePrimitive(elemType, items[i], base); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	return items, nil
+}
+
+// ParseValue parses value as the `default` tag of a field of type t without
+// other tags, after expanding `${VAR}` references, and returns what
+// GetDefaultValues would bind in Viper. Defaults that GetDefaultValues
+// skips, i.e. those of slices of structs, are errors here, as are structs,
+// which take their defaults per field.
+func ParseValue(t reflect.Type, value string) (interface{}, error) {
+	elemType := derefType(t)
+	if elemType.Kind() == reflect.Struct && elemType != timeType && !walker.TextType(elemType) {
+		return nil, fmt.Errorf("%s has no default of its own, its fields have", t)
+	}
+	if err := walker.CheckMapKeys(t); err != nil {
+		return nil, err
+	}
+
+	value = expandEnv(value)
+	parsed, err := parseDefault(reflect.StructField{Type: elemType}, value)
+	if err != nil {
+		return nil, err
+	}
+	if parsed == nil {
+		return nil, fmt.Errorf("cannot parse default value %q as %s", value, t)
+	}
+	return parsed, nil
+}
+
+// CheckDefault returns an error if the `default` tag of a struct field
+// doesn't parse as the field type, the way GetDefaultValues would report it.
+// Fields without a default, structs and slices of structs pass.
+func CheckDefault(field reflect.StructField) error {
+	value := getDefaultValue(field.Tag)
+	elemType := derefType(field.Type)
+	switch {
+	case value == "":
+		return nil
+	case walker.SectionPointer(field.Type):
+		if value != EnabledSection {
+			return fmt.Errorf("invalid default %q for a pointer to a struct, expected %q", value, EnabledSection)
+		}
+		return nil
+	case elemType.Kind() == reflect.Struct && elemType != timeType && !walker.TextType(elemType):
+		return nil
+	}
+	if err := walker.CheckMapKeys(field.Type); err != nil {
+		return err
+	}
+	_, err := parseDefault(field, expandEnv(value))
+	return err
+}
+
+// DefaultValueOf returns the default value of a struct field as a JSON
+// literal, which is also valid YAML: strings, durations, times and []byte
+// are quoted, numbers and bools are bare, slices are arrays and maps are
+// objects. `${VAR}` references are expanded, and a slice default is split by
+// commas unless it is a JSON array.
+//
+// The bool reports whether the field has a `default` tag. Without one the
+// zero value of the type is returned: "", 0, false, "0s", [], {} or null for
+// pointers, structs, times and []byte. A default that can't be parsed as the
+// field type is returned as a quoted string.
+func DefaultValueOf(field reflect.StructField) (string, bool) {
+	raw := expandEnv(getDefaultValue(field.Tag))
+	if raw == "" {
+		return zeroLiteral(field.Type), false
+	}
+
+	// A pointer field takes the default of the pointed-to type.
+	field.Type = derefType(field.Type)
+
+	// Times, durations, []byte and text types keep their text form, e.g. a
+	// base64 string or a URL.
+	if field.Type == timeType || field.Type == durationType || field.Type == bytesType || walker.TextType(field.Type) {
+		return marshalLiteral(raw), true
+	}
+
+	value, err := parseDefault(field, raw)
+	if err != nil || value == nil {
+		return marshalLiteral(raw), true
+	}
+	return marshalLiteral(value), true
+}
+
+// IsZeroDefault reports whether the field has no default or a default equal
+// to the zero value of its type, e.g. `default:"false"`, `default:"0"` or
+// `default:"0s"`. A pointer field is compared with the zero value of the
+// pointed-to type.
+func IsZeroDefault(field reflect.StructField) bool {
+	literal, ok := DefaultValueOf(field)
+	return !ok || literal == zeroLiteral(derefType(field.Type))
+}
+
+// zeroLiteral returns the JSON literal of the zero value of a field type.
+func zeroLiteral(t reflect.Type) string {
+	if t == durationType {
+		return `"0s"`
+	}
+	if walker.TextType(t) {
+		return "null"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return `""`
+	case reflect.Bool:
+		return "false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "0"
+	case reflect.Slice, reflect.Array:
+		if t == bytesType {
+			return "null"
+		}
+		return "[]"
+	case reflect.Map:
+		return "{}"
+	default:
+		return "null"
+	}
+}
+
+// marshalLiteral renders a value as a JSON literal without escaping HTML
+// characters.
+func marshalLiteral(value interface{}) string {
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "null"
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// derefType unwraps pointer types.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// checkText reports whether value is a well-formed string form of the text
+// type t: a URL, an IP address, a CIDR network, or a value accepted by the
+// Parse hook of a registered type.
+func checkText(t reflect.Type, value string) error {
+	var err error
+	hooks, registered := walker.CustomType(t)
+	switch {
+	case registered:
+		_, err = hooks.Parse(value)
+	case t == reflect.TypeOf(url.URL{}):
+		_, err = url.Parse(value)
+	case t == reflect.TypeOf(net.IP{}):
+		if net.ParseIP(value) == nil {
+			err = fmt.Errorf("invalid IP address")
+		}
+	case t == reflect.TypeOf(net.IPNet{}):
+		_, _, err = net.ParseCIDR(value)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot parse default value %q as %s: %w", value, t, err)
+	}
+	return nil
+}
+
+// TimeLayout returns the layout time.Time values of a field are written in:
+// the `timeformat` tag, e.g. "2006-01-02", or time.RFC3339 by default.
+func TimeLayout(tag reflect.StructTag) string {
+	if layout := tag.Get("timeformat"); layout != "" {
+		return layout
+	}
+	return time.RFC3339
+}
+
+// parsePrimitive parses the default value of a single primitive field using
+// the exact kind and bit size of its type. Numbers that are malformed or don't
+// fit the type and malformed booleans are an error. Signed integers are
+// returned as int64, unsigned ones as uint64 and floats as float64.
+func parsePrimitive(t reflect.Type, value string, base int) (interface{}, error) {
+	// time.Duration is an int64, but its defaults are written like "30s".
+	if t == durationType {
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("cannot parse default value %q as %s: %w", value, t, err)
+		}
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intValue, err := walker.ParseInt(value, base, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse default value %q as %s: %w", value, t, err)
+		}
+		return intValue, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintValue, err := walker.ParseUint(value, base, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse default value %q as %s: %w", value, t, err)
+		}
+		return uintValue, nil
+	case reflect.Float32, reflect.Float64:
+		floatValue, err := strconv.ParseFloat(value, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse default value %q as %s: %w", value, t, err)
+		}
+		return floatValue, nil
+	case reflect.Bool:
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse default value %q as %s: %w", value, t, err)
+		}
+		return boolValue, nil
+	default:
+		return value, nil
 	}
-	return msVal
+}
+
+// isIgnored reports whether the field is not decoded at all.
+func isIgnored(field reflect.StructField) bool {
+	return field.Tag.Get("mapstructure") == "-"
 }
 
 // getDefaultValue extracts the default value from struct tags.
@@ -157,6 +457,38 @@ func getDefaultValue(tag reflect.StructTag) string {
 	return defaultVal
 }
 
+// expandEnv replaces ${VAR} references with the values of the environment
+// variables and "$$" with a literal "$". Any other "$" is kept as is.
+func expandEnv(value string) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 >= len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+		switch value[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				sb.WriteByte(value[i])
+				continue
+			}
+			sb.WriteString(os.Getenv(value[i+2 : i+2+end]))
+			i += end + 2
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
+
 func isPrimitive(kind reflect.Kind) bool {
 	switch kind {
 	case reflect.Bool,
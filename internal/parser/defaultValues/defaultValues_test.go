@@ -1,7 +1,12 @@
 package defaultValues
 
 import (
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -39,6 +44,17 @@ func TestGetDefaultValues_InvalidStruct(t *testing.T) {
 	assert.Error(t, err, "expected an error for non-struct input")
 }
 
+func TestGetDefaultValues_UnsupportedMapKey(t *testing.T) {
+	type key struct{ ID int }
+	type config struct {
+		Priorities map[int]string `mapstructure:"priorities" default:"{\"1\":\"high\"}"`
+		Index      map[key]string `mapstructure:"index"`
+	}
+
+	_, err := GetDefaultValues(config{})
+	assert.ErrorContains(t, err, "index: unsupported map key type defaultValues.key")
+}
+
 func TestGetDefaultValues_NestedStruct(t *testing.T) {
 	type Nested struct {
 		Timeout int `mapstructure:"timeout" default:"60"`
@@ -72,3 +88,528 @@ func TestGetDefaultValues_NoDefaults(t *testing.T) {
 
 	assert.Len(t, defaults, 0, "no defaults should be added if not specified")
 }
+
+func TestGetDefaultValues_EmbeddedStruct(t *testing.T) {
+	type Base struct {
+		Name string `mapstructure:"name" default:"app"`
+	}
+	type Config struct {
+		Base
+		Port int `mapstructure:"port" default:"8080"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+
+	expected := []DefaultInfo{
+		{BindKey: "name", DefaultValue: "app"},
+		{BindKey: "port", DefaultValue: int64(8080)},
+	}
+
+	assert.EqualValues(t, expected, defaults)
+}
+
+func TestGetDefaultValues_MixedFields(t *testing.T) {
+	type Common struct {
+		Name string `mapstructure:"name" default:"app"`
+	}
+	type Config struct {
+		Common  Common `mapstructure:",squash"`
+		Port    int    `mapstructure:"port,omitempty" default:"8080"`
+		hidden  string `default:"x"`
+		Ignored string `mapstructure:"-" default:"y"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+
+	expected := []DefaultInfo{
+		{BindKey: "name", DefaultValue: "app"},
+		{BindKey: "port", DefaultValue: int64(8080)},
+	}
+
+	assert.EqualValues(t, expected, defaults)
+}
+
+func TestGetDefaultValues_SliceOfPointers(t *testing.T) {
+	type Config struct {
+		Ports []*int    `mapstructure:"ports" default:"[80, 443]"`
+		Hosts []*string `mapstructure:"hosts" default:"a,b"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+
+	require.Len(t, defaults, 2)
+	ports, ok := defaults[0].DefaultValue.([]*int)
+	require.True(t, ok)
+	require.Len(t, ports, 2)
+	assert.Equal(t, 80, *ports[0])
+	assert.Equal(t, 443, *ports[1])
+	assert.Equal(t, []string{"a", "b"}, defaults[1].DefaultValue)
+}
+
+func TestGetDefaultValues_SliceForms(t *testing.T) {
+	type Config struct {
+		Queries []string `mapstructure:"queries" default:"[\"a,b\", \"c\"]"`
+		Ports   []int    `mapstructure:"ports" default:"[80, 443]"`
+		Tags    []string `mapstructure:"tags" default:"x, y"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+
+	expected := []DefaultInfo{
+		{BindKey: "queries", DefaultValue: []string{"a,b", "c"}},
+		{BindKey: "ports", DefaultValue: []int{80, 443}},
+		{BindKey: "tags", DefaultValue: []string{"x", "y"}},
+	}
+
+	assert.EqualValues(t, expected, defaults)
+}
+
+func TestGetDefaultValues_EnvExpansion(t *testing.T) {
+	t.Setenv("CONFIGO_TEST_HOME", "/home/user")
+	type Config struct {
+		DataDir  string   `mapstructure:"data_dir" default:"${CONFIGO_TEST_HOME}/app"`
+		Price    string   `mapstructure:"price" default:"$$5 or $5"`
+		Missing  string   `mapstructure:"missing" default:"${CONFIGO_TEST_UNSET}"`
+		Unclosed string   `mapstructure:"unclosed" default:"${HOME"`
+		Paths    []string `mapstructure:"paths" default:"${CONFIGO_TEST_HOME}/a,/b"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+
+	expected := []DefaultInfo{
+		{BindKey: "data_dir", DefaultValue: "/home/user/app"},
+		{BindKey: "price", DefaultValue: "$5 or $5"},
+		{BindKey: "unclosed", DefaultValue: "${HOME"},
+		{BindKey: "paths", DefaultValue: []string{"/home/user/a", "/b"}},
+	}
+
+	assert.EqualValues(t, expected, defaults)
+}
+
+func TestGetDefaultValues_NumericKinds(t *testing.T) {
+	type Config struct {
+		Int8    int8          `mapstructure:"int8" default:"-128"`
+		Int16   int16         `mapstructure:"int16" default:"32767"`
+		Int64   int64         `mapstructure:"int64" default:"-9223372036854775808"`
+		Uint    uint          `mapstructure:"uint" default:"42"`
+		Uint16  uint16        `mapstructure:"uint16" default:"65535"`
+		Uint64  uint64        `mapstructure:"uint64" default:"18446744073709551615"`
+		Float32 float32       `mapstructure:"float32" default:"0.1"`
+		Float64 float64       `mapstructure:"float64" default:"1e6"`
+		Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+
+	expected := []DefaultInfo{
+		{BindKey: "int8", DefaultValue: int64(-128)},
+		{BindKey: "int16", DefaultValue: int64(32767)},
+		{BindKey: "int64", DefaultValue: int64(-9223372036854775808)},
+		{BindKey: "uint", DefaultValue: uint64(42)},
+		{BindKey: "uint16", DefaultValue: uint64(65535)},
+		{BindKey: "uint64", DefaultValue: uint64(18446744073709551615)},
+		{BindKey: "float32", DefaultValue: float64(float32(0.1))},
+		{BindKey: "float64", DefaultValue: 1e6},
+		{BindKey: "timeout", DefaultValue: "30s"},
+	}
+
+	assert.EqualValues(t, expected, defaults)
+}
+
+func TestGetDefaultValues_NumericOverflow(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  interface{}
+	}{
+		{"int8", struct {
+			V int8 `mapstructure:"v" default:"128"`
+		}{}},
+		{"uint16", struct {
+			V uint16 `mapstructure:"v" default:"65536"`
+		}{}},
+		{"negative uint", struct {
+			V uint `mapstructure:"v" default:"-1"`
+		}{}},
+		{"float32", struct {
+			V float32 `mapstructure:"v" default:"1e39"`
+		}{}},
+		{"malformed int", struct {
+			V int `mapstructure:"v" default:"8080x"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetDefaultValues(tt.cfg)
+			assert.ErrorContains(t, err, "v: cannot parse default value")
+		})
+	}
+}
+
+func TestGetDefaultValues_Time(t *testing.T) {
+	type Config struct {
+		ValidFrom time.Time `mapstructure:"valid_from" default:"2024-01-01T00:00:00Z"`
+		Day       time.Time `mapstructure:"day" default:"2024-03-05" timeformat:"2006-01-02"`
+		Until     time.Time `mapstructure:"until"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []DefaultInfo{
+		{BindKey: "valid_from", DefaultValue: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{BindKey: "day", DefaultValue: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+	}, defaults)
+
+	_, err = GetDefaultValues(struct {
+		Day time.Time `mapstructure:"day" default:"05.03.2024"`
+	}{})
+	assert.ErrorContains(t, err, "day: cannot parse default value")
+}
+
+func TestGetDefaultValues_Bytes(t *testing.T) {
+	type Config struct {
+		Key []byte `mapstructure:"key" default:"aGVsbG8=" encoding:"base64"`
+		Raw []byte `mapstructure:"raw" default:"hello,world"`
+		Nil []byte `mapstructure:"nil"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []DefaultInfo{
+		{BindKey: "key", DefaultValue: []byte("hello")},
+		{BindKey: "raw", DefaultValue: []byte("hello,world")},
+	}, defaults)
+
+	_, err = GetDefaultValues(struct {
+		Key []byte `mapstructure:"key" default:"not base64!" encoding:"base64"`
+	}{})
+	assert.ErrorContains(t, err, "key: cannot decode default value")
+}
+
+func TestDefaultValueOf(t *testing.T) {
+	t.Setenv("DEFAULT_VALUE_HOST", "db.local")
+
+	type Config struct {
+		Name     string            `default:"my <app>"`
+		Host     string            `default:"${DEFAULT_VALUE_HOST}"`
+		Port     int               `default:"8080"`
+		Ratio    float64           `default:"0.5"`
+		Enabled  bool              `default:"true"`
+		Timeout  time.Duration     `default:"30s"`
+		Since    time.Time         `default:"2024-03-05" timeformat:"2006-01-02"`
+		Key      []byte            `default:"aGVsbG8=" encoding:"base64"`
+		Tags     []string          `default:"a, b"`
+		Items    []string          `default:"[\"a,b\", \"c\"]"`
+		Ports    []int             `default:"[80, 443]"`
+		Labels   map[string]string `default:"{\"env\":\"prod\"}"`
+		Limit    *int              `default:"10"`
+		Invalid  int               `default:"many"`
+		Empty    string
+		Zero     int
+		Off      bool
+		Interval time.Duration
+		List     []string
+		Map      map[string]int
+		Pointer  *int
+		Raw      []byte
+	}
+
+	tests := []struct {
+		field    string
+		expected string
+		set      bool
+	}{
+		{"Name", `"my <app>"`, true},
+		{"Host", `"db.local"`, true},
+		{"Port", "8080", true},
+		{"Ratio", "0.5", true},
+		{"Enabled", "true", true},
+		{"Timeout", `"30s"`, true},
+		{"Since", `"2024-03-05"`, true},
+		{"Key", `"aGVsbG8="`, true},
+		{"Tags", `["a","b"]`, true},
+		{"Items", `["a,b","c"]`, true},
+		{"Ports", "[80,443]", true},
+		{"Labels", `{"env":"prod"}`, true},
+		{"Limit", "10", true},
+		{"Invalid", `"many"`, true},
+		{"Empty", `""`, false},
+		{"Zero", "0", false},
+		{"Off", "false", false},
+		{"Interval", `"0s"`, false},
+		{"List", "[]", false},
+		{"Map", "{}", false},
+		{"Pointer", "null", false},
+		{"Raw", "null", false},
+	}
+
+	typ := reflect.TypeOf(Config{})
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			field, ok := typ.FieldByName(tt.field)
+			require.True(t, ok)
+
+			value, set := DefaultValueOf(field)
+			assert.Equal(t, tt.expected, value)
+			assert.Equal(t, tt.set, set)
+		})
+	}
+}
+
+func TestGetDefaultValues_TextTypes(t *testing.T) {
+	type Config struct {
+		BaseURL url.URL    `mapstructure:"base_url" default:"https://example.com/api"`
+		BindIP  net.IP     `mapstructure:"bind_ip" default:"0.0.0.0"`
+		Allowed *net.IPNet `mapstructure:"allowed_cidr" default:"10.0.0.0/8"`
+		NoValue url.URL    `mapstructure:"no_value"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []DefaultInfo{
+		{BindKey: "base_url", DefaultValue: "https://example.com/api"},
+		{BindKey: "bind_ip", DefaultValue: "0.0.0.0"},
+		{BindKey: "allowed_cidr", DefaultValue: "10.0.0.0/8"},
+	}, defaults)
+
+	_, err = GetDefaultValues(struct {
+		BindIP net.IP `mapstructure:"bind_ip" default:"localhost"`
+	}{})
+	assert.ErrorContains(t, err, "bind_ip: cannot parse default value")
+
+	_, err = GetDefaultValues(struct {
+		Allowed net.IPNet `mapstructure:"allowed_cidr" default:"10.0.0.1"`
+	}{})
+	assert.ErrorContains(t, err, "allowed_cidr: cannot parse default value")
+}
+
+func TestIsZeroDefault(t *testing.T) {
+	typ := reflect.TypeOf(struct {
+		None     string        `default:""`
+		False    bool          `default:"false"`
+		True     bool          `default:"true"`
+		Zero     int           `default:"0"`
+		Duration time.Duration `default:"0s"`
+		Ptr      *int          `default:"0"`
+		Tags     []string      `default:"[]"`
+		Name     string        `default:"app"`
+	}{})
+
+	expected := map[string]bool{
+		"None": true, "False": true, "True": false, "Zero": true,
+		"Duration": true, "Ptr": true, "Tags": true, "Name": false,
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		assert.Equal(t, expected[field.Name], IsZeroDefault(field), field.Name)
+	}
+}
+
+func TestGetDefaultValues_PointerSections(t *testing.T) {
+	type Inner struct {
+		Level int `mapstructure:"level" default:"1"`
+	}
+	type TLS struct {
+		Cert   string `mapstructure:"cert" default:"cert.pem"`
+		Inner  *Inner `mapstructure:"inner"`
+		Always *Inner `mapstructure:"always" default:"enabled"`
+	}
+	type Config struct {
+		TLS    *TLS `mapstructure:"tls"`
+		Backup *TLS `mapstructure:"backup" default:"enabled"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []DefaultInfo{
+		{BindKey: "tls.cert", DefaultValue: "cert.pem", Section: "tls"},
+		{BindKey: "tls.inner.level", DefaultValue: int64(1), Section: "tls.inner"},
+		{BindKey: "tls.always.level", DefaultValue: int64(1), Section: "tls"},
+		{BindKey: "backup.cert", DefaultValue: "cert.pem"},
+		{BindKey: "backup.inner.level", DefaultValue: int64(1), Section: "backup.inner"},
+		{BindKey: "backup.always.level", DefaultValue: int64(1)},
+	}, defaults)
+
+	_, err = GetDefaultValues(struct {
+		TLS *TLS `mapstructure:"tls" default:"yes"`
+	}{})
+	assert.EqualError(t, err, `tls: invalid default "yes" for a pointer to a struct, expected "enabled"`)
+}
+
+func TestParseValue(t *testing.T) {
+	value, err := ParseValue(reflect.TypeOf([]int{}), "[1,2,3]")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, value)
+
+	value, err = ParseValue(reflect.TypeOf(new(uint8)), "255")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(255), value)
+
+	t.Setenv("PARSE_VALUE_HOST", "example.com")
+	value, err = ParseValue(reflect.TypeOf(""), "${PARSE_VALUE_HOST}")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", value)
+
+	for _, tt := range []struct {
+		t     reflect.Type
+		value string
+	}{
+		{reflect.TypeOf(true), "maybe"},
+		{reflect.TypeOf(uint8(0)), "256"},
+		{reflect.TypeOf([]int{}), "[1,"},
+		{reflect.TypeOf(map[string]int{}), "{"},
+		{reflect.TypeOf([]struct{ A int }{}), "[]"},
+		{reflect.TypeOf(struct{ A int }{}), "x"},
+		{reflect.TypeOf(map[struct{}]int{}), "{}"},
+	} {
+		_, err := ParseValue(tt.t, tt.value)
+		assert.Error(t, err, "%s %q", tt.t, tt.value)
+	}
+}
+
+func TestGetDefaultValues_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  interface{}
+		err  string
+	}{
+		{"bool", struct {
+			V bool `mapstructure:"v" default:"maybe"`
+		}{}, `v: cannot parse default value "maybe" as bool`},
+		{"trailing comma", struct {
+			V []string `mapstructure:"v" default:"1,2,"`
+		}{}, `v: cannot parse default value "1,2,": item 2 is empty`},
+		{"leading comma", struct {
+			V []string `mapstructure:"v" default:",a"`
+		}{}, `v: cannot parse default value ",a": item 0 is empty`},
+		{"empty item", struct {
+			V []int `mapstructure:"v" default:"1, ,2"`
+		}{}, `v: cannot parse default value "1, ,2": item 1 is empty`},
+		{"non-numeric item", struct {
+			V []int `mapstructure:"v" default:"1,x"`
+		}{}, `v: item 1: cannot parse default value "x" as int`},
+		{"bool item", struct {
+			V []bool `mapstructure:"v" default:"true,yes"`
+		}{}, `v: item 1: cannot parse default value "yes" as bool`},
+		{"unterminated array", struct {
+			V []int `mapstructure:"v" default:"[unterminated"`
+		}{}, `v: cannot parse default value "[unterminated" as []int`},
+		{"array of the wrong type", struct {
+			V []int `mapstructure:"v" default:"[\"a\"]"`
+		}{}, `v: cannot parse default value "[\"a\"]" as []int`},
+		{"duration", struct {
+			V time.Duration `mapstructure:"v" default:"many"`
+		}{}, `v: cannot parse default value "many" as time.Duration`},
+		{"duration item", struct {
+			V []time.Duration `mapstructure:"v" default:"1s,2"`
+		}{}, `v: item 1: cannot parse default value "2" as time.Duration`},
+		{"malformed map", struct {
+			V map[string]int `mapstructure:"v" default:"{\"a\":"`
+		}{}, `v: cannot parse default value "{\"a\":" as map[string]int`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetDefaultValues(tt.cfg)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestGetDefaultValues_EmptyItemsAsJSON(t *testing.T) {
+	type Config struct {
+		Items []string `mapstructure:"items" default:"[\"a\", \"\"]"`
+		Hex   []int    `mapstructure:"hex" default:"0x10, 0x20" format:"hex"`
+	}
+
+	defaults, err := GetDefaultValues(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []DefaultInfo{
+		{BindKey: "items", DefaultValue: []string{"a", ""}},
+		{BindKey: "hex", DefaultValue: []string{"0x10", "0x20"}},
+	}, defaults)
+}
+
+func TestCheckDefault(t *testing.T) {
+	type Section struct {
+		Port int `default:"80"`
+	}
+	type Config struct {
+		Port    int      `default:"8080"`
+		Bad     int      `default:"many"`
+		Tags    []string `default:"a,b,"`
+		None    bool
+		Nested  Section   `default:"ignored"`
+		Items   []Section `default:"[]"`
+		Enabled *Section  `default:"enabled"`
+		Section *Section  `default:"yes"`
+	}
+
+	typ := reflect.TypeOf(Config{})
+	for name, ok := range map[string]bool{
+		"Port": true, "Bad": false, "Tags": false, "None": true,
+		"Nested": true, "Items": true, "Enabled": true, "Section": false,
+	} {
+		field, found := typ.FieldByName(name)
+		require.True(t, found)
+		err := CheckDefault(field)
+		assert.Equal(t, ok, err == nil, "%s: %v", name, err)
+	}
+}
+
+// FuzzParseValue checks that malformed defaults are reported as errors,
+// never as a panic or a nil value, and that list defaults come back without
+// empty items.
+func FuzzParseValue(f *testing.F) {
+	types := []reflect.Type{
+		reflect.TypeOf(""),
+		reflect.TypeOf(0),
+		reflect.TypeOf(int8(0)),
+		reflect.TypeOf(uint16(0)),
+		reflect.TypeOf(0.0),
+		reflect.TypeOf(false),
+		reflect.TypeOf(time.Duration(0)),
+		reflect.TypeOf(time.Time{}),
+		reflect.TypeOf([]byte(nil)),
+		reflect.TypeOf([]string{}),
+		reflect.TypeOf([]int{}),
+		reflect.TypeOf([]*bool{}),
+		reflect.TypeOf(map[string]int{}),
+		reflect.TypeOf(map[int]string{}),
+		reflect.TypeOf(map[bool][]string{}),
+		reflect.TypeOf(net.IP{}),
+		reflect.TypeOf(url.URL{}),
+		reflect.TypeOf(new(int)),
+	}
+	for _, value := range []string{"", "1", "1,2,", "[unterminated", "many", "true", "a, ,b", "[1, 2]",
+		`{"a": 1}`, "0x1F", "-0o17", "1e400", "${HOME}", "$${X}", "2024-01-02T00:00:00Z", "[\"a\", \"\"]",
+		"30s", "1h30m", "-1.5ms"} {
+		for i := range types {
+			f.Add(uint8(i), value)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, index uint8, value string) {
+		typ := types[int(index)%len(types)]
+		parsed, err := ParseValue(typ, value)
+		if err != nil {
+			return
+		}
+		if parsed == nil {
+			t.Fatalf("ParseValue(%s, %q) returned nil without an error", typ, value)
+		}
+		if items, ok := parsed.([]string); ok && typ.Kind() == reflect.Slice && !strings.HasPrefix(value, "[") {
+			for i, item := range items {
+				if item == "" {
+					t.Fatalf("ParseValue(%s, %q) returned empty item %d", typ, value, i)
+				}
+			}
+		}
+	})
+}
@@ -2,34 +2,96 @@ package env
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/vsysa/configo/internal/parser/walker"
 )
 
+// timeType is used to detect time.Time fields, which are structs but are
+// configured as a single RFC3339 string.
+var timeType = reflect.TypeOf(time.Time{})
+
 // EnvInfo holds information needed to document an environment variable:
 //   - EnvVar:       the name of the environment variable.
 //   - DefaultValue: the default value (if any).
 //   - HelpText:     description/help for the variable.
+//   - Fixed:        the name comes from an `env` tag with the "fixed" option,
+//     e.g. `env:"DATABASE_URL,fixed"`, and must be used as is, without any
+//     prefix.
+//   - Secret:       the field, or a section holding it, is tagged with
+//     `secret:"true"`. Its DefaultValue is always empty.
 type EnvInfo struct {
 	EnvVar       string
 	DefaultValue string
 	HelpText     string
 	BindKey      string
 	ValueType    string
+	Fixed        bool
+	Secret       bool
+}
+
+// Naming controls how the environment variable names are derived.
+type Naming struct {
+	// Prefix is prepended to every derived name, e.g. "MYAPP". Fixed names
+	// from an `env:"NAME,fixed"` tag on a leaf field are never prefixed.
+	Prefix string
+	// Separator joins the prefix and the names of nested fields, e.g. "__"
+	// for MYAPP__META__VERSION. Empty means "_".
+	Separator string
+	// KeepCase keeps the names as they are written in the tags (or the Go
+	// field names) instead of uppercasing them.
+	KeepCase bool
+	// InElement derives the names of the fields of a slice element or map
+	// value, whose Prefix holds the index or key. A fixed `env` tag of a
+	// leaf field is then joined to the prefix like any other name, since a
+	// fixed name would be the same for every element.
+	InElement bool
+}
+
+// separator returns the separator of nested names.
+func (n Naming) separator() string {
+	if n.Separator == "" {
+		return "_"
+	}
+	return n.Separator
+}
+
+// applyCase uppercases name unless KeepCase is set.
+func (n Naming) applyCase(name string) string {
+	if n.KeepCase {
+		return name
+	}
+	return strings.ToUpper(name)
 }
 
 func GetEnvs(cfg interface{}) []EnvInfo {
+	return GetEnvsWithNaming(cfg, Naming{})
+}
+
+// GetEnvsWithNaming works like GetEnvs, but derives the variable names with
+// the given naming rules.
+func GetEnvsWithNaming(cfg interface{}, naming Naming) []EnvInfo {
 	var lines []EnvInfo
-	parseEnvStructure(reflect.TypeOf(cfg), "", "", &lines)
+	parseEnvStructure(reflect.TypeOf(cfg), naming.Prefix, "", false, naming, walker.Expanding{}, &lines)
 	return lines
 }
 
 // parseEnvStructure recursively scans the given type (and nested structs, if any),
 // collecting environment variable information according to the specified rules.
 // parentPrefix will be prepended to child env tags if the parent has an env tag.
-// For instance, if the parent struct has env:"db" and the nested field is env:"host",
-// the final environment variable becomes "DB_HOST".
-func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, lines *[]EnvInfo) {
+// For instance, if the parent struct has env:"db" and the nested field is mapstructure:"host",
+// the final environment variable becomes "DB_HOST", and so it does for env:"host". An env
+// tag with the "fixed" option on a non-struct field is a fixed name:
+// env:"DATABASE_URL,fixed" stays "DATABASE_URL" at any depth.
+// The names are joined and cased according to naming. Pointers to structs
+// are expanded like nested structs, unless they refer back to a struct of
+// expanding, the structs on the current path. The fields of secret sections
+// are secret themselves.
+func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, inSecret bool, naming Naming, expanding walker.Expanding, lines *[]EnvInfo) {
 	// If the type is a pointer, unwrap it to its element type.
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -37,28 +99,22 @@ func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, li
 	if t.Kind() != reflect.Struct {
 		return
 	}
+	expanding[t] = true
+	defer delete(expanding, t)
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-
-		// Skip unexported fields
-		if field.PkgPath != "" {
-			continue
-		}
-
-		// Determine if the field is allowed to have an env
-		envName, envAllowed := getEnvName(field)
-		if !envAllowed {
-			continue
-		}
+	// Fields without an env are skipped, embedded structs without an
+	// explicit name are flattened like in templates.
+	for _, f := range walker.FieldsFunc(t, nil, withoutEnv) {
+		field := f.StructField
+		envName, _ := getEnvName(field)
 
-		msKey := getMapstructureKey(field)
+		msKey := f.BindKey
 
 		// Build the full environment variable name
-		// parentEnvPrefix + "_" + envNamePart (if both are non-empty)
+		// parentEnvPrefix + separator + envNamePart (if both are non-empty)
 		childEnvName := parentEnvPrefix
 		if childEnvName != "" && envName != "" {
-			childEnvName += "_" + envName
+			childEnvName += naming.separator() + envName
 		} else if envName != "" {
 			childEnvName = envName
 		}
@@ -74,37 +130,59 @@ func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, li
 
 		// Check the field kind to handle nested structs, slices, maps, etc.
 		fieldKind := field.Type.Kind()
+		secret := inSecret || field.Tag.Get("secret") == "true"
 
 		// Recurse deeper if it's a struct (and not a map or slice).
 		// We assume *non*-map, non-slice struct fields can have nested env variables.
-		if fieldKind == reflect.Struct {
+		if fieldKind == reflect.Struct && field.Type != timeType && !walker.TextType(field.Type) {
 			// Recurse into nested struct.
-			parseEnvStructure(field.Type, childEnvName, childBindKey, lines)
+			parseEnvStructure(field.Type, childEnvName, childBindKey, secret, naming, expanding, lines)
+			continue
+		}
+		if walker.SectionPointer(field.Type) {
+			if !expanding.Recursive(field.Type) {
+				parseEnvStructure(field.Type, childEnvName, childBindKey, secret, naming, expanding, lines)
+			}
 			continue
 		}
 
 		// Prepare the EnvInfo record.
 		info := EnvInfo{
-			EnvVar:    strings.ToUpper(childEnvName),
+			EnvVar:    naming.applyCase(childEnvName),
 			BindKey:   childBindKey,
-			HelpText:  getHelpText(field.Tag),
+			HelpText:  walker.HelpWithUnit(getHelpText(field.Tag), field.Tag.Get("unit")),
 			ValueType: field.Type.String(), // e.g. "int", "[]string", "map[string]int"
+			Secret:    secret,
+		}
+
+		// A fixed env tag on a leaf field overrides the derived name.
+		if fixed := FixedName(field.Tag); fixed != "" && !naming.InElement {
+			info.EnvVar = naming.applyCase(fixed)
+			info.Fixed = true
 		}
 
 		// Figure out the default value. If none is provided, handle special cases for map/slice.
 		defaultValStr := getDefaultValue(field.Tag)
 
+		// Text types like net.IP are set from their string form, as strings.
+		if walker.TextType(field.Type) {
+			fieldKind = reflect.String
+		}
+
 		switch fieldKind {
 
 		// ======================= SLICE CASE =======================
 		case reflect.Slice:
 			if defaultValStr == "" {
 				// If no default, produce a "zero" JSON.
-				elemKind := field.Type.Elem().Kind()
-				if elemKind == reflect.Struct {
+				elemType := field.Type.Elem()
+				for elemType.Kind() == reflect.Ptr {
+					elemType = elemType.Elem()
+				}
+				if elemType.Kind() == reflect.Struct {
 					// e.g. `[ {} ]`
 					// Create a zero-value element, then put it into an array of length 1.
-					zeroElem := reflect.Zero(field.Type.Elem()).Interface()
+					zeroElem := reflect.Zero(elemType).Interface()
 					oneElemArray := []interface{}{zeroElem}
 					jsonBytes, _ := json.Marshal(oneElemArray)
 					defaultValStr = string(jsonBytes)
@@ -120,8 +198,9 @@ func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, li
 		// ======================= MAP CASE ==========================
 		case reflect.Map:
 			if defaultValStr == "" {
-				// No default => produce `{"key":"value"}`
-				defaultValStr = `{"key":"value"}`
+				// No default => produce `{"key":"value"}`, or `{"1":"value"}`
+				// for integer keys.
+				defaultValStr = fmt.Sprintf(`{%q:"value"}`, walker.MapKeyExample(field.Type.Key()))
 			}
 			info.DefaultValue = defaultValStr
 
@@ -131,6 +210,11 @@ func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, li
 			info.DefaultValue = defaultValStr
 		}
 
+		// Secrets never echo their defaults, nor a placeholder for them.
+		if secret {
+			info.DefaultValue = ""
+		}
+
 		*lines = append(*lines, info)
 	}
 }
@@ -138,14 +222,14 @@ func parseEnvStructure(t reflect.Type, parentEnvPrefix, parentBindKey string, li
 // getEnvName determines how to name the environment variable.
 // Priority:
 // 1. env:"..." tag (excluding "-")
-// 2. mapstructure:"..." tag => uppercase
-// 3. field name => uppercase
+// 2. mapstructure:"..." tag
+// 3. field name
+//
+// The name is returned as written, without tag options such as ",fixed"; it
+// is uppercased with the whole variable name.
 func getEnvName(field reflect.StructField) (envName string, isAllowEnv bool) {
-	defer func() {
-		envName = strings.ToUpper(envName)
-	}()
 	// 1) Check `env` tag
-	envName = field.Tag.Get("env")
+	envName = strings.Split(field.Tag.Get("env"), ",")[0]
 
 	if envName == "-" {
 		return "", false
@@ -154,8 +238,8 @@ func getEnvName(field reflect.StructField) (envName string, isAllowEnv bool) {
 		return envName, true
 	}
 
-	// 2) Fallback to mapstructure in uppercase
-	msName := field.Tag.Get("mapstructure")
+	// 2) Fallback to mapstructure
+	msName := getMapstructureName(field.Tag)
 	if msName == "-" {
 		return "", false
 	}
@@ -166,16 +250,28 @@ func getEnvName(field reflect.StructField) (envName string, isAllowEnv bool) {
 	return field.Name, true
 }
 
-// getMapstructureKey returns the part of the key used for Viper bind keys
-// based on mapstructure or the field name, but does not uppercase it.
-// We want something like `db` or `host`, so the final key might be `db.host`.
-func getMapstructureKey(field reflect.StructField) string {
-	msVal := field.Tag.Get("mapstructure")
-	if msVal == "" {
-		// fallback to the lowercase field name
-		return strings.ToLower(field.Name)
+// withoutEnv reports whether the field is not allowed to have an env, see
+// getEnvName.
+func withoutEnv(field reflect.StructField) bool {
+	_, isAllowEnv := getEnvName(field)
+	return !isAllowEnv
+}
+
+// FixedName returns the name of an `env` tag with the "fixed" option, e.g.
+// "DATABASE_URL" for `env:"DATABASE_URL,fixed"`, as written. It returns an
+// empty string for other tags, whose name is a part of the derived name.
+func FixedName(tag reflect.StructTag) string {
+	parts := strings.Split(tag.Get("env"), ",")
+	if parts[0] == "-" || !slices.Contains(parts[1:], "fixed") {
+		return ""
 	}
-	return msVal
+	return parts[0]
+}
+
+// getMapstructureName returns the name part of the mapstructure tag,
+// without options such as ",squash" or ",omitempty".
+func getMapstructureName(tag reflect.StructTag) string {
+	return strings.Split(tag.Get("mapstructure"), ",")[0]
 }
 
 // getDefaultValue extracts the default value from struct tags.
@@ -3,9 +3,11 @@ package env
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vsysa/configo/internal/parser/walker"
 )
 
 func TestParseEnvStructure_Simple(t *testing.T) {
@@ -18,7 +20,7 @@ func TestParseEnvStructure_Simple(t *testing.T) {
 	}
 
 	var lines []EnvInfo
-	parseEnvStructure(reflect.TypeOf(simpleConfig{}), "", "", &lines)
+	parseEnvStructure(reflect.TypeOf(simpleConfig{}), "", "", false, Naming{}, walker.Expanding{}, &lines)
 
 	if len(lines) != 3 {
 		t.Errorf("expected 3 lines, got %d", len(lines))
@@ -172,3 +174,177 @@ func TestGetEnvs_InvalidType(t *testing.T) {
 	envs := GetEnvs(invalidCfg)
 	assert.Len(t, envs, 0, "No env variables should be parsed from non-struct types")
 }
+
+func TestGetEnvs_EmbeddedStruct(t *testing.T) {
+	type Base struct {
+		Name string `mapstructure:"name"`
+	}
+	type Config struct {
+		Base
+		Tagged Base `mapstructure:"tagged"`
+	}
+
+	envs := GetEnvs(Config{})
+
+	expected := []EnvInfo{
+		{EnvVar: "NAME", BindKey: "name", ValueType: "string"},
+		{EnvVar: "TAGGED_NAME", BindKey: "tagged.name", ValueType: "string"},
+	}
+
+	assert.EqualValues(t, expected, envs)
+}
+
+func TestGetEnvs_MixedFields(t *testing.T) {
+	type Common struct {
+		Name string `mapstructure:"name"`
+	}
+	type Config struct {
+		Common  Common `mapstructure:",squash"`
+		Port    int    `mapstructure:"port"`
+		hidden  string `mapstructure:"hidden"`
+		Ignored string `mapstructure:"-"`
+		NoEnv   string `mapstructure:"no_env" env:"-"`
+	}
+
+	envs := GetEnvs(Config{})
+
+	expected := []EnvInfo{
+		{EnvVar: "NAME", BindKey: "name", ValueType: "string"},
+		{EnvVar: "PORT", BindKey: "port", ValueType: "int"},
+	}
+
+	assert.EqualValues(t, expected, envs)
+}
+
+func TestGetEnvs_FixedEnvName(t *testing.T) {
+	type Database struct {
+		URL  string `mapstructure:"url" env:"DATABASE_URL,fixed"`
+		Host string `mapstructure:"host" env:"host"`
+		Name string `mapstructure:"name"`
+	}
+	type Config struct {
+		Database Database `mapstructure:"database" env:"db"`
+	}
+
+	envs := GetEnvs(Config{})
+
+	// Without the "fixed" option the env tag is a part of the derived name.
+	expected := []EnvInfo{
+		{EnvVar: "DATABASE_URL", BindKey: "database.url", ValueType: "string", Fixed: true},
+		{EnvVar: "DB_HOST", BindKey: "database.host", ValueType: "string"},
+		{EnvVar: "DB_NAME", BindKey: "database.name", ValueType: "string"},
+	}
+
+	assert.EqualValues(t, expected, envs)
+}
+
+type OrderFirst struct {
+	B string `mapstructure:"b"`
+	C string `mapstructure:"c"`
+}
+
+func TestGetEnvs_FieldOrder(t *testing.T) {
+	type Config struct {
+		A string `mapstructure:"a"`
+		OrderFirst
+		D string `mapstructure:"d"`
+	}
+
+	var names []string
+	for _, e := range GetEnvs(Config{}) {
+		names = append(names, e.EnvVar)
+	}
+
+	assert.Equal(t, []string{"A", "B", "C", "D"}, names)
+}
+
+func TestGetEnvs_SliceOfPointers(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+	}
+	type Config struct {
+		Items []*Item `mapstructure:"items"`
+		Ports []*int  `mapstructure:"ports"`
+	}
+
+	envs := GetEnvs(Config{})
+
+	expected := []EnvInfo{
+		{EnvVar: "ITEMS", BindKey: "items", ValueType: "[]*env.Item", DefaultValue: `[{"name":""}]`},
+		{EnvVar: "PORTS", BindKey: "ports", ValueType: "[]*int", DefaultValue: "[]"},
+	}
+
+	assert.EqualValues(t, expected, envs)
+}
+
+func TestGetEnvsWithNaming(t *testing.T) {
+	type Meta struct {
+		Version string `mapstructure:"version"`
+	}
+	type Config struct {
+		Meta       Meta     `mapstructure:"meta"`
+		AllowedIPs []string `mapstructure:"allowed_ips"`
+		URL        string   `mapstructure:"url" env:"database_url,fixed"`
+	}
+
+	envVars := func(naming Naming) []string {
+		var names []string
+		for _, info := range GetEnvsWithNaming(Config{}, naming) {
+			names = append(names, info.EnvVar)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"META_VERSION", "ALLOWED_IPS", "DATABASE_URL"}, envVars(Naming{}))
+	assert.Equal(t, []string{"MYAPP_META_VERSION", "MYAPP_ALLOWED_IPS", "DATABASE_URL"}, envVars(Naming{Prefix: "myapp"}))
+	assert.Equal(t, []string{"MYAPP__META__VERSION", "MYAPP__ALLOWED_IPS", "DATABASE_URL"},
+		envVars(Naming{Prefix: "MYAPP", Separator: "__"}))
+	assert.Equal(t, []string{"myapp_meta_version", "myapp_allowed_ips", "database_url"},
+		envVars(Naming{Prefix: "myapp", KeepCase: true}))
+	assert.Equal(t, []string{"SERVERS_0_META_VERSION", "SERVERS_0_ALLOWED_IPS", "SERVERS_0_DATABASE_URL"},
+		envVars(Naming{Prefix: "SERVERS_0", InElement: true}))
+}
+
+func TestGetEnvs_PointerSection(t *testing.T) {
+	type Node struct {
+		Name string `mapstructure:"name"`
+		Next *Node  `mapstructure:"next"`
+	}
+	type TLS struct {
+		Cert string `mapstructure:"cert" default:"cert.pem"`
+	}
+	type Config struct {
+		TLS  *TLS       `mapstructure:"tls"`
+		Head *Node      `mapstructure:"head"`
+		At   *time.Time `mapstructure:"at"`
+	}
+
+	envs := GetEnvs(Config{})
+
+	expected := []EnvInfo{
+		{EnvVar: "TLS_CERT", DefaultValue: "cert.pem", BindKey: "tls.cert", ValueType: "string"},
+		{EnvVar: "HEAD_NAME", BindKey: "head.name", ValueType: "string"},
+		{EnvVar: "AT", BindKey: "at", ValueType: "*time.Time"},
+	}
+
+	assert.EqualValues(t, expected, envs)
+}
+
+func TestGetEnvs_Secret(t *testing.T) {
+	type Auth struct {
+		Token string `mapstructure:"token" default:"t0ken"`
+	}
+	type Config struct {
+		Password string   `mapstructure:"password" default:"hunter2" secret:"true"`
+		Keys     []string `mapstructure:"keys" secret:"true"`
+		Auth     Auth     `mapstructure:"auth" secret:"true"`
+	}
+
+	expected := []EnvInfo{
+		{EnvVar: "PASSWORD", BindKey: "password", ValueType: "string", Secret: true},
+		{EnvVar: "KEYS", BindKey: "keys", ValueType: "[]string", Secret: true},
+		{EnvVar: "AUTH_TOKEN", BindKey: "auth.token", ValueType: "string", Secret: true},
+	}
+
+	assert.EqualValues(t, expected, GetEnvs(Config{}))
+}
@@ -0,0 +1,318 @@
+package json
+
+import (
+	gojson "encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// durationType is used to detect time.Duration fields, which would otherwise
+// be treated as plain int64 values.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// timeType is used to detect time.Time fields, which are structs but are
+// configured as a single RFC3339 string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// bytesType is used to detect []byte fields, which are configured as a
+// single string rather than a list of numbers.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// recursiveAnnotation marks fields of a recursive type in comments.
+const recursiveAnnotation = "(recursive)"
+
+// maskedValue replaces the value of fields tagged with `secret:"true"`.
+const maskedValue = `"***"`
+
+// member represents a single "key": value pair of a JSON object.
+// Value holds an already rendered JSON fragment (it may span several lines).
+type member struct {
+	Key   string
+	Value string
+}
+
+// GenerateJSONTemplate generates a JSON template from a given configuration struct.
+// It follows the same rules as the YAML generator: nested structs become nested
+// objects, slices become arrays, maps get an example entry and fields without
+// a default are rendered as null. Keys come from the json tag first, then the
+// mapstructure and yaml tags. Values of secret fields are masked as "***".
+//
+// JSON has no native comments, so when withComments is true the help text of
+// a field is emitted as a sidecar member right before it:
+//
+//	"_host_comment": "The hostname",
+//	"host": "localhost",
+func GenerateJSONTemplate(cfg interface{}, withComments bool) string {
+	members := parseStructure(reflect.TypeOf(cfg), withComments, false, walker.Expanding{})
+	return renderObject(members, 0) + "\n"
+}
+
+// parseStructure traverses a struct (and nested structs) and returns the list
+// of object members in field declaration order. expanding holds the structs
+// being rendered: fields referring back to one of them are rendered empty,
+// since a template of a recursive type would never end. With inSecret set
+// every value is masked.
+func parseStructure(t reflect.Type, withComments, inSecret bool, expanding walker.Expanding) []member {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	expanding[t] = true
+	defer delete(expanding, t)
+
+	var members []member
+
+	for _, field := range walker.Fields(t, nil) {
+		// In JSON templates fields tagged with `json:"-"` are ignored as well.
+		if field.Tags["json"] == "-" {
+			continue
+		}
+
+		fieldName := field.KeyFor(walker.JSONKeyTags)
+		helpText := field.Help
+		recursive := expanding.Recursive(field.Type)
+		if recursive {
+			helpText = strings.TrimSpace(helpText + " " + recursiveAnnotation)
+		}
+
+		if withComments && helpText != "" {
+			members = append(members, member{
+				Key:   fmt.Sprintf("_%s_comment", fieldName),
+				Value: quote(helpText),
+			})
+		}
+
+		value := renderEmpty(field.Type)
+		if !recursive {
+			secret := inSecret || field.Tags["secret"] == "true"
+			value = renderValue(field.Type, field.Tag, withComments, secret, expanding)
+		}
+		members = append(members, member{
+			Key:   fieldName,
+			Value: value,
+		})
+	}
+
+	return members
+}
+
+// renderValue renders the value of a single field. Nested values are rendered
+// with zero indentation and are re-indented by renderObject. Secret fields
+// never echo their defaults: nested objects, arrays and maps keep their
+// shape, but every value inside them is masked.
+func renderValue(t reflect.Type, tag reflect.StructTag, withComments, secret bool, expanding walker.Expanding) string {
+	defaultValue := getDefaultValue(tag)
+
+	if secret && !isCollection(t) {
+		return maskedValue
+	}
+
+	if t == durationType {
+		if defaultValue == "" {
+			defaultValue = "0s"
+		}
+		return quote(defaultValue)
+	}
+
+	if t == timeType || t == bytesType || walker.TextType(t) {
+		if defaultValue == "" {
+			return "null"
+		}
+		return quote(defaultValue)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return renderObject(parseStructure(t, withComments, secret, expanding), 0)
+
+	case reflect.Slice:
+		// For slices of structs (or pointers to them) we show a single zero
+		// value element.
+		elemType := t.Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() == reflect.Struct {
+			return renderArray([]string{renderObject(parseStructure(elemType, withComments, secret, expanding), 0)})
+		}
+		// Slices of maps show one element holding the sample entry.
+		if elemType.Kind() == reflect.Map {
+			return renderArray([]string{renderValue(elemType, tag, withComments, secret, expanding)})
+		}
+		if secret {
+			return renderArray([]string{maskedValue})
+		}
+
+		// For slices of primitives, we split the default value into items.
+		if defaultValue == "" {
+			return renderArray([]string{quote("example")})
+		}
+		items, isJSON := splitSliceDefault(defaultValue)
+		if !isJSON {
+			for i, item := range items {
+				items[i] = renderScalar(elemType.Kind(), item)
+			}
+		}
+		return renderArray(items)
+
+	case reflect.Map:
+		// For maps, we just show a sample key and value.
+		exampleKey, exampleValue := getMapExample(tag, t.Key())
+		value := quote(exampleValue)
+		if secret {
+			value = maskedValue
+		}
+		return renderObject([]member{{Key: exampleKey, Value: value}}, 0)
+
+	case reflect.Interface:
+		// Opaque fields (interface{} / any) take their value from the `example`
+		// tag, then from `default`. Valid JSON is used as is, anything else
+		// becomes a string.
+		value := tag.Get("example")
+		if value == "" {
+			value = defaultValue
+		}
+		if value == "" {
+			return "null"
+		}
+		if gojson.Valid([]byte(value)) {
+			return value
+		}
+		return quote(value)
+
+	default:
+		if defaultValue == "" {
+			return "null"
+		}
+		// JSON has no octal or hex literals, such defaults are decimal.
+		if literal, ok := walker.FormatInteger(t.Kind(), defaultValue, walker.IntBase(tag), walker.JSON); ok {
+			return literal
+		}
+		return renderScalar(t.Kind(), defaultValue)
+	}
+}
+
+// isCollection reports whether values of type t are rendered as objects or
+// arrays: structs, slices and maps, except for times, []byte and text types.
+func isCollection(t reflect.Type) bool {
+	if t == timeType || t == bytesType || walker.TextType(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+// renderEmpty renders the empty value of a field of a recursive type.
+func renderEmpty(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "[]"
+	case reflect.Map:
+		return "{}"
+	default:
+		return "null"
+	}
+}
+
+// renderScalar renders a primitive value. Bools and numbers are written as
+// native literals (see walker.FormatScalar), everything else is quoted and
+// escaped.
+func renderScalar(kind reflect.Kind, value string) string {
+	if literal, ok := walker.FormatScalar(kind, value, walker.JSON); ok {
+		return literal
+	}
+	return quote(value)
+}
+
+// renderObject renders members as a JSON object indented by indent levels.
+func renderObject(members []member, indent int) string {
+	if len(members) == 0 {
+		return "{}"
+	}
+
+	var builder strings.Builder
+	builder.WriteString("{\n")
+	for i, m := range members {
+		builder.WriteString(strings.Repeat("  ", indent+1))
+		builder.WriteString(quote(m.Key) + ": " + reindent(m.Value, indent+1))
+		if i < len(members)-1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString(strings.Repeat("  ", indent) + "}")
+	return builder.String()
+}
+
+// renderArray renders already rendered items as a JSON array.
+func renderArray(items []string) string {
+	var builder strings.Builder
+	builder.WriteString("[\n")
+	for i, item := range items {
+		builder.WriteString("  " + reindent(item, 1))
+		if i < len(items)-1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("]")
+	return builder.String()
+}
+
+// reindent shifts every line except the first one by indent levels,
+// so that a nested fragment lines up with its parent key.
+func reindent(value string, indent int) string {
+	return strings.ReplaceAll(value, "\n", "\n"+strings.Repeat("  ", indent))
+}
+
+// quote returns s as a JSON string literal.
+func quote(s string) string {
+	b, _ := gojson.Marshal(s)
+	return string(b)
+}
+
+// splitSliceDefault splits the default value of a slice into its items.
+// A value starting with "[" is parsed as a JSON array and its items are
+// returned in JSON form, which allows items containing commas, e.g.
+// `default:"[\"a,b\", \"c\"]"`. Otherwise the value is split by commas.
+func splitSliceDefault(value string) (items []string, isJSON bool) {
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var raw []gojson.RawMessage
+		if err := gojson.Unmarshal([]byte(value), &raw); err == nil {
+			for _, item := range raw {
+				items = append(items, string(item))
+			}
+			return items, true
+		}
+	}
+	for _, item := range strings.Split(value, ",") {
+		items = append(items, strings.TrimSpace(item))
+	}
+	return items, false
+}
+
+// getDefaultValue extracts the default value from struct tags.
+func getDefaultValue(tag reflect.StructTag) string {
+	return tag.Get("default")
+}
+
+// getMapExample returns the sample key and value rendered for map fields,
+// customizable with the `example_key` and `example_value` tags. The default
+// key matches keyType, e.g. "1" for integer keys.
+func getMapExample(tag reflect.StructTag, keyType reflect.Type) (string, string) {
+	key, value := tag.Get("example_key"), tag.Get("example_value")
+	if key == "" {
+		key = walker.MapKeyExample(keyType)
+	}
+	if value == "" {
+		value = "value"
+	}
+	return key, value
+}
@@ -0,0 +1,396 @@
+package json
+
+import (
+	gojson "encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateJSONTemplate(t *testing.T) {
+	type Config struct {
+		Host    string        `mapstructure:"host" default:"localhost" help:"The hostname"`
+		Port    int           `mapstructure:"port" default:"8080" help:"The port number"`
+		Enabled bool          `mapstructure:"enabled" default:"true"`
+		Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+		Options []string      `mapstructure:"options" default:"1,2,3"`
+		Meta    struct {
+			Version string `mapstructure:"version" default:"1.0" help:"App version"`
+		} `mapstructure:"meta"`
+		MapField map[string]string `mapstructure:"map_field"`
+		Nickname string            `mapstructure:"nickname"`
+	}
+	jsonTemplate := GenerateJSONTemplate(Config{}, true)
+
+	expected := `{
+  "_host_comment": "The hostname",
+  "host": "localhost",
+  "_port_comment": "The port number",
+  "port": 8080,
+  "enabled": true,
+  "timeout": "30s",
+  "options": [
+    "1",
+    "2",
+    "3"
+  ],
+  "meta": {
+    "_version_comment": "App version",
+    "version": "1.0"
+  },
+  "map_field": {
+    "key": "value"
+  },
+  "nickname": null
+}
+`
+
+	assert.Equal(t, expected, jsonTemplate)
+	assert.True(t, gojson.Valid([]byte(jsonTemplate)))
+}
+
+// Test JSON generation without comments.
+func TestGenerateJSONTemplate_WithoutComments(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+		Port int    `json:"port,omitempty" help:"The port number"`
+	}{}
+	jsonTemplate := GenerateJSONTemplate(cfg, false)
+
+	expected := `{
+  "host": "localhost",
+  "port": null
+}
+`
+
+	assert.Equal(t, expected, jsonTemplate)
+}
+
+// Test JSON generation with array of structs.
+func TestGenerateJSONTemplate_ArrayOfStructs(t *testing.T) {
+	type Item struct {
+		Name  string `yaml:"name" default:"item1"`
+		Value int    `yaml:"value"`
+	}
+	cfg := struct {
+		Items []Item   `yaml:"items"`
+		Tags  []string `yaml:"tags"`
+	}{}
+	jsonTemplate := GenerateJSONTemplate(cfg, false)
+
+	expected := `{
+  "items": [
+    {
+      "name": "item1",
+      "value": null
+    }
+  ],
+  "tags": [
+    "example"
+  ]
+}
+`
+
+	assert.Equal(t, expected, jsonTemplate)
+	assert.True(t, gojson.Valid([]byte(jsonTemplate)))
+}
+
+// Test that slices of pointers render like slices of values.
+func TestGenerateJSONTemplate_ArrayOfPointers(t *testing.T) {
+	type Item struct {
+		Name string `yaml:"name" default:"item1"`
+	}
+	cfg := struct {
+		Items []*Item `yaml:"items"`
+		Ports []*int  `yaml:"ports" default:"80,443"`
+	}{}
+	jsonTemplate := GenerateJSONTemplate(cfg, false)
+
+	expected := `{
+  "items": [
+    {
+      "name": "item1"
+    }
+  ],
+  "ports": [
+    80,
+    443
+  ]
+}
+`
+
+	assert.Equal(t, expected, jsonTemplate)
+	assert.True(t, gojson.Valid([]byte(jsonTemplate)))
+}
+
+// Test JSON generation with an empty struct.
+func TestGenerateJSONTemplate_Empty(t *testing.T) {
+	assert.Equal(t, "{}\n", GenerateJSONTemplate(struct{}{}, true))
+}
+
+// Test JSON generation with custom map examples.
+func TestGenerateJSONTemplate_MapExampleTags(t *testing.T) {
+	cfg := struct {
+		Regions map[string]string `yaml:"regions" example_key:"region" example_value:"us-east-1"`
+	}{}
+	jsonTemplate := GenerateJSONTemplate(cfg, false)
+
+	expected := `{
+  "regions": {
+    "region": "us-east-1"
+  }
+}
+`
+
+	assert.Equal(t, expected, jsonTemplate)
+}
+
+// Test JSON generation with JSON-array slice defaults.
+func TestGenerateJSONTemplate_JSONSliceDefault(t *testing.T) {
+	cfg := struct {
+		Queries []string `yaml:"queries" default:"[\"a,b\", \"c\"]"`
+		Ports   []int    `yaml:"ports" default:"[80, 443]"`
+	}{}
+	jsonTemplate := GenerateJSONTemplate(cfg, false)
+
+	expected := `{
+  "queries": [
+    "a,b",
+    "c"
+  ],
+  "ports": [
+    80,
+    443
+  ]
+}
+`
+
+	assert.Equal(t, expected, jsonTemplate)
+	assert.True(t, gojson.Valid([]byte(jsonTemplate)))
+}
+
+type OrderFirst struct {
+	B string `json:"b"`
+	C string `json:"c"`
+}
+
+type OrderSecond struct {
+	E string `json:"e"`
+}
+
+// Test that flattened embedded fields keep the position of the embedded field.
+func TestGenerateJSONTemplate_FieldOrder(t *testing.T) {
+	cfg := struct {
+		A string `json:"a"`
+		OrderFirst
+		D string `json:"d"`
+		*OrderSecond
+		F string `json:"f"`
+	}{}
+
+	expected := `{
+  "a": null,
+  "b": null,
+  "c": null,
+  "d": null,
+  "ordersecond": {
+    "e": null
+  },
+  "f": null
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg, false))
+}
+
+// Test JSON generation of interface{} fields.
+func TestGenerateJSONTemplate_Interface(t *testing.T) {
+	cfg := struct {
+		Payload interface{}    `json:"payload" example:"{\"retries\": 3}"`
+		Name    any            `json:"name" example:"anything"`
+		Value   any            `json:"value"`
+		Extra   map[string]any `json:"extra"`
+	}{}
+	jsonTemplate := GenerateJSONTemplate(cfg, false)
+
+	expected := `{
+  "payload": {"retries": 3},
+  "name": "anything",
+  "value": null,
+  "extra": {
+    "key": "value"
+  }
+}
+`
+
+	assert.Equal(t, expected, jsonTemplate)
+	assert.True(t, gojson.Valid([]byte(jsonTemplate)))
+}
+
+// Test that pointer fields are rendered as the pointed-to type, like in the
+// YAML and TOML templates.
+func TestGenerateJSONTemplate_Pointers(t *testing.T) {
+	type TLS struct {
+		Cert string `json:"cert" default:"cert.pem"`
+	}
+	cfg := struct {
+		TLS  *TLS `json:"tls"`
+		Port *int `json:"port" default:"443"`
+	}{}
+
+	expected := `{
+  "tls": {
+    "cert": "cert.pem"
+  },
+  "port": 443
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(&cfg, false))
+}
+
+func TestGenerateJSONTemplate_Time(t *testing.T) {
+	cfg := struct {
+		ValidFrom time.Time `json:"valid_from" default:"2024-01-01T00:00:00Z"`
+		Until     time.Time `json:"until"`
+	}{}
+
+	expected := `{
+  "valid_from": "2024-01-01T00:00:00Z",
+  "until": null
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg, false))
+}
+
+func TestGenerateJSONTemplate_Bytes(t *testing.T) {
+	cfg := struct {
+		Key []byte `json:"key" default:"aGVsbG8=" encoding:"base64"`
+		Raw []byte `json:"raw"`
+	}{}
+
+	expected := `{
+  "key": "aGVsbG8=",
+  "raw": null
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg, false))
+}
+
+type treeNode struct {
+	Name     string     `json:"name" default:"root"`
+	Children []treeNode `json:"children" help:"Child nodes"`
+	Next     *treeNode  `json:"next"`
+}
+
+func TestGenerateJSONTemplate_Recursive(t *testing.T) {
+	cfg := struct {
+		Tree treeNode `json:"tree"`
+	}{}
+
+	expected := `{
+  "tree": {
+    "name": "root",
+    "_children_comment": "Child nodes (recursive)",
+    "children": [],
+    "_next_comment": "(recursive)",
+    "next": null
+  }
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg, true))
+}
+
+func TestGenerateJSONTemplate_DisagreeingTags(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" json:"hostname" default:"localhost"`
+		Port int    `yaml:"port" mapstructure:"listen_port" default:"80"`
+		Name string `yaml:"name"`
+		Skip string `yaml:"skip" json:"-"`
+	}{}
+
+	expected := `{
+  "hostname": "localhost",
+  "listen_port": 80,
+  "name": null
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg, false))
+}
+
+func TestGenerateJSONTemplate_NonStringMapKeys(t *testing.T) {
+	cfg := struct {
+		Priorities map[int]string `json:"priorities"`
+		Flags      map[bool]int   `json:"flags"`
+	}{}
+
+	expected := `{
+  "priorities": {
+    "1": "value"
+  },
+  "flags": {
+    "true": "value"
+  }
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg, false))
+}
+
+func TestGenerateJSONTemplate_SliceOfMaps(t *testing.T) {
+	cfg := struct {
+		Labels  []map[string]string `json:"labels"`
+		Weights []map[string]int    `json:"weights" example_key:"canary"`
+	}{}
+
+	expected := `{
+  "labels": [
+    {
+      "key": "value"
+    }
+  ],
+  "weights": [
+    {
+      "canary": "value"
+    }
+  ]
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg, false))
+}
+
+func TestGenerateJSONTemplate_Secret(t *testing.T) {
+	type credentials struct {
+		User string `json:"user" default:"admin"`
+	}
+	cfg := struct {
+		Host     string            `json:"host" default:"localhost"`
+		Password string            `json:"password" default:"hunter2" secret:"true"`
+		Tokens   []string          `json:"tokens" default:"a,b" secret:"true"`
+		Headers  map[string]string `json:"headers" secret:"true"`
+		Auth     credentials       `json:"auth" secret:"true"`
+	}{}
+
+	expected := `{
+  "host": "localhost",
+  "password": "***",
+  "tokens": [
+    "***"
+  ],
+  "headers": {
+    "key": "***"
+  },
+  "auth": {
+    "user": "***"
+  }
+}
+`
+
+	assert.Equal(t, expected, GenerateJSONTemplate(cfg, false))
+}
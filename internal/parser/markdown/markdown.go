@@ -0,0 +1,186 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// maskedValue replaces the defaults of secret fields.
+const maskedValue = "***"
+
+// timeType is used to detect time.Time fields, which are structs but are
+// configured as a single RFC3339 string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// Options configures Markdown generation.
+type Options struct {
+	// SectionTables emits one table per top-level section instead of a
+	// single table for the whole config.
+	SectionTables bool
+	// EnvNaming derives the names of the Env Var column.
+	EnvNaming env.Naming
+}
+
+// Option configures Markdown generation.
+type Option func(*Options)
+
+// WithSectionTables emits one table per top-level struct, each under its own
+// "## key" heading. Top-level fields that are not structs come first, in a
+// table without a heading.
+func WithSectionTables() Option {
+	return func(o *Options) {
+		o.SectionTables = true
+	}
+}
+
+// WithEnvNaming derives the names of the Env Var column with the given naming
+// rules, which should match the ones of the loader.
+func WithEnvNaming(naming env.Naming) Option {
+	return func(o *Options) {
+		o.EnvNaming = naming
+	}
+}
+
+// row is a single row of a table. Rows of nested structs are section
+// headers, which only carry the key and the description.
+type row struct {
+	Field  walker.Field
+	Header bool
+}
+
+// GenerateMarkdownDocs generates a Markdown reference of the config struct,
+// with one row per field: the dotted key, the Go type, the default, the
+// environment variable, whether the field is required and its help text.
+// Nested structs produce section header rows. The output only depends on the
+// struct type, so it can be committed and checked for changes.
+func GenerateMarkdownDocs(cfg interface{}, opts ...Option) string {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	envNames := make(map[string]string)
+	for _, info := range env.GetEnvsWithNaming(cfg, o.EnvNaming) {
+		envNames[info.BindKey] = info.EnvVar
+	}
+
+	// Rows are grouped by top-level section: the first group holds the
+	// top-level fields that are not structs.
+	var general []row
+	var sections [][]row
+	err := walker.Walk(reflect.TypeOf(cfg), func(f walker.Field) error {
+		r := row{Field: f, Header: f.Kind == reflect.Struct && f.Type != timeType && !walker.TextType(f.Type)}
+		switch {
+		case !o.SectionTables:
+			general = append(general, r)
+		case f.Depth == 0 && r.Header:
+			sections = append(sections, []row{r})
+		case f.Depth == 0:
+			general = append(general, r)
+		default:
+			sections[len(sections)-1] = append(sections[len(sections)-1], r)
+		}
+		return nil
+	})
+	if err != nil {
+		return ""
+	}
+
+	var parts []string
+	if len(general) > 0 {
+		parts = append(parts, renderTable(general, envNames))
+	}
+	for _, section := range sections {
+		header := section[0].Field
+		blocks := []string{"## " + header.Key + "\n"}
+		if header.Help != "" {
+			blocks = append(blocks, header.Help+"\n")
+		}
+		if len(section) > 1 {
+			blocks = append(blocks, renderTable(section[1:], envNames))
+		}
+		parts = append(parts, strings.Join(blocks, "\n"))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// renderTable renders the rows as a Markdown table.
+func renderTable(rows []row, envNames map[string]string) string {
+	var builder strings.Builder
+	builder.WriteString("| Key | Type | Default | Env Var | Required | Description |\n")
+	builder.WriteString("|-----|------|---------|---------|----------|-------------|\n")
+
+	for _, r := range rows {
+		f := r.Field
+		help := walker.HelpWithUnit(f.Help, f.Unit)
+		if f.Recursive {
+			help = strings.TrimSpace(help + " (recursive)")
+		}
+		if r.Header {
+			builder.WriteString("| **" + escape(f.Path) + "** | | | | | " + escape(help) + " |\n")
+			continue
+		}
+
+		defaultValue := f.Default
+		if defaultValue != "" && isSecret(f) {
+			defaultValue = maskedValue
+		}
+		required := "no"
+		if isRequired(f.Tag) {
+			required = "yes"
+		}
+
+		cells := []string{
+			code(f.Path),
+			code(f.StructField.Type.String()),
+			code(defaultValue),
+			code(envNames[f.BindKey]),
+			required,
+			escape(help),
+		}
+		builder.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return builder.String()
+}
+
+// code renders a non-empty value as inline code.
+func code(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + escape(value) + "`"
+}
+
+// escape makes a value safe to use in a table cell.
+func escape(value string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+}
+
+// isSecret reports whether the field or one of its parents is tagged with
+// `secret:"true"`.
+func isSecret(f walker.Field) bool {
+	for p := &f; p != nil; p = p.Parent {
+		if p.Tag.Get("secret") == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// isRequired reports whether the field is marked as mandatory either with
+// `required:"true"` or with `validate:"required"`.
+func isRequired(tag reflect.StructTag) bool {
+	if tag.Get("required") == "true" {
+		return true
+	}
+	for _, rule := range strings.Split(tag.Get("validate"), ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type docsMeta struct {
+	Version string `mapstructure:"version" default:"1.0" help:"App version"`
+	Build   struct {
+		Commit string `mapstructure:"commit" help:"Git commit"`
+	} `mapstructure:"build" help:"Build info"`
+}
+
+type docsConfig struct {
+	Host     string        `mapstructure:"host" default:"localhost" help:"The hostname"`
+	Port     int           `mapstructure:"port" default:"8080" required:"true" env:"APP_PORT"`
+	Timeout  time.Duration `mapstructure:"timeout" default:"30s" help:"Timeout | in seconds"`
+	Password string        `mapstructure:"password" default:"hunter2" secret:"true"`
+	Meta     docsMeta      `mapstructure:"meta" help:"Meta section"`
+	Tags     []string      `mapstructure:"tags" default:"a,b" validate:"required"`
+}
+
+func TestGenerateMarkdownDocs(t *testing.T) {
+	expected := "| Key | Type | Default | Env Var | Required | Description |\n" +
+		"|-----|------|---------|---------|----------|-------------|\n" +
+		"| `host` | `string` | `localhost` | `HOST` | no | The hostname |\n" +
+		"| `port` | `int` | `8080` | `APP_PORT` | yes |  |\n" +
+		"| `timeout` | `time.Duration` | `30s` | `TIMEOUT` | no | Timeout \\| in seconds |\n" +
+		"| `password` | `string` | `***` | `PASSWORD` | no |  |\n" +
+		"| **meta** | | | | | Meta section |\n" +
+		"| `meta.version` | `string` | `1.0` | `META_VERSION` | no | App version |\n" +
+		"| **meta.build** | | | | | Build info |\n" +
+		"| `meta.build.commit` | `string` |  | `META_BUILD_COMMIT` | no | Git commit |\n" +
+		"| `tags` | `[]string` | `a,b` | `TAGS` | yes |  |\n"
+
+	assert.Equal(t, expected, GenerateMarkdownDocs(docsConfig{}))
+}
+
+func TestGenerateMarkdownDocs_SectionTables(t *testing.T) {
+	expected := "| Key | Type | Default | Env Var | Required | Description |\n" +
+		"|-----|------|---------|---------|----------|-------------|\n" +
+		"| `host` | `string` | `localhost` | `HOST` | no | The hostname |\n" +
+		"| `port` | `int` | `8080` | `APP_PORT` | yes |  |\n" +
+		"| `timeout` | `time.Duration` | `30s` | `TIMEOUT` | no | Timeout \\| in seconds |\n" +
+		"| `password` | `string` | `***` | `PASSWORD` | no |  |\n" +
+		"| `tags` | `[]string` | `a,b` | `TAGS` | yes |  |\n" +
+		"\n" +
+		"## meta\n" +
+		"\n" +
+		"Meta section\n" +
+		"\n" +
+		"| Key | Type | Default | Env Var | Required | Description |\n" +
+		"|-----|------|---------|---------|----------|-------------|\n" +
+		"| `meta.version` | `string` | `1.0` | `META_VERSION` | no | App version |\n" +
+		"| **meta.build** | | | | | Build info |\n" +
+		"| `meta.build.commit` | `string` |  | `META_BUILD_COMMIT` | no | Git commit |\n"
+
+	assert.Equal(t, expected, GenerateMarkdownDocs(&docsConfig{}, WithSectionTables()))
+}
+
+func TestGenerateMarkdownDocs_NotAStruct(t *testing.T) {
+	assert.Equal(t, "", GenerateMarkdownDocs(42))
+}
+
+func TestGenerateMarkdownDocs_Unit(t *testing.T) {
+	cfg := struct {
+		MaxUpload int `mapstructure:"max_upload" unit:"MB" help:"Max upload size"`
+	}{}
+
+	assert.Contains(t, GenerateMarkdownDocs(cfg), "| `max_upload` | `int` |  | `MAX_UPLOAD` | no | Max upload size (MB) |\n")
+}
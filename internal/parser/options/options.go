@@ -0,0 +1,370 @@
+package options
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vsysa/configo/internal/parser/env"
+)
+
+// tabWidth is the tab stop width assumed when aligning comments with tabs.
+const tabWidth = 8
+
+// Alignment controls how help comments are aligned to a column.
+type Alignment int
+
+const (
+	// AlignGlobal aligns every comment of the template to a single column,
+	// computed from the longest line of the whole template.
+	AlignGlobal Alignment = iota
+
+	// AlignPerBlock aligns comments of sibling keys only: each nested struct,
+	// slice or map forms its own block with its own comment column.
+	AlignPerBlock
+)
+
+// StringQuoting controls how string values are quoted in YAML templates.
+type StringQuoting int
+
+const (
+	// QuoteAlways writes every string in double quotes.
+	QuoteAlways StringQuoting = iota
+
+	// QuoteWhenNeeded quotes only the strings that would otherwise read back
+	// as another value or break the document, e.g. "true", "123", "on",
+	// "a: b" or strings with leading or trailing spaces.
+	QuoteWhenNeeded
+
+	// QuoteNever writes every string as is.
+	QuoteNever
+)
+
+// CommentPlacement controls where help comments are written in YAML templates.
+type CommentPlacement int
+
+const (
+	// CommentInline writes the comment on the line of its key, aligned to a
+	// column.
+	CommentInline CommentPlacement = iota
+
+	// CommentAbove writes the comment on lines of its own above its key,
+	// indented like the key.
+	CommentAbove
+)
+
+// Options holds the settings shared by the template generators. Every
+// generator honors the settings that apply to its format.
+type Options struct {
+	Alignment Alignment
+	// BoolHints appends "(true|false)" to the comments of bool fields.
+	BoolHints bool
+	// NullPointers renders nil pointers as null in GenerateYAMLFromValues.
+	NullPointers bool
+	// EnvNames appends the fixed environment variable name of fields with
+	// an `env:"NAME,fixed"` tag to their comments.
+	EnvNames bool
+	// SecretPlaceholders renders secret fields as empty strings with a hint
+	// to their environment variable instead of "***".
+	SecretPlaceholders bool
+	// CommentPrefix starts every help comment. Empty means "#".
+	CommentPrefix string
+	// AlignChar pads lines up to the comment column. Zero means a space.
+	AlignChar byte
+	// EnvNaming derives the environment variable names shown in the docs.
+	EnvNaming env.Naming
+	// OptionalCommented comments out optional sections in YAML templates.
+	OptionalCommented bool
+	// OmitTrailingNewline drops the newline ending generated documents.
+	OmitTrailingNewline bool
+	// OmitEmptyDefaults leaves fields with a zero default out of YAML
+	// templates.
+	OmitEmptyDefaults bool
+	// EmptyCollections renders slices and maps without a default or example
+	// as empty collections in YAML templates.
+	EmptyCollections bool
+	// StringQuoting selects how string values are quoted in YAML templates.
+	StringQuoting StringQuoting
+	// CommentPlacement selects where help comments go in YAML templates.
+	CommentPlacement CommentPlacement
+	// CommentWidth is the maximum length of the comment lines written above
+	// their keys. Zero means no wrapping.
+	CommentWidth int
+	// Header is written verbatim before the first key of a document.
+	Header string
+	// Footer is written verbatim after the last line of a document.
+	Footer string
+	// Profiles are the active profiles of YAML templates. Fields tagged with
+	// `profiles` are rendered only if they share one of them. Empty means
+	// every field is rendered.
+	Profiles []string
+}
+
+// Option configures the template generators.
+type Option func(*Options)
+
+// New returns the options with opts applied.
+func New(opts ...Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithAlignment selects the comment alignment mode. AlignGlobal is the default.
+func WithAlignment(alignment Alignment) Option {
+	return func(o *Options) {
+		o.Alignment = alignment
+	}
+}
+
+// WithBoolHints appends the valid literals "(true|false)" to the comments
+// of bool fields, since YAML also accepts confusing forms like yes/no.
+func WithBoolHints() Option {
+	return func(o *Options) {
+		o.BoolHints = true
+	}
+}
+
+// WithEnvNames appends "# env: NAME" to the comments of fields that have
+// a fixed `env:"NAME,fixed"` tag, so operators know the override variable.
+func WithEnvNames() Option {
+	return func(o *Options) {
+		o.EnvNames = true
+	}
+}
+
+// WithSecretPlaceholders renders the values of secret fields in YAML
+// templates as empty strings instead of "***", and appends
+// "(set via NAME env var)" to the comments of those with a fixed
+// `env:"NAME,fixed"` tag. Their defaults are never written.
+func WithSecretPlaceholders() Option {
+	return func(o *Options) {
+		o.SecretPlaceholders = true
+	}
+}
+
+// WithNullPointers renders nil pointers as null in GenerateYAMLFromValues
+// instead of the zero value of the pointed-to type.
+func WithNullPointers() Option {
+	return func(o *Options) {
+		o.NullPointers = true
+	}
+}
+
+// WithCommentPrefix sets the string starting every help comment, e.g. "##".
+// The default is "#".
+func WithCommentPrefix(prefix string) Option {
+	return func(o *Options) {
+		o.CommentPrefix = prefix
+	}
+}
+
+// WithAlignChar sets the character padding lines up to the comment column,
+// e.g. '\t'. The default is a space.
+func WithAlignChar(c byte) Option {
+	return func(o *Options) {
+		o.AlignChar = c
+	}
+}
+
+// WithEnvNaming derives the environment variable names shown in the docs
+// with the given naming rules, which should match the ones of the loader.
+func WithEnvNaming(naming env.Naming) Option {
+	return func(o *Options) {
+		o.EnvNaming = naming
+	}
+}
+
+// WithOptionalCommented comments out the lines of optional fields in YAML
+// templates: pointers to structs and fields tagged with `optional:"true"`,
+// unless they are required.
+func WithOptionalCommented(enabled bool) Option {
+	return func(o *Options) {
+		o.OptionalCommented = enabled
+	}
+}
+
+// WithOmitEmptyDefaults leaves out of YAML templates the fields whose default
+// is the zero value of their type, unless they are required or have a help
+// text. Nested structs left without fields are left out too.
+func WithOmitEmptyDefaults(enabled bool) Option {
+	return func(o *Options) {
+		o.OmitEmptyDefaults = enabled
+	}
+}
+
+// WithEmptyCollections renders slices of scalars without a default as `[]`
+// and maps of scalars without `example_key` or `example_value` tags as `{}`
+// in YAML templates, instead of the "- example" and "key: value"
+// placeholders.
+func WithEmptyCollections(enabled bool) Option {
+	return func(o *Options) {
+		o.EmptyCollections = enabled
+	}
+}
+
+// WithStringQuoting selects how string values are quoted in YAML templates.
+// QuoteAlways is the default.
+func WithStringQuoting(policy StringQuoting) Option {
+	return func(o *Options) {
+		o.StringQuoting = policy
+	}
+}
+
+// WithCommentPlacement selects where help comments go in YAML templates.
+// CommentInline is the default.
+func WithCommentPlacement(placement CommentPlacement) Option {
+	return func(o *Options) {
+		o.CommentPlacement = placement
+	}
+}
+
+// WithCommentWidth wraps the comments written above their keys with
+// CommentAbove so that their lines are at most width characters long, where
+// the words allow it.
+func WithCommentWidth(width int) Option {
+	return func(o *Options) {
+		o.CommentWidth = width
+	}
+}
+
+// WithProfiles sets the active profiles of YAML templates, e.g.
+// "community". Fields tagged with `profiles:"enterprise,cloud"` are rendered
+// only if one of their profiles is active; fields without the tag always are.
+func WithProfiles(profiles ...string) Option {
+	return func(o *Options) {
+		o.Profiles = append(o.Profiles, profiles...)
+	}
+}
+
+// WithHeader writes header verbatim at the top of generated documents, e.g.
+// a license banner. The caller writes the comment prefixes. JSON templates
+// have no comments and take no options, so they never get a header.
+func WithHeader(header string) Option {
+	return func(o *Options) {
+		o.Header = header
+	}
+}
+
+// WithFooter writes footer verbatim at the end of generated documents other
+// than JSON templates. The caller writes the comment prefixes.
+func WithFooter(footer string) Option {
+	return func(o *Options) {
+		o.Footer = footer
+	}
+}
+
+// WithoutTrailingNewline drops the newline ending generated documents, e.g.
+// to embed them into a larger document.
+func WithoutTrailingNewline() Option {
+	return func(o *Options) {
+		o.OmitTrailingNewline = true
+	}
+}
+
+// Finish wraps a generated document in the header and footer and applies
+// the trailing newline policy: a document with content ends with exactly one
+// newline, or none with OmitTrailingNewline, and a document without content
+// is empty, without a header or footer.
+func (o Options) Finish(doc string) string {
+	if strings.TrimSpace(doc) == "" {
+		return ""
+	}
+	doc = strings.TrimRight(doc, "\n")
+	if header := o.Header; header != "" {
+		if !strings.HasSuffix(header, "\n") {
+			header += "\n"
+		}
+		doc = header + doc
+	}
+	if o.Footer != "" {
+		doc = strings.TrimRight(doc+"\n"+o.Footer, "\n")
+	}
+	if o.OmitTrailingNewline {
+		return doc
+	}
+	return doc + "\n"
+}
+
+// Comment renders a help text as a comment, e.g. "# The hostname". The lines
+// of a multi-line help text are joined with spaces, since a comment must not
+// spill over into the next line of the document.
+func (o Options) Comment(help string) string {
+	prefix := o.CommentPrefix
+	if prefix == "" {
+		prefix = "#"
+	}
+	if strings.ContainsAny(help, "\r\n") {
+		lines := strings.FieldsFunc(help, func(r rune) bool { return r == '\r' || r == '\n' })
+		for i, line := range lines {
+			lines[i] = strings.TrimSpace(line)
+		}
+		help = strings.Join(lines, " ")
+	}
+	return prefix + " " + help
+}
+
+// CommentLines renders a help text as comment lines of their own, each
+// starting with indentation. Every line of a multi-line help text gets its own
+// comment line, and with CommentWidth the lines are wrapped at word
+// boundaries. A word longer than the width is kept whole.
+func (o Options) CommentLines(help, indentation string) []string {
+	prefix := indentation + o.Comment("")
+	var lines []string
+	help = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(strings.TrimSpace(help))
+	for _, text := range strings.Split(help, "\n") {
+		words := strings.Fields(text)
+		if len(words) == 0 {
+			lines = append(lines, strings.TrimRight(prefix, " "))
+			continue
+		}
+		line := prefix + words[0]
+		for _, word := range words[1:] {
+			if o.CommentWidth > 0 && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > o.CommentWidth {
+				lines = append(lines, line)
+				line = prefix + word
+				continue
+			}
+			line += " " + word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Padding returns the padding placed between a line of the given width and
+// its comment, so that the comment starts one column past maxWidth. With tabs
+// the comment starts at the first tab stop past maxWidth instead.
+func (o Options) Padding(width, maxWidth int) string {
+	switch o.AlignChar {
+	case 0, ' ':
+		return strings.Repeat(" ", maxWidth-width+1)
+	case '\t':
+		return strings.Repeat("\t", maxWidth/tabWidth+1-width/tabWidth)
+	default:
+		return strings.Repeat(string(o.AlignChar), maxWidth-width+1)
+	}
+}
+
+// InProfiles reports whether a field with the given tag is rendered with the
+// active profiles: without active profiles or a `profiles` tag it is,
+// otherwise one of its comma-separated profiles must be active.
+func (o Options) InProfiles(tag reflect.StructTag) bool {
+	if len(o.Profiles) == 0 {
+		return true
+	}
+	profiles := strings.TrimSpace(tag.Get("profiles"))
+	if profiles == "" {
+		return true
+	}
+	for _, profile := range strings.Split(profiles, ",") {
+		for _, active := range o.Profiles {
+			if strings.TrimSpace(profile) == active {
+				return true
+			}
+		}
+	}
+	return false
+}
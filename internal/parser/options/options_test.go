@@ -0,0 +1,62 @@
+package options
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_Comment(t *testing.T) {
+	assert.Equal(t, "# The hostname", New().Comment("The hostname"))
+	assert.Equal(t, "## The hostname", New(WithCommentPrefix("##")).Comment("The hostname"))
+	assert.Equal(t, "# Line one line two", New().Comment("Line one\r\n  line two\n"))
+}
+
+func TestOptions_Padding(t *testing.T) {
+	assert.Equal(t, "   ", New().Padding(4, 6))
+	assert.Equal(t, "...", New(WithAlignChar('.')).Padding(4, 6))
+
+	// Tabs move the comment to the first tab stop past the longest line.
+	tabs := New(WithAlignChar('\t'))
+	assert.Equal(t, "\t", tabs.Padding(17, 17))
+	assert.Equal(t, "\t\t\t", tabs.Padding(7, 17))
+	assert.Equal(t, "\t", tabs.Padding(16, 16))
+	assert.Equal(t, "\t\t\t", tabs.Padding(0, 16))
+}
+
+func TestOptions_Finish(t *testing.T) {
+	assert.Equal(t, "", New().Finish(""))
+	assert.Equal(t, "", New().Finish("\n\n"))
+	assert.Equal(t, "a: 1\n", New().Finish("a: 1"))
+	assert.Equal(t, "a: 1\n", New().Finish("a: 1\n\n"))
+
+	omit := New(WithoutTrailingNewline())
+	assert.Equal(t, "a: 1\nb: 2", omit.Finish("a: 1\nb: 2\n"))
+	assert.Equal(t, "", omit.Finish("\n"))
+}
+
+func TestOptions_FinishHeaderFooter(t *testing.T) {
+	header := WithHeader("# Copyright Example\n# SPDX-License-Identifier: MIT")
+	assert.Equal(t, "# Copyright Example\n# SPDX-License-Identifier: MIT\na: 1\n", New(header).Finish("a: 1\n"))
+	assert.Equal(t, "# Banner\n\na: 1\n", New(WithHeader("# Banner\n\n")).Finish("a: 1"))
+	assert.Equal(t, "a: 1\n# Generated\n", New(WithFooter("# Generated\n\n")).Finish("a: 1\n"))
+	assert.Equal(t, "# H\na: 1\n\n# F", New(WithHeader("# H"), WithFooter("\n# F"), WithoutTrailingNewline()).Finish("a: 1\n"))
+	assert.Equal(t, "", New(header).Finish("\n"))
+}
+
+func TestOptions_CommentLines(t *testing.T) {
+	assert.Equal(t, []string{"  # Hostname"}, New().CommentLines("Hostname", "  "))
+	assert.Equal(t, []string{"# One", "#", "# two"}, New().CommentLines("One\r\n\ntwo\n", ""))
+	assert.Equal(t, []string{"## a b c", "## very-long-word"},
+		New(WithCommentPrefix("##"), WithCommentWidth(8)).CommentLines("a b c very-long-word", ""))
+}
+
+func TestOptions_InProfiles(t *testing.T) {
+	tag := reflect.StructTag(`profiles:"enterprise, cloud"`)
+	assert.True(t, New().InProfiles(tag))
+	assert.True(t, New(WithProfiles("cloud")).InProfiles(tag))
+	assert.True(t, New(WithProfiles("community", "enterprise")).InProfiles(tag))
+	assert.False(t, New(WithProfiles("community")).InProfiles(tag))
+	assert.True(t, New(WithProfiles("community")).InProfiles(`yaml:"host"`))
+}
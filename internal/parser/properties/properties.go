@@ -0,0 +1,227 @@
+package properties
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/vsysa/configo/internal/parser/options"
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// durationType is used to detect time.Duration fields, which would otherwise
+// be treated as plain int64 values.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// timeType is used to detect time.Time fields, which are structs but are
+// configured as a single RFC3339 string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// bytesType is used to detect []byte fields, which are configured as a
+// single string rather than a list of numbers.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// GeneratePropertiesTemplate generates a Java-style .properties template from
+// a given configuration struct.
+//
+// Nested keys are joined with dots (meta.version=1.0) and help texts are
+// emitted as "# help" comments above each line. Slices of primitives are
+// rendered as a comma-separated list, which is also the form the loader
+// accepts for a single value. Slices of structs are rendered with indexed
+// keys for one sample element (servers.0.host=...), maps with one sample
+// entry (labels.key=value). Fields without a default get an empty value,
+// secret fields are masked as "***".
+func GeneratePropertiesTemplate(cfg interface{}, opts ...options.Option) string {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return ""
+	}
+
+	g := &generator{opts: options.New(opts...)}
+	g.parseStructure(t, "", false)
+	return g.opts.Finish(g.builder.String())
+}
+
+// maskedValue replaces the value of fields tagged with `secret:"true"`.
+const maskedValue = "***"
+
+// recursiveAnnotation marks fields of a recursive type in comments.
+const recursiveAnnotation = "(recursive)"
+
+// generator holds the state of a single template generation.
+type generator struct {
+	opts    options.Options
+	builder strings.Builder
+
+	// expanding holds the struct types being rendered, to detect recursive types.
+	expanding walker.Expanding
+}
+
+// parseStructure writes the key/value lines of a struct, prefixing the keys
+// with the dotted path of the parent. With inSecret set every value is masked.
+func (g *generator) parseStructure(t reflect.Type, prefix string, inSecret bool) {
+	if g.expanding == nil {
+		g.expanding = walker.Expanding{}
+	}
+	g.expanding[t] = true
+	defer delete(g.expanding, t)
+
+	for _, field := range walker.Fields(t, nil) {
+		tag := field.Tag
+		fieldType := field.Type
+		key := prefix + escapeKey(field.Key)
+		defaultValue := field.Default
+		helpText := walker.HelpWithUnit(field.Help, field.Unit)
+		secret := inSecret || tag.Get("secret") == "true"
+		mask := func(value string) string {
+			if secret {
+				return maskedValue
+			}
+			return value
+		}
+
+		if fieldType == durationType {
+			if defaultValue == "" {
+				defaultValue = "0s"
+			}
+			g.writeLine(key, mask(defaultValue), helpText)
+			continue
+		}
+
+		if fieldType == timeType || fieldType == bytesType || walker.TextType(fieldType) {
+			g.writeLine(key, mask(defaultValue), helpText)
+			continue
+		}
+
+		// A template of a recursive type would never end, so a field referring
+		// back to a struct being rendered is only mentioned in a comment.
+		if g.expanding.Recursive(fieldType) {
+			g.writeComment(helpText)
+			g.writeComment(key + " " + recursiveAnnotation)
+			continue
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			// Nested sections are separated by an empty line.
+			if g.builder.Len() > 0 {
+				g.builder.WriteString("\n")
+			}
+			g.writeComment(helpText)
+			g.parseStructure(fieldType, key+".", secret)
+
+		case reflect.Slice:
+			elemType := fieldType.Elem()
+			for elemType.Kind() == reflect.Ptr {
+				elemType = elemType.Elem()
+			}
+			if elemType.Kind() == reflect.Struct {
+				g.writeComment(helpText)
+				g.parseStructure(elemType, key+".0.", secret)
+				continue
+			}
+			// Slices of maps show the sample entry of their first element.
+			if elemType.Kind() == reflect.Map {
+				exampleKey, exampleValue := getMapExample(tag, elemType.Key())
+				g.writeLine(key+".0."+escapeKey(exampleKey), mask(exampleValue), helpText)
+				continue
+			}
+			g.writeLine(key, mask(joinSliceDefault(defaultValue)), helpText)
+
+		case reflect.Map:
+			// For maps, we just show a sample key and value.
+			exampleKey, exampleValue := getMapExample(tag, fieldType.Key())
+			g.writeLine(key+"."+escapeKey(exampleKey), mask(exampleValue), helpText)
+
+		case reflect.Interface:
+			// Opaque fields (interface{} / any) take their value from the
+			// `example` tag, then from `default`.
+			value := tag.Get("example")
+			if value == "" {
+				value = defaultValue
+			}
+			g.writeLine(key, mask(value), helpText)
+
+		default:
+			g.writeLine(key, mask(defaultValue), helpText)
+		}
+	}
+}
+
+// writeLine writes a key/value pair preceded by its help comment.
+func (g *generator) writeLine(key, value, helpText string) {
+	g.writeComment(helpText)
+	g.builder.WriteString(key + "=" + escapeValue(value) + "\n")
+}
+
+// writeComment writes a help text as a comment line, one line per line of text.
+func (g *generator) writeComment(helpText string) {
+	if helpText == "" {
+		return
+	}
+	for _, line := range strings.Split(helpText, "\n") {
+		g.builder.WriteString(g.opts.Comment(line) + "\n")
+	}
+}
+
+// joinSliceDefault turns the default value of a slice into a comma-separated
+// list. JSON arrays are converted item by item; items that are not strings
+// keep their JSON form. A JSON array with items containing commas is kept
+// as is, since joining would change the number of items.
+func joinSliceDefault(value string) string {
+	if !strings.HasPrefix(strings.TrimSpace(value), "[") {
+		return value
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return value
+	}
+	items := make([]string, 0, len(raw))
+	for _, item := range raw {
+		var s string
+		if err := json.Unmarshal(item, &s); err == nil {
+			if strings.Contains(s, ",") {
+				return value
+			}
+			items = append(items, s)
+			continue
+		}
+		items = append(items, string(item))
+	}
+	return strings.Join(items, ",")
+}
+
+// escapeKey escapes the characters that would end a key in a properties file.
+func escapeKey(key string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, " ", `\ `, "=", `\=`, ":", `\:`, "#", `\#`, "!", `\!`)
+	return replacer.Replace(key)
+}
+
+// escapeValue escapes backslashes and line breaks, and a leading space that
+// would otherwise be dropped by the parser.
+func escapeValue(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	value = replacer.Replace(value)
+	if strings.HasPrefix(value, " ") {
+		value = `\` + value
+	}
+	return value
+}
+
+// getMapExample returns the sample key and value rendered for map fields,
+// customizable with the `example_key` and `example_value` tags. The default
+// key matches keyType, e.g. "1" for integer keys.
+func getMapExample(tag reflect.StructTag, keyType reflect.Type) (string, string) {
+	key, value := tag.Get("example_key"), tag.Get("example_value")
+	if key == "" {
+		key = walker.MapKeyExample(keyType)
+	}
+	if value == "" {
+		value = "value"
+	}
+	return key, value
+}
@@ -0,0 +1,176 @@
+package properties
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsysa/configo/internal/parser/options"
+)
+
+func TestGeneratePropertiesTemplate(t *testing.T) {
+	type Item struct {
+		Name  string `mapstructure:"name" default:"item1" help:"Item name"`
+		Value int    `mapstructure:"value"`
+	}
+	type Config struct {
+		Host    string        `mapstructure:"host" default:"localhost" help:"The hostname"`
+		Port    int           `mapstructure:"port" default:"8080" help:"The port number"`
+		Enabled bool          `mapstructure:"enabled"`
+		Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+		Options []int         `mapstructure:"options" default:"1,2,3" help:"List of options"`
+		Meta    struct {
+			Version string `mapstructure:"version" default:"1.0" help:"App version"`
+		} `mapstructure:"meta" help:"Meta section"`
+		MapField map[string]string `mapstructure:"map_field" help:"Example map field"`
+		Items    []Item            `mapstructure:"items"`
+	}
+
+	expected := `# The hostname
+host=localhost
+# The port number
+port=8080
+enabled=
+timeout=30s
+# List of options
+options=1,2,3
+
+# Meta section
+# App version
+meta.version=1.0
+# Example map field
+map_field.key=value
+# Item name
+items.0.name=item1
+items.0.value=
+`
+
+	assert.Equal(t, expected, GeneratePropertiesTemplate(&Config{}))
+}
+
+func TestGeneratePropertiesTemplate_Secret(t *testing.T) {
+	type Auth struct {
+		Token string `mapstructure:"token" default:"abc"`
+	}
+	cfg := struct {
+		User     string            `mapstructure:"user" default:"admin"`
+		Password string            `mapstructure:"password" default:"hunter2" secret:"true"`
+		Keys     []string          `mapstructure:"keys" default:"k1,k2" secret:"true"`
+		Headers  map[string]string `mapstructure:"headers" secret:"true"`
+		Auth     Auth              `mapstructure:"auth" secret:"true"`
+	}{}
+
+	expected := `user=admin
+password=***
+keys=***
+headers.key=***
+
+auth.token=***
+`
+
+	assert.Equal(t, expected, GeneratePropertiesTemplate(&cfg))
+}
+
+func TestGeneratePropertiesTemplate_SliceOfMaps(t *testing.T) {
+	cfg := struct {
+		Labels []map[string]string `mapstructure:"labels" help:"Label sets"`
+		Ports  []map[string]int    `mapstructure:"ports" example_key:"http" example_value:"80"`
+	}{}
+
+	expected := `# Label sets
+labels.0.key=value
+ports.0.http=80
+`
+
+	assert.Equal(t, expected, GeneratePropertiesTemplate(cfg))
+}
+
+// In .properties only lines starting with # are comments.
+func TestGeneratePropertiesTemplate_HashesAndColons(t *testing.T) {
+	cfg := struct {
+		Colon  string            `mapstructure:"colon" default:"a:b" help:"Format: host:port"`
+		Hash   string            `mapstructure:"hash" default:"has # hash" help:"See #3"`
+		Labels map[string]string `mapstructure:"labels" example_key:"a:b" example_value:"x # y"`
+	}{}
+
+	expected := `# Format: host:port
+colon=a:b
+# See #3
+hash=has # hash
+labels.a\:b=x # y
+`
+
+	assert.Equal(t, expected, GeneratePropertiesTemplate(cfg))
+}
+
+// Test the tag priority for key names and flattening of embedded structs.
+func TestGeneratePropertiesTemplate_KeyNames(t *testing.T) {
+	type Base struct {
+		Name string `default:"app"`
+	}
+	type Config struct {
+		Base
+		Host    string `yaml:"yaml_host" mapstructure:"ms_host" default:"h"`
+		Port    int    `json:"json_port" default:"1"`
+		Ignored string `mapstructure:"-" default:"x"`
+		hidden  string
+	}
+
+	expected := `name=app
+yaml_host=h
+json_port=1
+`
+
+	assert.Equal(t, expected, GeneratePropertiesTemplate(Config{}))
+}
+
+// Test escaping of keys and values and JSON slice defaults.
+func TestGeneratePropertiesTemplate_Escaping(t *testing.T) {
+	type Config struct {
+		Path   string            `mapstructure:"path" default:"C:\\temp"`
+		Lead   string            `mapstructure:"lead" default:" padded"`
+		Tags   []string          `mapstructure:"tags" default:"[\"a\", \"b\"]"`
+		Pairs  []string          `mapstructure:"pairs" default:"[\"a,b\", \"c\"]"`
+		Labels map[string]string `mapstructure:"labels" example_key:"app:name" example_value:"x=y"`
+	}
+
+	expected := `path=C:\\temp
+lead=\ padded
+tags=a,b
+pairs=["a,b", "c"]
+labels.app\:name=x=y
+`
+
+	assert.Equal(t, expected, GeneratePropertiesTemplate(Config{}))
+}
+
+func TestGeneratePropertiesTemplate_NotAStruct(t *testing.T) {
+	assert.Equal(t, "", GeneratePropertiesTemplate(42))
+}
+
+type treeNode struct {
+	Name     string     `yaml:"name" default:"root"`
+	Children []treeNode `yaml:"children" help:"Child nodes"`
+}
+
+func TestGeneratePropertiesTemplate_Recursive(t *testing.T) {
+	cfg := struct {
+		Tree treeNode `yaml:"tree"`
+	}{}
+
+	expected := "tree.name=root\n" +
+		"# Child nodes\n" +
+		"# tree.children (recursive)\n"
+
+	assert.Equal(t, expected, GeneratePropertiesTemplate(cfg))
+}
+
+func TestGeneratePropertiesTemplate_TrailingNewline(t *testing.T) {
+	cfg := struct {
+		Labels map[string]string `yaml:"labels"`
+	}{}
+
+	assert.Equal(t, "labels.key=value\n", GeneratePropertiesTemplate(cfg))
+	assert.Equal(t, "labels.key=value", GeneratePropertiesTemplate(cfg, options.WithoutTrailingNewline()))
+	assert.Equal(t, "", GeneratePropertiesTemplate(struct{}{}))
+}
@@ -0,0 +1,350 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// SchemaVersion is the JSON Schema dialect of the generated documents.
+const SchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// durationType is used to detect time.Duration fields, which are configured
+// as strings like "30s".
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// timeType is used to detect time.Time fields, which are structs but are
+// configured as a single RFC3339 string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFormats maps the names of the `format` tag to the JSON Schema formats.
+// Formats without a JSON Schema counterpart, like cidr, are left out.
+var schemaFormats = map[string]string{
+	"email":    "email",
+	"hostname": "hostname",
+	"uuid":     "uuid",
+	"url":      "uri",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+}
+
+// urlType is used to detect url.URL fields, which are documented as URI
+// references.
+var urlType = reflect.TypeOf(url.URL{})
+
+// bytesType is used to detect []byte fields, which are configured as a
+// single string rather than a list of numbers.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// GenerateJSONSchema generates a JSON Schema describing the config struct.
+//
+//   - help     => description, followed by the unit of the `unit` tag
+//   - default  => default (typed according to the field), left out for
+//     fields tagged with `secret:"true"` and everything nested under them
+//   - required => listed in the "required" array of the parent object
+//   - oneof    => enum
+//   - format   => format, e.g. "email" or "uri" for `format:"url"`
+//
+// Nested structs become object schemas, slices arrays and maps objects with
+// additionalProperties. Keys are sorted, so the output is deterministic.
+func GenerateJSONSchema(cfg interface{}) ([]byte, error) {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot generate schema for %T: not a struct", cfg)
+	}
+
+	root, err := objectSchema(t, walker.Expanding{})
+	if err != nil {
+		return nil, err
+	}
+	root["$schema"] = SchemaVersion
+
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// objectSchema builds the schema of a struct type. expanding holds the structs
+// being described: a recursive reference to one of them is described as a
+// plain object, since a schema of a recursive type would never end.
+func objectSchema(t reflect.Type, expanding walker.Expanding) (map[string]interface{}, error) {
+	if expanding[t] {
+		return map[string]interface{}{"type": "object"}, nil
+	}
+	expanding[t] = true
+	defer delete(expanding, t)
+
+	properties := make(map[string]interface{})
+	var required []string
+
+	if err := collectProperties(t, properties, &required, expanding); err != nil {
+		return nil, err
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// collectProperties adds the schema of every field of t to properties.
+func collectProperties(t reflect.Type, properties map[string]interface{}, required *[]string, expanding walker.Expanding) error {
+	for _, field := range walker.Fields(t, nil) {
+		fieldName := field.Key
+		tag := field.Tag
+		prop, err := fieldSchema(field.Type, tag, expanding)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fieldName, err)
+		}
+		// Secrets never echo their defaults.
+		if tag.Get("secret") == "true" {
+			omitDefaults(prop)
+		}
+		properties[fieldName] = prop
+
+		if isRequired(tag) {
+			*required = append(*required, fieldName)
+		}
+	}
+	return nil
+}
+
+// omitDefaults removes the defaults of a schema and of the schemas nested in
+// its properties, items and additionalProperties.
+func omitDefaults(schema map[string]interface{}) {
+	delete(schema, "default")
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for _, prop := range properties {
+			if prop, ok := prop.(map[string]interface{}); ok {
+				omitDefaults(prop)
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties"} {
+		if nested, ok := schema[key].(map[string]interface{}); ok {
+			omitDefaults(nested)
+		}
+	}
+}
+
+// fieldSchema builds the schema of a single field.
+func fieldSchema(t reflect.Type, tag reflect.StructTag, expanding walker.Expanding) (map[string]interface{}, error) {
+	schema, err := typeSchema(t, expanding)
+	if err != nil {
+		return nil, err
+	}
+
+	if help := walker.HelpWithUnit(getHelpText(tag), tag.Get("unit")); help != "" {
+		schema["description"] = help
+	}
+
+	// A custom layout is not an RFC3339 date-time.
+	if tag.Get("timeformat") != "" {
+		delete(schema, "format")
+	}
+	if format, ok := schemaFormats[tag.Get("format")]; ok && t.Kind() == reflect.String {
+		schema["format"] = format
+	}
+	if t == bytesType && tag.Get("encoding") == "base64" {
+		schema["contentEncoding"] = "base64"
+	}
+
+	// The `default:"enabled"` of a section only allocates it, its fields
+	// carry their own defaults.
+	if _, isSection := schema["properties"]; isSection {
+		return schema, nil
+	}
+
+	if defaultValue := getDefaultValue(tag); defaultValue != "" {
+		value, err := parseValue(t, defaultValue, walker.IntBase(tag))
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse default value %q: %w", defaultValue, err)
+		}
+		schema["default"] = value
+	}
+
+	if values := strings.Fields(tag.Get("oneof")); len(values) > 0 {
+		var enum []interface{}
+		for _, v := range values {
+			value, err := parseScalar(t, v, walker.IntBase(tag))
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse oneof value %q: %w", v, err)
+			}
+			enum = append(enum, value)
+		}
+		schema["enum"] = enum
+	}
+
+	return schema, nil
+}
+
+// typeSchema builds the schema describing a Go type.
+func typeSchema(t reflect.Type, expanding walker.Expanding) (map[string]interface{}, error) {
+	if t == durationType {
+		return map[string]interface{}{"type": "string"}, nil
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	}
+	if t == bytesType {
+		return map[string]interface{}{"type": "string"}, nil
+	}
+	if t == urlType {
+		return map[string]interface{}{"type": "string", "format": "uri-reference"}, nil
+	}
+	if walker.BinaryType(t) {
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+	}
+	if walker.TextType(t) {
+		return map[string]interface{}{"type": "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return objectSchema(t, expanding)
+
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(derefType(t.Elem()), expanding)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+
+	case reflect.Map:
+		values, err := typeSchema(derefType(t.Elem()), expanding)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+
+	case reflect.Interface:
+		// Any value is accepted.
+		return map[string]interface{}{}, nil
+
+	default:
+		name := jsonType(t.Kind())
+		if name == "" {
+			return nil, fmt.Errorf("unsupported type %s", t)
+		}
+		return map[string]interface{}{"type": name}, nil
+	}
+}
+
+// jsonType maps a primitive kind to its JSON Schema type name.
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return ""
+	}
+}
+
+// parseValue converts a default tag into a JSON value of the field type.
+// Slices accept a JSON array or a comma-separated list, maps a JSON object.
+func parseValue(t reflect.Type, value string, base int) (interface{}, error) {
+	if t == bytesType || walker.TextType(t) {
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		if strings.HasPrefix(value, "[") {
+			var items []interface{}
+			err := json.Unmarshal([]byte(value), &items)
+			return items, err
+		}
+		var items []interface{}
+		for _, item := range strings.Split(value, ",") {
+			parsed, err := parseScalar(derefType(t.Elem()), strings.TrimSpace(item), 10)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, parsed)
+		}
+		return items, nil
+
+	case reflect.Map:
+		var m map[string]interface{}
+		err := json.Unmarshal([]byte(value), &m)
+		return m, err
+
+	default:
+		return parseScalar(t, value, base)
+	}
+}
+
+// parseScalar converts a single primitive value according to the type kind.
+// Integers are written in base, see walker.IntBase.
+func parseScalar(t reflect.Type, value string, base int) (interface{}, error) {
+	if t == durationType {
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return walker.ParseInt(value, base, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return walker.ParseUint(value, base, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return value, nil
+	}
+}
+
+// derefType unwraps pointer types.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isRequired reports whether the field is marked as mandatory either with
+// `required:"true"` or with `validate:"required"`.
+func isRequired(tag reflect.StructTag) bool {
+	if tag.Get("required") == "true" {
+		return true
+	}
+	for _, rule := range strings.Split(tag.Get("validate"), ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// getDefaultValue extracts the default value from struct tags.
+func getDefaultValue(tag reflect.StructTag) string {
+	return tag.Get("default")
+}
+
+// getHelpText retrieves help (description) text from struct tags.
+func getHelpText(tag reflect.StructTag) string {
+	return tag.Get("help")
+}
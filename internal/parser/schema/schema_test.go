@@ -0,0 +1,186 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateJSONSchema(t *testing.T) {
+	type Server struct {
+		Host string `mapstructure:"host" default:"localhost" help:"Server host"`
+	}
+	type Config struct {
+		LogLevel string            `mapstructure:"log_level" default:"info" oneof:"debug info" help:"Log level"`
+		Port     int               `mapstructure:"port" default:"8080" required:"true"`
+		Ratio    float64           `mapstructure:"ratio"`
+		Timeout  time.Duration     `mapstructure:"timeout" default:"30s"`
+		Tags     []string          `mapstructure:"tags" default:"a,b"`
+		Labels   map[string]string `mapstructure:"labels"`
+		Server   *Server           `mapstructure:"server" validate:"required"`
+	}
+
+	out, err := GenerateJSONSchema(Config{})
+	require.NoError(t, err)
+
+	expected := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "properties": {
+    "labels": {
+      "additionalProperties": {
+        "type": "string"
+      },
+      "type": "object"
+    },
+    "log_level": {
+      "default": "info",
+      "description": "Log level",
+      "enum": [
+        "debug",
+        "info"
+      ],
+      "type": "string"
+    },
+    "port": {
+      "default": 8080,
+      "type": "integer"
+    },
+    "ratio": {
+      "type": "number"
+    },
+    "server": {
+      "properties": {
+        "host": {
+          "default": "localhost",
+          "description": "Server host",
+          "type": "string"
+        }
+      },
+      "type": "object"
+    },
+    "tags": {
+      "default": [
+        "a",
+        "b"
+      ],
+      "items": {
+        "type": "string"
+      },
+      "type": "array"
+    },
+    "timeout": {
+      "default": "30s",
+      "type": "string"
+    }
+  },
+  "required": [
+    "port",
+    "server"
+  ],
+  "type": "object"
+}
+`
+
+	assert.Equal(t, expected, string(out))
+}
+
+func TestGenerateJSONSchema_Errors(t *testing.T) {
+	_, err := GenerateJSONSchema(42)
+	assert.Error(t, err)
+
+	_, err = GenerateJSONSchema(struct {
+		Port int `mapstructure:"port" default:"abc"`
+	}{})
+	assert.ErrorContains(t, err, "port: cannot parse default value")
+}
+
+func TestGenerateJSONSchema_Recursive(t *testing.T) {
+	type Node struct {
+		Name     string `json:"name"`
+		Children []Node `json:"children"`
+		Next     *Node  `json:"next"`
+	}
+
+	out, err := GenerateJSONSchema(Node{})
+	require.NoError(t, err)
+
+	expected := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "properties": {
+    "children": {
+      "items": {
+        "type": "object"
+      },
+      "type": "array"
+    },
+    "name": {
+      "type": "string"
+    },
+    "next": {
+      "type": "object"
+    }
+  },
+  "type": "object"
+}
+`
+	assert.Equal(t, expected, string(out))
+}
+
+func TestGenerateJSONSchema_Unit(t *testing.T) {
+	out, err := GenerateJSONSchema(struct {
+		MaxUpload int `mapstructure:"max_upload" unit:"MB" help:"Max upload size"`
+		Timeout   int `mapstructure:"timeout" unit:"ms"`
+	}{})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), `"description": "Max upload size (MB)"`)
+	assert.Contains(t, string(out), `"description": "(ms)"`)
+}
+
+func TestGenerateJSONSchema_EnabledSection(t *testing.T) {
+	type TLS struct {
+		Cert string `mapstructure:"cert" default:"cert.pem"`
+	}
+	out, err := GenerateJSONSchema(struct {
+		TLS *TLS `mapstructure:"tls" default:"enabled"`
+	}{})
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(out), `"default": "enabled"`)
+	assert.Contains(t, string(out), `"default": "cert.pem"`)
+}
+
+func TestGenerateJSONSchema_Format(t *testing.T) {
+	out, err := GenerateJSONSchema(struct {
+		Contact  string `mapstructure:"contact" format:"email"`
+		Endpoint string `mapstructure:"endpoint" format:"url"`
+		Network  string `mapstructure:"network" format:"cidr"`
+	}{})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), `"format": "email"`)
+	assert.Contains(t, string(out), `"format": "uri"`)
+	assert.Equal(t, 2, strings.Count(string(out), `"format"`))
+}
+
+func TestGenerateJSONSchema_Secret(t *testing.T) {
+	type Auth struct {
+		Token string   `mapstructure:"token" default:"abc"`
+		Keys  []string `mapstructure:"keys" default:"k1,k2"`
+	}
+	out, err := GenerateJSONSchema(struct {
+		User string `mapstructure:"user" default:"admin"`
+		Pass string `mapstructure:"pass" default:"hunter2" secret:"true"`
+		Auth Auth   `mapstructure:"auth" secret:"true"`
+	}{})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), `"default": "admin"`)
+	assert.NotContains(t, string(out), "hunter2")
+	assert.NotContains(t, string(out), "abc")
+	assert.NotContains(t, string(out), "k1")
+	assert.Equal(t, 1, strings.Count(string(out), `"default"`))
+}
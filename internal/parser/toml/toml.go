@@ -0,0 +1,392 @@
+package toml
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/vsysa/configo/internal/parser/options"
+	"github.com/vsysa/configo/internal/parser/walker"
+	"golang.org/x/text/width"
+)
+
+// durationType is used to detect time.Duration fields, which would otherwise
+// be treated as plain int64 values.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// timeType is used to detect time.Time fields, which are structs but are
+// configured as a single RFC3339 string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// bytesType is used to detect []byte fields, which are configured as a
+// single string rather than a list of numbers.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// recursiveAnnotation marks fields of a recursive type in comments.
+const recursiveAnnotation = "(recursive)"
+
+// maskedValue replaces the value of fields tagged with `secret:"true"`.
+const maskedValue = `"***"`
+
+// bareKeyRegexp matches keys that can be written in TOML without quotes.
+var bareKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// fieldInfo represents a single line in the generated TOML template
+// along with an optional help (comment) text.
+type fieldInfo struct {
+	Line string
+	Help string
+}
+
+// GenerateTOMLTemplate generates a TOML template from a given configuration struct.
+//
+// Nested structs become [table] headers, slices of structs become [[array]]
+// tables and maps are rendered as inline tables. Since TOML has no null, fields
+// without a default are rendered with the zero value of their type. Values of
+// secret fields are masked as "***".
+func GenerateTOMLTemplate(cfg interface{}, withComments bool, opts ...options.Option) string {
+	var lines []fieldInfo
+
+	// First pass: Parse the struct and collect the lines
+	parseTable(reflect.TypeOf(cfg), nil, false, &lines, walker.Expanding{})
+
+	// Second pass: Align the resulting TOML lines with help comments
+	return generateTOMLWithAlignment(lines, withComments, options.New(opts...))
+}
+
+// table is a nested table which has to be rendered after all key/value pairs
+// of its parent.
+type table struct {
+	Path    []string
+	Type    reflect.Type
+	Help    string
+	IsArray bool
+	// Secret is set for the tables of secret fields, whose values are all
+	// masked.
+	Secret bool
+}
+
+// parseTable renders the key/value pairs of a struct and then recurses into
+// its nested tables, since TOML requires all plain keys of a table to come
+// before any sub-table. expanding holds the structs being rendered, to detect
+// recursive types. With secret set every value is masked.
+func parseTable(t reflect.Type, path []string, secret bool, lines *[]fieldInfo, expanding walker.Expanding) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	expanding[t] = true
+	defer delete(expanding, t)
+
+	var tables []table
+	parseFields(t, path, secret, lines, &tables, expanding)
+
+	for _, tbl := range tables {
+		header := "[" + strings.Join(tbl.Path, ".") + "]"
+		if tbl.IsArray {
+			header = "[" + header + "]"
+		}
+		if len(*lines) > 0 {
+			*lines = append(*lines, fieldInfo{})
+		}
+		*lines = append(*lines, fieldInfo{Line: header, Help: tbl.Help})
+		parseTable(tbl.Type, tbl.Path, tbl.Secret, lines, expanding)
+	}
+}
+
+// parseFields appends the key/value lines of a struct and collects its nested
+// tables. Fields referring back to a struct being rendered get an empty value
+// instead of a table, since a template of a recursive type would never end.
+// Secret fields, and all fields with inSecret set, never echo their defaults.
+func parseFields(t reflect.Type, path []string, inSecret bool, lines *[]fieldInfo, tables *[]table, expanding walker.Expanding) {
+	for _, field := range walker.Fields(t, nil) {
+		tag := field.Tag
+		fieldType := field.Type
+		fieldName := formatKey(field.Key)
+		defaultValue := field.Default
+		helpText := walker.HelpWithUnit(field.Help, field.Unit)
+		childPath := append(append([]string{}, path...), fieldName)
+		secret := inSecret || field.Tags["secret"] == "true"
+		mask := func(value string) string {
+			if secret {
+				return maskedValue
+			}
+			return value
+		}
+
+		if fieldType == durationType {
+			if defaultValue == "" {
+				defaultValue = "0s"
+			}
+			*lines = append(*lines, fieldInfo{
+				Line: fmt.Sprintf("%s = %s", fieldName, mask(strconv.Quote(defaultValue))),
+				Help: helpText,
+			})
+			continue
+		}
+
+		// TOML has no null, so time.Time fields without a default are
+		// rendered as the zero time.
+		if fieldType == timeType {
+			if defaultValue == "" {
+				defaultValue = time.Time{}.Format(time.RFC3339)
+			}
+			*lines = append(*lines, fieldInfo{
+				Line: fmt.Sprintf("%s = %s", fieldName, mask(strconv.Quote(defaultValue))),
+				Help: helpText,
+			})
+			continue
+		}
+
+		if expanding.Recursive(fieldType) {
+			value := "{}"
+			if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array {
+				value = "[]"
+			}
+			*lines = append(*lines, fieldInfo{
+				Line: fmt.Sprintf("%s = %s", fieldName, value),
+				Help: strings.TrimSpace(helpText + " " + recursiveAnnotation),
+			})
+			continue
+		}
+
+		// Like []byte fields, text types such as url.URL or net.IP are plain
+		// strings, empty without a default.
+		if fieldType == bytesType || walker.TextType(fieldType) {
+			*lines = append(*lines, fieldInfo{
+				Line: fmt.Sprintf("%s = %s", fieldName, mask(strconv.Quote(defaultValue))),
+				Help: helpText,
+			})
+			continue
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			*tables = append(*tables, table{Path: childPath, Type: fieldType, Help: helpText, Secret: secret})
+
+		case reflect.Slice:
+			elemType := fieldType.Elem()
+			for elemType.Kind() == reflect.Ptr {
+				elemType = elemType.Elem()
+			}
+			if elemType.Kind() == reflect.Struct {
+				*tables = append(*tables, table{Path: childPath, Type: elemType, Help: helpText, IsArray: true, Secret: secret})
+				continue
+			}
+			// Slices of maps show one inline table holding the sample entry.
+			if elemType.Kind() == reflect.Map {
+				*lines = append(*lines, fieldInfo{
+					Line: fmt.Sprintf("%s = [%s]", fieldName, inlineMap(tag, elemType, secret)),
+					Help: helpText,
+				})
+				continue
+			}
+
+			value := renderArray(elemType.Kind(), defaultValue)
+			if secret {
+				value = "[" + maskedValue + "]"
+			}
+			*lines = append(*lines, fieldInfo{
+				Line: fmt.Sprintf("%s = %s", fieldName, value),
+				Help: helpText,
+			})
+
+		case reflect.Map:
+			// For maps, we just show a sample key and value as an inline table.
+			*lines = append(*lines, fieldInfo{
+				Line: fmt.Sprintf("%s = %s", fieldName, inlineMap(tag, fieldType, secret)),
+				Help: helpText,
+			})
+
+		case reflect.Interface:
+			// Opaque fields (interface{} / any) take their value from the
+			// `example` tag, then from `default`.
+			value := tag.Get("example")
+			if value == "" {
+				value = defaultValue
+			}
+			*lines = append(*lines, fieldInfo{
+				Line: fmt.Sprintf("%s = %s", fieldName, mask(renderLiteral(value))),
+				Help: helpText,
+			})
+
+		default:
+			value, ok := walker.FormatInteger(fieldType.Kind(), defaultValue, walker.IntBase(tag), walker.TOML)
+			if !ok {
+				value = renderScalar(fieldType.Kind(), defaultValue)
+			}
+			*lines = append(*lines, fieldInfo{
+				Line: fmt.Sprintf("%s = %s", fieldName, mask(value)),
+				Help: helpText,
+			})
+		}
+	}
+}
+
+// inlineMap renders the sample entry of a map of type t as an inline table,
+// with the value masked if secret is set.
+func inlineMap(tag reflect.StructTag, t reflect.Type, secret bool) string {
+	exampleKey, exampleValue := getMapExample(tag, t.Key())
+	value := strconv.Quote(exampleValue)
+	if secret {
+		value = maskedValue
+	}
+	return fmt.Sprintf("{ %s = %s }", formatKey(exampleKey), value)
+}
+
+// renderArray renders a slice of primitives. The default value is either a
+// JSON array or a comma-separated list; without a default a sample item is
+// shown for string slices.
+func renderArray(kind reflect.Kind, defaultValue string) string {
+	if defaultValue == "" {
+		if kind == reflect.String {
+			return `["example"]`
+		}
+		return "[]"
+	}
+
+	// JSON strings, numbers and booleans are valid TOML values as well.
+	items, isJSON := splitSliceDefault(defaultValue)
+	if !isJSON {
+		for i, item := range items {
+			items[i] = renderScalar(kind, item)
+		}
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// renderLiteral renders a value of unknown type. Numbers, booleans, arrays
+// and inline tables are emitted as is, anything else is quoted as a string.
+func renderLiteral(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	if _, err := strconv.ParseBool(value); err == nil {
+		return value
+	}
+	if strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{") {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// renderScalar renders a primitive value, falling back to the zero value
+// of the kind when no default is provided. Bools and numbers are written as
+// native literals (see walker.FormatScalar), everything else is quoted.
+func renderScalar(kind reflect.Kind, value string) string {
+	if value == "" {
+		switch kind {
+		case reflect.String:
+			return `""`
+		case reflect.Bool:
+			return "false"
+		case reflect.Float32, reflect.Float64:
+			return "0.0"
+		default:
+			return "0"
+		}
+	}
+	if literal, ok := walker.FormatScalar(kind, value, walker.TOML); ok {
+		return literal
+	}
+	return strconv.Quote(value)
+}
+
+// generateTOMLWithAlignment aligns the generated TOML lines with
+// optional help comments on the right side.
+func generateTOMLWithAlignment(lines []fieldInfo, withComments bool, opts options.Options) string {
+	var builder strings.Builder
+	maxLength := 0
+
+	// Determine the maximum line length (without help text)
+	for _, line := range lines {
+		if w := displayWidth(line.Line); w > maxLength {
+			maxLength = w
+		}
+	}
+
+	// Write lines with alignment
+	for _, line := range lines {
+		builder.WriteString(line.Line)
+		if withComments && line.Help != "" {
+			builder.WriteString(opts.Padding(displayWidth(line.Line), maxLength))
+			builder.WriteString(opts.Comment(line.Help))
+		}
+		builder.WriteString("\n")
+	}
+
+	return opts.Finish(builder.String())
+}
+
+// formatKey quotes a key if it can't be written as a TOML bare key.
+func formatKey(key string) string {
+	if bareKeyRegexp.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// displayWidth returns the number of terminal columns the string occupies:
+// wide and fullwidth East Asian characters take two columns, combining
+// marks none.
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case isWide(r):
+			w += 2
+		default:
+			w++
+		}
+	}
+	return w
+}
+
+// isWide reports whether the rune is a wide or fullwidth East Asian character.
+func isWide(r rune) bool {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitSliceDefault splits the default value of a slice into its items.
+// A value starting with "[" is parsed as a JSON array and its items are
+// returned in JSON form, which allows items containing commas, e.g.
+// `default:"[\"a,b\", \"c\"]"`. Otherwise the value is split by commas.
+func splitSliceDefault(value string) (items []string, isJSON bool) {
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var raw []json.RawMessage
+		if err := json.Unmarshal([]byte(value), &raw); err == nil {
+			for _, item := range raw {
+				items = append(items, string(item))
+			}
+			return items, true
+		}
+	}
+	for _, item := range strings.Split(value, ",") {
+		items = append(items, strings.TrimSpace(item))
+	}
+	return items, false
+}
+
+// getMapExample returns the sample key and value rendered for map fields,
+// customizable with the `example_key` and `example_value` tags. The default
+// key matches keyType, e.g. "1" for integer keys.
+func getMapExample(tag reflect.StructTag, keyType reflect.Type) (string, string) {
+	key, value := tag.Get("example_key"), tag.Get("example_value")
+	if key == "" {
+		key = walker.MapKeyExample(keyType)
+	}
+	if value == "" {
+		value = "value"
+	}
+	return key, value
+}
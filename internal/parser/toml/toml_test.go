@@ -0,0 +1,287 @@
+package toml
+
+import (
+	"testing"
+	"time"
+
+	gotoml "github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vsysa/configo/internal/parser/options"
+)
+
+func TestGenerateTOMLTemplate(t *testing.T) {
+	type Item struct {
+		Name  string `mapstructure:"name" default:"item1" help:"Item name"`
+		Value int    `mapstructure:"value"`
+	}
+	type Config struct {
+		Host    string        `mapstructure:"host" default:"localhost" help:"The hostname"`
+		Port    int           `mapstructure:"port" default:"8080" help:"The port number"`
+		Enabled bool          `mapstructure:"enabled"`
+		Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+		Options []int         `mapstructure:"options" default:"1,2,3" help:"List of options"`
+		Meta    struct {
+			Version string `mapstructure:"version" default:"1.0" help:"App version"`
+		} `mapstructure:"meta" help:"Meta section"`
+		MapField map[string]string `mapstructure:"map_field" help:"Example map field"`
+		Items    []Item            `mapstructure:"items"`
+	}
+	tomlTemplate := GenerateTOMLTemplate(Config{}, true)
+
+	expected := `host = "localhost"            # The hostname
+port = 8080                   # The port number
+enabled = false
+timeout = "30s"
+options = [1, 2, 3]           # List of options
+map_field = { key = "value" } # Example map field
+
+[meta]                        # Meta section
+version = "1.0"               # App version
+
+[[items]]
+name = "item1"                # Item name
+value = 0
+`
+
+	assert.Equal(t, expected, tomlTemplate)
+
+	var parsed map[string]interface{}
+	require.NoError(t, gotoml.Unmarshal([]byte(tomlTemplate), &parsed))
+}
+
+// Test TOML generation of deeper nested tables without comments.
+func TestGenerateTOMLTemplate_NestedTables(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Host string `yaml:"host" default:"0.0.0.0" help:"Server host"`
+			TLS  *struct {
+				Cert string `yaml:"cert"`
+			} `yaml:"tls"`
+		} `yaml:"server"`
+		Tags []string `yaml:"tags"`
+	}
+	tomlTemplate := GenerateTOMLTemplate(Config{}, false)
+
+	expected := `tags = ["example"]
+
+[server]
+host = "0.0.0.0"
+
+[server.tls]
+cert = ""
+`
+
+	assert.Equal(t, expected, tomlTemplate)
+
+	var parsed map[string]interface{}
+	require.NoError(t, gotoml.Unmarshal([]byte(tomlTemplate), &parsed))
+}
+
+// Test TOML generation with custom map examples.
+func TestGenerateTOMLTemplate_MapExampleTags(t *testing.T) {
+	cfg := struct {
+		Regions map[string]string `yaml:"regions" example_key:"region" example_value:"us-east-1"`
+	}{}
+
+	assert.Equal(t, "regions = { region = \"us-east-1\" }\n", GenerateTOMLTemplate(cfg, false))
+}
+
+func TestGenerateTOMLTemplate_NonStringMapKeys(t *testing.T) {
+	cfg := struct {
+		Priorities map[int]string `toml:"priorities"`
+	}{}
+
+	assert.Equal(t, "priorities = { 1 = \"value\" }\n", GenerateTOMLTemplate(cfg, false))
+}
+
+func TestGenerateTOMLTemplate_SliceOfMaps(t *testing.T) {
+	cfg := struct {
+		Labels  []map[string]string `toml:"labels" help:"Label sets"`
+		Weights []map[string]int    `toml:"weights" example_key:"canary"`
+	}{}
+	tomlTemplate := GenerateTOMLTemplate(cfg, true)
+
+	assert.Equal(t, "labels = [{ key = \"value\" }]     # Label sets\nweights = [{ canary = \"value\" }]\n", tomlTemplate)
+
+	var parsed map[string]interface{}
+	require.NoError(t, gotoml.Unmarshal([]byte(tomlTemplate), &parsed))
+}
+
+func TestGenerateTOMLTemplate_HashesAndColons(t *testing.T) {
+	cfg := struct {
+		Colon  string            `toml:"colon" default:"a:b" help:"Format: host:port"`
+		Hash   string            `toml:"hash" default:"has # hash" help:"See #3"`
+		Labels map[string]string `toml:"labels" example_key:"a:b" example_value:"x # y"`
+	}{}
+	tomlTemplate := GenerateTOMLTemplate(cfg, true)
+
+	var parsed struct {
+		Colon  string            `toml:"colon"`
+		Hash   string            `toml:"hash"`
+		Labels map[string]string `toml:"labels"`
+	}
+	require.NoError(t, gotoml.Unmarshal([]byte(tomlTemplate), &parsed), tomlTemplate)
+	assert.Equal(t, "a:b", parsed.Colon)
+	assert.Equal(t, "has # hash", parsed.Hash)
+	assert.Equal(t, map[string]string{"a:b": "x # y"}, parsed.Labels)
+}
+
+func TestGenerateTOMLTemplate_JSONSliceDefault(t *testing.T) {
+	cfg := struct {
+		Queries []string `toml:"queries" default:"[\"a,b\", \"c\"]"`
+		Ports   []int    `toml:"ports" default:"[80, 443]"`
+	}{}
+	tomlTemplate := GenerateTOMLTemplate(cfg, false)
+
+	var parsed struct {
+		Queries []string `toml:"queries"`
+		Ports   []int    `toml:"ports"`
+	}
+	require.NoError(t, gotoml.Unmarshal([]byte(tomlTemplate), &parsed))
+	assert.Equal(t, []string{"a,b", "c"}, parsed.Queries)
+	assert.Equal(t, []int{80, 443}, parsed.Ports)
+}
+
+func TestGenerateTOMLTemplate_WideCharacters(t *testing.T) {
+	cfg := struct {
+		Name string `toml:"name" default:"東京" help:"都市名"`
+		Port int    `toml:"port" default:"8080" help:"Port"`
+	}{}
+
+	expected := `name = "東京" # 都市名
+port = 8080   # Port
+`
+
+	assert.Equal(t, expected, GenerateTOMLTemplate(cfg, true))
+}
+
+type OrderFirst struct {
+	B string `toml:"b"`
+	C string `toml:"c"`
+}
+
+type OrderSecond struct {
+	E string `toml:"e"`
+}
+
+// Test that flattened embedded fields keep the position of the embedded field.
+// Tables always follow the plain keys, in declaration order.
+func TestGenerateTOMLTemplate_FieldOrder(t *testing.T) {
+	cfg := struct {
+		A string `toml:"a"`
+		OrderFirst
+		Y struct{} `toml:"y"`
+		D string   `toml:"d"`
+		*OrderSecond
+		X struct{} `toml:"x"`
+	}{}
+
+	expected := `a = ""
+b = ""
+c = ""
+d = ""
+
+[y]
+
+[ordersecond]
+e = ""
+
+[x]
+`
+
+	assert.Equal(t, expected, GenerateTOMLTemplate(cfg, false))
+}
+
+func TestGenerateTOMLTemplate_Interface(t *testing.T) {
+	cfg := struct {
+		Retries any `toml:"retries" example:"3"`
+		Name    any `toml:"name" example:"anything"`
+		Value   any `toml:"value"`
+	}{}
+
+	expected := `retries = 3
+name = "anything"
+value = ""
+`
+
+	assert.Equal(t, expected, GenerateTOMLTemplate(cfg, false))
+}
+
+// Test a custom comment prefix and alignment character.
+func TestGenerateTOMLTemplate_CommentStyle(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+		Port int    `yaml:"port" default:"8080" help:"The port"`
+	}{}
+
+	expected := `host = "localhost".;; The hostname
+port = 8080........;; The port
+`
+
+	assert.Equal(t, expected, GenerateTOMLTemplate(cfg, true, options.WithCommentPrefix(";;"), options.WithAlignChar('.')))
+}
+
+type treeNode struct {
+	Name     string     `toml:"name" default:"root"`
+	Children []treeNode `toml:"children" help:"Child nodes"`
+	Next     *treeNode  `toml:"next"`
+}
+
+// Test that a recursive type is rendered one level deep.
+func TestGenerateTOMLTemplate_Recursive(t *testing.T) {
+	cfg := struct {
+		Tree treeNode `toml:"tree"`
+	}{}
+
+	expected := `[tree]
+name = "root"
+children = [] # Child nodes (recursive)
+next = {}     # (recursive)
+`
+
+	assert.Equal(t, expected, GenerateTOMLTemplate(cfg, true))
+}
+
+// Test the trailing newline of TOML templates.
+func TestGenerateTOMLTemplate_TrailingNewline(t *testing.T) {
+	type Server struct {
+		Host string `toml:"host" default:"localhost"`
+	}
+	cfg := struct {
+		Name   string `toml:"name" default:"app"`
+		Server Server `toml:"server"`
+	}{}
+
+	template := GenerateTOMLTemplate(cfg, true)
+	assert.Equal(t, "name = \"app\"\n\n[server]\nhost = \"localhost\"\n", template)
+	assert.Equal(t, "name = \"app\"\n\n[server]\nhost = \"localhost\"", GenerateTOMLTemplate(cfg, true, options.WithoutTrailingNewline()))
+
+	assert.Equal(t, "", GenerateTOMLTemplate(struct{}{}, true))
+}
+
+func TestGenerateTOMLTemplate_Secret(t *testing.T) {
+	type credentials struct {
+		User string `toml:"user" default:"admin"`
+	}
+	cfg := struct {
+		Host     string            `toml:"host" default:"localhost"`
+		Password string            `toml:"password" default:"hunter2" secret:"true"`
+		Timeout  time.Duration     `toml:"timeout" default:"5s" secret:"true"`
+		Tokens   []string          `toml:"tokens" default:"a,b" secret:"true"`
+		Headers  map[string]string `toml:"headers" secret:"true"`
+		Auth     credentials       `toml:"auth" secret:"true"`
+	}{}
+
+	expected := `host = "localhost"
+password = "***"
+timeout = "***"
+tokens = ["***"]
+headers = { key = "***" }
+
+[auth]
+user = "***"
+`
+
+	assert.Equal(t, expected, GenerateTOMLTemplate(cfg, false))
+}
@@ -0,0 +1,117 @@
+package walker
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// IntBase returns the base the value of an integer field is written in: 8
+// for fields tagged with `format:"octal"`, 16 for `format:"hex"` and 10
+// otherwise.
+func IntBase(tag reflect.StructTag) int {
+	switch tag.Get("format") {
+	case "octal":
+		return 8
+	case "hex":
+		return 16
+	default:
+		return 10
+	}
+}
+
+// ParseInt parses s as a signed integer in base, like strconv.ParseInt. The
+// digits may follow a sign and the prefix of the base: "0x" for hex, "0o" or
+// "0" for octal, e.g. "0644", "0o644" and "644" are all 420 in base 8.
+func ParseInt(s string, base, bitSize int) (int64, error) {
+	sign, digits := splitSign(s)
+	return strconv.ParseInt(sign+trimBasePrefix(digits, base), base, bitSize)
+}
+
+// ParseUint works like ParseInt for unsigned integers.
+func ParseUint(s string, base, bitSize int) (uint64, error) {
+	sign, digits := splitSign(s)
+	if sign == "-" {
+		// Leave the sign in for strconv to reject it.
+		digits = s
+	}
+	return strconv.ParseUint(trimBasePrefix(digits, base), base, bitSize)
+}
+
+// FormatInteger renders the default value of an integer field of kind in
+// base as a literal of format, see FormatInt.
+//
+// It returns false in base 10, for other kinds and for values that don't
+// parse, which the generator renders as usual.
+func FormatInteger(kind reflect.Kind, value string, base int, format Format) (string, bool) {
+	if base == 10 {
+		return "", false
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := ParseInt(value, base, 64)
+		if err != nil {
+			return "", false
+		}
+		return FormatInt(n, base, format), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := ParseUint(value, base, 64)
+		if err != nil {
+			return "", false
+		}
+		return FormatUint(n, base, format), true
+	default:
+		return "", false
+	}
+}
+
+// FormatInt writes n in base 8 or 16 as a literal of format: 0644 and 0x1F
+// in YAML, 0o644 and 0x1F in TOML. JSON has no such literals and TOML none
+// for negative numbers, so they get the decimal number instead.
+func FormatInt(n int64, base int, format Format) string {
+	if n >= 0 {
+		return FormatUint(uint64(n), base, format)
+	}
+	if format == TOML {
+		return strconv.FormatInt(n, 10)
+	}
+	return "-" + FormatUint(uint64(-n), base, format)
+}
+
+// FormatUint works like FormatInt for unsigned integers.
+func FormatUint(n uint64, base int, format Format) string {
+	digits := strconv.FormatUint(n, base)
+	switch {
+	case format == JSON || base != 8 && base != 16:
+		return strconv.FormatUint(n, 10)
+	case base == 16:
+		return "0x" + strings.ToUpper(digits)
+	case format == TOML:
+		return "0o" + digits
+	case n == 0:
+		return digits
+	default:
+		return "0" + digits
+	}
+}
+
+// splitSign splits an optional leading sign off s.
+func splitSign(s string) (sign, digits string) {
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return s[:1], s[1:]
+	}
+	return "", s
+}
+
+// trimBasePrefix removes the prefix of base from digits.
+func trimBasePrefix(digits string, base int) string {
+	lower := strings.ToLower(digits)
+	switch {
+	case base == 16 && strings.HasPrefix(lower, "0x"):
+		return digits[2:]
+	case base == 8 && strings.HasPrefix(lower, "0o"):
+		return digits[2:]
+	default:
+		return digits
+	}
+}
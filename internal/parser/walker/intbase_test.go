@@ -0,0 +1,104 @@
+package walker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntBase(t *testing.T) {
+	assert.Equal(t, 8, IntBase(`format:"octal"`))
+	assert.Equal(t, 16, IntBase(`format:"hex"`))
+	assert.Equal(t, 10, IntBase(`format:"email"`))
+	assert.Equal(t, 10, IntBase(``))
+}
+
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		value string
+		base  int
+		want  int64
+		ok    bool
+	}{
+		{"0644", 8, 420, true},
+		{"0o644", 8, 420, true},
+		{"0O644", 8, 420, true},
+		{"644", 8, 420, true},
+		{"-0644", 8, -420, true},
+		{"0", 8, 0, true},
+		{"0x1F", 16, 31, true},
+		{"0X1f", 16, 31, true},
+		{"1F", 16, 31, true},
+		{"+0x10", 16, 16, true},
+		{"-0x1F", 16, -31, true},
+		{"0x1F", 8, 0, false},
+		{"0o7", 16, 0, false},
+		{"9", 8, 0, false},
+		{"0x", 16, 0, false},
+		{"", 16, 0, false},
+		{"42", 10, 42, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseInt(tt.value, tt.base, 64)
+		if tt.ok {
+			assert.NoError(t, err, tt.value)
+			assert.Equal(t, tt.want, got, tt.value)
+		} else {
+			assert.Error(t, err, tt.value)
+		}
+	}
+
+	_, err := ParseInt("0x1FF", 16, 8)
+	assert.Error(t, err, "out of range for int8")
+}
+
+func TestParseUint(t *testing.T) {
+	n, err := ParseUint("0755", 8, 32)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(493), n)
+
+	n, err = ParseUint("0xFFFF", 16, 16)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(65535), n)
+
+	_, err = ParseUint("-0x1", 16, 64)
+	assert.Error(t, err)
+	_, err = ParseUint("0x10000", 16, 16)
+	assert.Error(t, err)
+}
+
+func TestFormatInteger(t *testing.T) {
+	tests := []struct {
+		kind   reflect.Kind
+		value  string
+		base   int
+		format Format
+		want   string
+		ok     bool
+	}{
+		{reflect.Uint32, "0644", 8, YAML, "0644", true},
+		{reflect.Uint32, "644", 8, YAML, "0644", true},
+		{reflect.Uint32, "0o644", 8, TOML, "0o644", true},
+		{reflect.Uint32, "0644", 8, JSON, "420", true},
+		{reflect.Int, "0", 8, YAML, "0", true},
+		{reflect.Int, "0", 8, TOML, "0o0", true},
+		{reflect.Int, "-0644", 8, YAML, "-0644", true},
+		{reflect.Int, "-0644", 8, TOML, "-420", true},
+		{reflect.Int, "0x1f", 16, YAML, "0x1F", true},
+		{reflect.Uint8, "1F", 16, TOML, "0x1F", true},
+		{reflect.Int64, "0x1F", 16, JSON, "31", true},
+		{reflect.Int, "-0x1F", 16, JSON, "-31", true},
+		{reflect.Int, "8080", 10, YAML, "", false},
+		{reflect.String, "0644", 8, YAML, "", false},
+		{reflect.Int, "0xZZ", 16, YAML, "", false},
+		{reflect.Uint, "-1", 16, YAML, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := FormatInteger(tt.kind, tt.value, tt.base, tt.format)
+		assert.Equal(t, tt.ok, ok, "%s %q base %d", tt.kind, tt.value, tt.base)
+		assert.Equal(t, tt.want, got, "%s %q base %d", tt.kind, tt.value, tt.base)
+	}
+}
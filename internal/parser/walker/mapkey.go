@@ -0,0 +1,45 @@
+package walker
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MapKeyExample returns the sample key of a map with keys of type t, used by
+// the generators when the `example_key` tag is empty: "1" for numbers,
+// "true" for bools and "key" for strings.
+func MapKeyExample(t reflect.Type) string {
+	switch deref(t).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "1"
+	case reflect.Bool:
+		return "true"
+	default:
+		return "key"
+	}
+}
+
+// CheckMapKeys returns an error if t, or a slice, array, pointer or map type
+// it is made of, is a map whose keys can't be written as config keys.
+// Config files key maps by strings, which the loader parses into string,
+// bool and number keys; other key types like structs are rejected.
+func CheckMapKeys(t reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return CheckMapKeys(t.Elem())
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return CheckMapKeys(t.Elem())
+		default:
+			return fmt.Errorf("unsupported map key type %s in %s: keys must be strings, bools or numbers", t.Key(), t)
+		}
+	default:
+		return nil
+	}
+}
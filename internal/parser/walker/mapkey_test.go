@@ -0,0 +1,29 @@
+package walker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapKeyExample(t *testing.T) {
+	assert.Equal(t, "key", MapKeyExample(reflect.TypeOf("")))
+	assert.Equal(t, "1", MapKeyExample(reflect.TypeOf(0)))
+	assert.Equal(t, "1", MapKeyExample(reflect.TypeOf(uint8(0))))
+	assert.Equal(t, "true", MapKeyExample(reflect.TypeOf(false)))
+}
+
+func TestCheckMapKeys(t *testing.T) {
+	type key struct{ A int }
+
+	assert.NoError(t, CheckMapKeys(reflect.TypeOf(map[string]string{})))
+	assert.NoError(t, CheckMapKeys(reflect.TypeOf(map[int]string{})))
+	assert.NoError(t, CheckMapKeys(reflect.TypeOf([]map[uint16]map[bool]float64{})))
+	assert.NoError(t, CheckMapKeys(reflect.TypeOf(0)))
+
+	assert.EqualError(t, CheckMapKeys(reflect.TypeOf(map[key]int{})),
+		"unsupported map key type walker.key in map[walker.key]int: keys must be strings, bools or numbers")
+	assert.Error(t, CheckMapKeys(reflect.TypeOf(&map[string]map[*int]string{})))
+	assert.Error(t, CheckMapKeys(reflect.TypeOf(map[[2]int]string{})))
+}
@@ -0,0 +1,104 @@
+package walker
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format is a config file format template values are rendered for.
+type Format int
+
+const (
+	YAML Format = iota
+	JSON
+	TOML
+)
+
+// decimalNumber matches numbers that are valid literals as they are in YAML,
+// JSON and TOML alike.
+var decimalNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// FormatScalar renders the default value of a bool or number field as a
+// native literal of format, so that every generator writes `8080` and `true`
+// unquoted. The value is parsed the way the `default` tag is parsed:
+// strconv.ParseBool for bools, strconv.ParseInt and ParseUint in base 10 for
+// integers, strconv.ParseFloat for floats. The literal is then written in a
+// form every format accepts, e.g. "+08" becomes 8 and "TRUE" becomes true;
+// TOML floats always get a fraction or exponent.
+//
+// It returns false for other kinds and for values that don't parse, which the
+// generator renders as a string instead.
+func FormatScalar(kind reflect.Kind, value string, format Format) (string, bool) {
+	switch kind {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatBool(b), true
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatInt(n, 10), true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatUint(n, 10), true
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", false
+		}
+		return formatFloat(value, f, format)
+
+	default:
+		return "", false
+	}
+}
+
+// formatFloat renders the parsed float f of value. Decimal numbers keep
+// their text, e.g. "1.50".
+func formatFloat(value string, f float64, format Format) (string, bool) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nonFinite(f, format)
+	}
+
+	literal := value
+	if !decimalNumber.MatchString(value) {
+		literal = strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	// In TOML "1" is an integer, the float is "1.0".
+	if format == TOML && !strings.ContainsAny(literal, ".eE") {
+		literal += ".0"
+	}
+	return literal, true
+}
+
+// nonFinite renders infinities and NaN, which JSON has no literal for.
+func nonFinite(f float64, format Format) (string, bool) {
+	literals := map[Format][3]string{
+		YAML: {".inf", "-.inf", ".nan"},
+		TOML: {"inf", "-inf", "nan"},
+	}
+	l, ok := literals[format]
+	switch {
+	case !ok:
+		return "", false
+	case math.IsInf(f, 1):
+		return l[0], true
+	case math.IsInf(f, -1):
+		return l[1], true
+	default:
+		return l[2], true
+	}
+}
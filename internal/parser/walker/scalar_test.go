@@ -0,0 +1,48 @@
+package walker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatScalar(t *testing.T) {
+	tests := []struct {
+		kind   reflect.Kind
+		value  string
+		format Format
+		want   string
+		ok     bool
+	}{
+		{reflect.Bool, "true", YAML, "true", true},
+		{reflect.Bool, "TRUE", JSON, "true", true},
+		{reflect.Bool, "0", TOML, "false", true},
+		{reflect.Bool, "yes", JSON, "", false},
+		{reflect.Int, "8080", JSON, "8080", true},
+		{reflect.Int64, "+08", JSON, "8", true},
+		{reflect.Int64, "0x10", JSON, "", false},
+		{reflect.Int, "-5", YAML, "-5", true},
+		{reflect.Int, "1.5", JSON, "", false},
+		{reflect.Int64, "30s", YAML, "", false},
+		{reflect.Uint, "42", TOML, "42", true},
+		{reflect.Uint, "-1", JSON, "", false},
+		{reflect.Float64, "0.50", JSON, "0.50", true},
+		{reflect.Float64, "1", YAML, "1", true},
+		{reflect.Float64, "1", TOML, "1.0", true},
+		{reflect.Float64, "1e6", TOML, "1e6", true},
+		{reflect.Float32, ".5", JSON, "0.5", true},
+		{reflect.Float64, "+2", TOML, "2.0", true},
+		{reflect.Float64, "Inf", YAML, ".inf", true},
+		{reflect.Float64, "-Inf", TOML, "-inf", true},
+		{reflect.Float64, "NaN", JSON, "", false},
+		{reflect.Float64, "half", YAML, "", false},
+		{reflect.String, "8080", JSON, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := FormatScalar(tt.kind, tt.value, tt.format)
+		assert.Equal(t, tt.ok, ok, "%s %q", tt.kind, tt.value)
+		assert.Equal(t, tt.want, got, "%s %q", tt.kind, tt.value)
+	}
+}
@@ -0,0 +1,129 @@
+package walker
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TypeHooks convert a registered type from and to its string form.
+type TypeHooks struct {
+	// Parse converts the string form to a value of the type, or a pointer
+	// to one.
+	Parse func(string) (interface{}, error)
+	// Render converts a value of the type to its string form.
+	Render func(interface{}) string
+}
+
+var (
+	customTypesMu sync.RWMutex
+	customTypes   = map[reflect.Type]TypeHooks{}
+)
+
+// RegisterType registers the hooks of a type configured by its string form,
+// replacing the hooks registered for t before. A nil Render falls back to
+// fmt.Sprint.
+func RegisterType(t reflect.Type, hooks TypeHooks) {
+	if hooks.Render == nil {
+		hooks.Render = func(v interface{}) string { return fmt.Sprint(v) }
+	}
+
+	customTypesMu.Lock()
+	defer customTypesMu.Unlock()
+	customTypes[t] = hooks
+}
+
+// CustomType returns the hooks registered for t with RegisterType, or for a
+// BinaryType hooks converting its binary form from and to base64.
+func CustomType(t reflect.Type) (TypeHooks, bool) {
+	if hooks, ok := registeredType(t); ok {
+		return hooks, true
+	}
+	if BinaryType(t) {
+		return binaryHooks(t), true
+	}
+	return TypeHooks{}, false
+}
+
+// registeredType returns the hooks registered for t with RegisterType.
+func registeredType(t reflect.Type) (TypeHooks, bool) {
+	customTypesMu.RLock()
+	defer customTypesMu.RUnlock()
+	hooks, ok := customTypes[t]
+	return hooks, ok
+}
+
+var (
+	binaryMarshalerType   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	textMarshalerType     = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// BinaryType reports whether t is configured as the base64 string of its
+// binary form, e.g. a crypto key: t (or *t) implements both
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler but neither of
+// their text counterparts, and is neither registered with RegisterType nor
+// a standard type like url.URL. Text marshaling, when available, is used
+// instead.
+func BinaryType(t reflect.Type) bool {
+	if t == nil || t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface || textTypes[t] {
+		return false
+	}
+	if _, ok := registeredType(t); ok {
+		return false
+	}
+	p := reflect.PointerTo(t)
+	if p.Implements(textMarshalerType) || p.Implements(textUnmarshalerType) {
+		return false
+	}
+	return p.Implements(binaryMarshalerType) && p.Implements(binaryUnmarshalerType)
+}
+
+// binaryHooks returns the hooks of a BinaryType: Parse decodes the standard
+// base64 encoding of the binary form and Render encodes it. A value whose
+// MarshalBinary fails is rendered as an empty string.
+func binaryHooks(t reflect.Type) TypeHooks {
+	return TypeHooks{
+		Parse: func(s string) (interface{}, error) {
+			data, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode %q as base64: %w", s, err)
+			}
+			p := reflect.New(t)
+			if err := p.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err != nil {
+				return nil, err
+			}
+			return p.Interface(), nil
+		},
+		Render: func(v interface{}) string {
+			p := reflect.New(t)
+			p.Elem().Set(reflect.ValueOf(v))
+			data, err := p.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				return ""
+			}
+			return base64.StdEncoding.EncodeToString(data)
+		},
+	}
+}
+
+// TextString returns the string form of a value of a TextType: the Render
+// hook of a registered type, or the String method of a standard type. The
+// zero value of a standard type is an empty string.
+func TextString(v reflect.Value) string {
+	if hooks, ok := CustomType(v.Type()); ok {
+		return hooks.Render(v.Interface())
+	}
+	if v.IsZero() {
+		return ""
+	}
+	// url.URL and net.IPNet implement fmt.Stringer on their pointers.
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	if s, ok := p.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,107 @@
+package walker
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type walkMoney struct {
+	Cents int
+}
+
+func TestRegisterType(t *testing.T) {
+	moneyType := reflect.TypeOf(walkMoney{})
+	_, ok := CustomType(moneyType)
+	assert.False(t, ok)
+
+	RegisterType(moneyType, TypeHooks{
+		Parse: func(s string) (interface{}, error) { return walkMoney{}, nil },
+	})
+	hooks, ok := CustomType(moneyType)
+	require.True(t, ok)
+	assert.True(t, TextType(moneyType))
+	assert.False(t, TextType(reflect.PointerTo(moneyType)))
+
+	// Without a Render hook the value is rendered with fmt.Sprint.
+	assert.Equal(t, "{250}", hooks.Render(walkMoney{Cents: 250}))
+
+	type Config struct {
+		Price walkMoney `mapstructure:"price"`
+	}
+	var keys []string
+	require.NoError(t, Walk(reflect.TypeOf(Config{}), func(f Field) error {
+		keys = append(keys, f.BindKey)
+		return nil
+	}))
+	assert.Equal(t, []string{"price"}, keys)
+}
+
+func TestTextString(t *testing.T) {
+	type price struct{ Cents int }
+	RegisterType(reflect.TypeOf(price{}), TypeHooks{
+		Parse:  func(s string) (interface{}, error) { return price{}, nil },
+		Render: func(v interface{}) string { return fmt.Sprintf("$%.2f", float64(v.(price).Cents)/100) },
+	})
+
+	u, _ := url.Parse("https://example.com/api")
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+
+	assert.Equal(t, "$4.99", TextString(reflect.ValueOf(price{Cents: 499})))
+	assert.Equal(t, "https://example.com/api", TextString(reflect.ValueOf(*u)))
+	assert.Equal(t, "10.0.0.0/8", TextString(reflect.ValueOf(*network)))
+	assert.Equal(t, "", TextString(reflect.ValueOf(url.URL{})))
+}
+
+// walkKey implements only binary marshaling.
+type walkKey struct{ data []byte }
+
+func (k walkKey) MarshalBinary() ([]byte, error) { return k.data, nil }
+
+func (k *walkKey) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty key")
+	}
+	k.data = data
+	return nil
+}
+
+func TestBinaryType(t *testing.T) {
+	keyType := reflect.TypeOf(walkKey{})
+	assert.True(t, BinaryType(keyType))
+	assert.True(t, TextType(keyType))
+	assert.False(t, BinaryType(reflect.PointerTo(keyType)))
+	assert.False(t, SectionPointer(reflect.PointerTo(keyType)))
+
+	// Text marshaling and the standard types take precedence.
+	assert.False(t, BinaryType(reflect.TypeOf(time.Time{})))
+	assert.False(t, BinaryType(reflect.TypeOf(url.URL{})))
+	assert.False(t, BinaryType(reflect.TypeOf(0)))
+
+	hooks, ok := CustomType(keyType)
+	require.True(t, ok)
+	value, err := hooks.Parse("c2VjcmV0")
+	require.NoError(t, err)
+	assert.Equal(t, &walkKey{data: []byte("secret")}, value)
+	assert.Equal(t, "c2VjcmV0", hooks.Render(walkKey{data: []byte("secret")}))
+	assert.Equal(t, "c2VjcmV0", TextString(reflect.ValueOf(walkKey{data: []byte("secret")})))
+
+	_, err = hooks.Parse("%%%")
+	assert.ErrorContains(t, err, "cannot decode")
+	_, err = hooks.Parse("")
+	assert.ErrorContains(t, err, "empty key")
+
+	// A registered type uses its own hooks.
+	type registeredKey struct{ walkKey }
+	assert.True(t, BinaryType(reflect.TypeOf(registeredKey{})))
+	RegisterType(reflect.TypeOf(registeredKey{}), TypeHooks{
+		Parse: func(s string) (interface{}, error) { return registeredKey{}, nil },
+	})
+	assert.False(t, BinaryType(reflect.TypeOf(registeredKey{})))
+}
@@ -0,0 +1,394 @@
+package walker
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// textTypes are the standard types configured by their string form although
+// they are structs or slices.
+var textTypes = map[reflect.Type]bool{
+	reflect.TypeOf(url.URL{}):   true,
+	reflect.TypeOf(net.IP{}):    true,
+	reflect.TypeOf(net.IPNet{}): true,
+}
+
+// SkipStruct can be returned by the visit function of Walk for a struct field
+// to skip its nested fields. Returned for any other field it is ignored.
+var SkipStruct = errors.New("skip struct")
+
+// Field describes a single config field, with the tag-priority and ignore
+// rules shared by all generators already applied.
+type Field struct {
+	// Name is the name of the Go struct field.
+	Name string
+	// Key is the key of the field in config files: the yaml, mapstructure or
+	// json tag name, or the lowercase field name. See KeyFor for other
+	// formats.
+	Key string
+	// Path is the dotted path of keys from the root, e.g. "meta.version".
+	Path string
+	// BindKey is the dotted Viper key, built from mapstructure tags or
+	// lowercase field names, e.g. "meta.version".
+	BindKey string
+	// Type is the type of the field with pointers removed.
+	Type reflect.Type
+	// Kind is the kind of Type.
+	Kind reflect.Kind
+	// Tag is the raw struct tag of the field.
+	Tag reflect.StructTag
+	// Tags holds the key/value pairs of Tag, e.g. Tags["help"].
+	Tags map[string]string
+	// Default is the value of the `default` tag.
+	Default string
+	// Help is the value of the `help` tag.
+	Help string
+	// Unit is the value of the `unit` tag, e.g. "MB" or "ms". It only
+	// documents the field and doesn't change how values are parsed.
+	Unit string
+	// Depth is the nesting level, 0 for top-level fields.
+	Depth int
+	// Parent is the enclosing struct field, or nil for top-level fields.
+	Parent *Field
+	// Recursive is set by Walk for fields referring back to a struct being
+	// expanded on the current path, directly or through slices, maps and
+	// pointers. Walk doesn't descend into them.
+	Recursive bool
+	// StructField is the underlying reflect field.
+	StructField reflect.StructField
+	// Index is the index sequence of the field in the struct passed to
+	// Fields, through the flattened structs it is declared in, for
+	// reflect.Value.FieldByIndex.
+	Index []int
+}
+
+// Expanding holds the struct types being expanded on the current path from
+// the root. Generators recursing into nested structs use it to render a
+// recursive type one level deep instead of forever.
+type Expanding map[reflect.Type]bool
+
+// Recursive reports whether a field of type t refers back to a struct being
+// expanded: t itself or the elements of a slice, array or map, with pointers
+// removed.
+func (e Expanding) Recursive(t reflect.Type) bool {
+	s := StructType(t)
+	return s != nil && e[s]
+}
+
+// StructType returns the struct type a field of type t expands into: t
+// itself or the element type of a slice, array or map, with pointers removed.
+// It returns nil for other types.
+func StructType(t reflect.Type) reflect.Type {
+	t = deref(t)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		t = deref(t.Elem())
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// TextType reports whether t is configured as a single string: a standard
+// type like url.URL (as parsed by url.Parse), net.IP and net.IPNet (in CIDR
+// notation), a type registered with RegisterType, or a BinaryType in base64.
+// Generators render them like strings and Walk doesn't descend into them.
+func TextType(t reflect.Type) bool {
+	if textTypes[t] {
+		return true
+	}
+	_, ok := CustomType(t)
+	return ok
+}
+
+// textUnmarshalerType is used to detect types decoded from their text form,
+// like time.Time.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// SectionPointer reports whether t is a pointer to a struct configured as an
+// optional section of its own, rather than to a type configured as a single
+// string like time.Time or url.URL.
+func SectionPointer(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr {
+		return false
+	}
+	elem := deref(t)
+	return elem.Kind() == reflect.Struct && !TextType(elem) && !reflect.PointerTo(elem).Implements(textUnmarshalerType)
+}
+
+// deref unwraps pointer types.
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Walk calls visit for every field of the struct type t (or pointer to
+// struct) in declaration order. Struct fields are visited before their nested
+// fields; slices, maps and text types (see TextType) are visited as single
+// fields without descending into them. Fields of a recursive type are visited
+// with Recursive set, without descending into them again. Walking stops at the
+// first error returned by visit, except SkipStruct.
+func Walk(t reflect.Type, visit func(Field) error) error {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot walk %v: not a struct", t)
+	}
+	return walk(t, nil, Expanding{}, visit)
+}
+
+// walk visits the fields of a struct, keeping track of the struct types on
+// the current path to detect recursive types.
+func walk(t reflect.Type, parent *Field, expanding Expanding, visit func(Field) error) error {
+	expanding[t] = true
+	defer delete(expanding, t)
+
+	for _, field := range Fields(t, parent) {
+		field.Recursive = expanding.Recursive(field.Type)
+		err := visit(field)
+		if field.Kind != reflect.Struct || field.Recursive || TextType(field.Type) {
+			if err != nil && err != SkipStruct {
+				return err
+			}
+			continue
+		}
+		if err == SkipStruct {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := walk(field.Type, &field, expanding, visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fields returns the fields of the struct type t (or pointer to struct) in
+// declaration order. Unexported fields and fields tagged with `yaml:"-"` or
+// `mapstructure:"-"` are skipped. Embedded structs without an explicit name
+// and fields tagged with `mapstructure:",squash"` are flattened in place.
+//
+// parent is the field t belongs to, or nil at the top level; paths, bind
+// keys and depth of the returned fields are derived from it.
+func Fields(t reflect.Type, parent *Field) []Field {
+	return FieldsFunc(t, parent, ignored)
+}
+
+// FieldsFunc works like Fields, but skips the fields for which skip returns
+// true instead of those tagged with `yaml:"-"` or `mapstructure:"-"`.
+// Unexported fields are always skipped.
+func FieldsFunc(t reflect.Type, parent *Field, skip func(reflect.StructField) bool) []Field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var fields []Field
+	collectFields(t, parent, nil, skip, map[reflect.Type]bool{}, &fields)
+	return fields
+}
+
+// ignored reports whether a field is intentionally ignored by `yaml:"-"` or
+// `mapstructure:"-"`.
+func ignored(field reflect.StructField) bool {
+	return field.Tag.Get("yaml") == "-" || field.Tag.Get("mapstructure") == "-"
+}
+
+// collectFields appends the fields of a struct, descending into flattened
+// structs. A struct flattened into itself adds no new fields, so it is
+// skipped instead of recursing forever. index is the index sequence of t in
+// the struct passed to Fields.
+func collectFields(t reflect.Type, parent *Field, index []int, skip func(reflect.StructField) bool, flattening map[reflect.Type]bool, fields *[]Field) {
+	flattening[t] = true
+	defer delete(flattening, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		// Skip unexported fields.
+		// In Go, an exported field has an uppercase first letter and an empty PkgPath.
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if skip(field) {
+			continue
+		}
+		tag := field.Tag
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if Flattened(field) {
+			if !flattening[fieldType] {
+				collectFields(fieldType, parent, fieldIndex, skip, flattening, fields)
+			}
+			continue
+		}
+
+		f := Field{
+			Name:        field.Name,
+			Key:         getFieldName(field),
+			BindKey:     getBindKey(field),
+			Type:        fieldType,
+			Kind:        fieldType.Kind(),
+			Tag:         tag,
+			Tags:        parseTags(tag),
+			Default:     tag.Get("default"),
+			Help:        tag.Get("help"),
+			Unit:        tag.Get("unit"),
+			Parent:      parent,
+			StructField: field,
+			Index:       fieldIndex,
+		}
+		f.Path = f.Key
+		if parent != nil {
+			f.Path = parent.Path + "." + f.Key
+			f.BindKey = parent.BindKey + "." + f.BindKey
+			f.Depth = parent.Depth + 1
+		}
+		*fields = append(*fields, f)
+	}
+}
+
+// Key tag priorities of the template generators. A field tagged
+// `yaml:"host" json:"hostname"` is "host" in YAML templates and "hostname" in
+// JSON templates; without the format's own tag the mapstructure name is used.
+var (
+	YAMLKeyTags = []string{"yaml", "mapstructure", "json"}
+	JSONKeyTags = []string{"json", "mapstructure", "yaml"}
+)
+
+// getFieldName determines the key of the field in config files.
+// Priority:
+// 1. yaml:"..." tag (excluding "-")
+// 2. mapstructure:"..." tag (excluding "-")
+// 3. json:"..." tag (excluding "-")
+// 4. fallback to lowercase struct field name.
+func getFieldName(field reflect.StructField) string {
+	return fieldKey(field.Tag, field.Name, YAMLKeyTags)
+}
+
+// KeyFor returns the key of the field under the given tag priority, e.g.
+// JSONKeyTags, falling back to the lowercase field name.
+func (f Field) KeyFor(tags []string) string {
+	return fieldKey(f.Tag, f.Name, tags)
+}
+
+func fieldKey(tag reflect.StructTag, name string, tags []string) string {
+	for _, key := range tags {
+		name := strings.Split(tag.Get(key), ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return strings.ToLower(name)
+}
+
+// getBindKey returns the part of the Viper key of the field: the mapstructure
+// name or the lowercase field name.
+func getBindKey(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]; name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
+
+// Flattened reports whether the fields of a struct field are declared at the
+// parent level: either the field is embedded and has no explicit yaml,
+// mapstructure or json name, or it is tagged with `mapstructure:",squash"`.
+// An embedded pointer to a struct stays nested under its name, as
+// mapstructure decodes it. The loader flattens the same fields.
+func Flattened(field reflect.StructField) bool {
+	if field.Type.Kind() != reflect.Struct {
+		return false
+	}
+	if isSquashed(field.Tag) {
+		return true
+	}
+	if !field.Anonymous {
+		return false
+	}
+	for _, key := range []string{"yaml", "mapstructure", "json"} {
+		if strings.Split(field.Tag.Get(key), ",")[0] != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isSquashed reports whether the mapstructure tag has the "squash" option.
+func isSquashed(tag reflect.StructTag) bool {
+	for _, opt := range strings.Split(tag.Get("mapstructure"), ",")[1:] {
+		if opt == "squash" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTags splits a struct tag into its key/value pairs, following the
+// conventional `key:"value" key2:"value2"` format. Malformed parts end
+// the parsing.
+func parseTags(tag reflect.StructTag) map[string]string {
+	tags := make(map[string]string)
+	s := string(tag)
+	for s != "" {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			break
+		}
+
+		i := 0
+		for i < len(s) && s[i] > ' ' && s[i] != ':' && s[i] != '"' && s[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(s) || s[i] != ':' || s[i+1] != '"' {
+			break
+		}
+		name := s[:i]
+		s = s[i+1:]
+
+		// Scan the quoted value, skipping escaped characters.
+		i = 1
+		for i < len(s) && s[i] != '"' {
+			if s[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		value, err := strconv.Unquote(s[:i+1])
+		if err != nil {
+			break
+		}
+		tags[name] = value
+		s = s[i+1:]
+	}
+	return tags
+}
+
+// HelpWithUnit appends the unit of a field in parentheses to its help text,
+// e.g. "Max upload size (MB)". An empty unit leaves the help text as is.
+func HelpWithUnit(help, unit string) string {
+	if unit == "" {
+		return help
+	}
+	return strings.TrimSpace(help + " (" + unit + ")")
+}
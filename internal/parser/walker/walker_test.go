@@ -0,0 +1,218 @@
+package walker
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type walkBase struct {
+	Name string `mapstructure:"name" default:"app"`
+}
+
+type WalkBase struct {
+	Env string `mapstructure:"env"`
+}
+
+type walkConfig struct {
+	WalkBase
+	Common walkBase `mapstructure:",squash"`
+	Host   string   `yaml:"server_host" mapstructure:"host" default:"localhost" help:"The hostname"`
+	Meta   *struct {
+		Version string `mapstructure:"version" default:"1.0"`
+	} `mapstructure:"meta" help:"Meta section"`
+	Tags    []string `json:"tags"`
+	Ignored string   `mapstructure:"-"`
+	hidden  string
+}
+
+func TestWalk(t *testing.T) {
+	var paths, bindKeys []string
+	var depths []int
+	err := Walk(reflect.TypeOf(&walkConfig{}), func(f Field) error {
+		paths = append(paths, f.Path)
+		bindKeys = append(bindKeys, f.BindKey)
+		depths = append(depths, f.Depth)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"env", "name", "server_host", "meta", "meta.version", "tags"}, paths)
+	assert.Equal(t, []string{"env", "name", "host", "meta", "meta.version", "tags"}, bindKeys)
+	assert.Equal(t, []int{0, 0, 0, 0, 1, 0}, depths)
+}
+
+func TestWalk_FieldInfo(t *testing.T) {
+	fields := map[string]Field{}
+	err := Walk(reflect.TypeOf(walkConfig{}), func(f Field) error {
+		fields[f.Path] = f
+		return nil
+	})
+	require.NoError(t, err)
+
+	host := fields["server_host"]
+	assert.Equal(t, "Host", host.Name)
+	assert.Equal(t, reflect.String, host.Kind)
+	assert.Equal(t, "localhost", host.Default)
+	assert.Equal(t, "The hostname", host.Help)
+	assert.Equal(t, map[string]string{
+		"yaml":         "server_host",
+		"mapstructure": "host",
+		"default":      "localhost",
+		"help":         "The hostname",
+	}, host.Tags)
+	assert.Nil(t, host.Parent)
+
+	version := fields["meta.version"]
+	require.NotNil(t, version.Parent)
+	assert.Equal(t, "meta", version.Parent.Path)
+	assert.Equal(t, "Meta section", version.Parent.Help)
+
+	// Pointers are removed from the type.
+	assert.Equal(t, reflect.Struct, fields["meta"].Kind)
+	assert.Equal(t, reflect.Slice, fields["tags"].Kind)
+}
+
+func TestFieldsFunc(t *testing.T) {
+	fields := FieldsFunc(reflect.TypeOf(walkConfig{}), nil, func(field reflect.StructField) bool {
+		return field.Tag.Get("json") == "tags"
+	})
+
+	var keys []string
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	assert.Equal(t, []string{"env", "name", "server_host", "meta", "ignored"}, keys)
+
+	// Index reaches the fields of flattened structs from the outer struct.
+	cfg := walkConfig{Common: walkBase{Name: "svc"}}
+	assert.Equal(t, []int{1, 0}, fields[1].Index)
+	assert.Equal(t, "svc", reflect.ValueOf(cfg).FieldByIndex(fields[1].Index).String())
+}
+
+func TestWalk_SkipStruct(t *testing.T) {
+	var paths []string
+	err := Walk(reflect.TypeOf(walkConfig{}), func(f Field) error {
+		paths = append(paths, f.Path)
+		if f.Key == "meta" {
+			return SkipStruct
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"env", "name", "server_host", "meta", "tags"}, paths)
+}
+
+func TestWalk_Errors(t *testing.T) {
+	assert.Error(t, Walk(reflect.TypeOf(42), func(Field) error { return nil }))
+	assert.Error(t, Walk(nil, func(Field) error { return nil }))
+
+	stop := assert.AnError
+	var visited int
+	err := Walk(reflect.TypeOf(walkConfig{}), func(Field) error {
+		visited++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, visited)
+}
+
+func TestParseTags(t *testing.T) {
+	tags := parseTags(`json:"a,omitempty" help:"Say \"hi\"" x:""`)
+
+	assert.Equal(t, map[string]string{"json": "a,omitempty", "help": `Say "hi"`, "x": ""}, tags)
+}
+
+type treeNode struct {
+	Name     string     `mapstructure:"name"`
+	Parent   *treeNode  `mapstructure:"parent"`
+	Children []treeNode `mapstructure:"children"`
+}
+
+func TestWalk_Recursive(t *testing.T) {
+	type Config struct {
+		Tree treeNode `mapstructure:"tree"`
+	}
+
+	recursive := make(map[string]bool)
+	err := Walk(reflect.TypeOf(Config{}), func(f Field) error {
+		recursive[f.Path] = f.Recursive
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]bool{
+		"tree":          false,
+		"tree.name":     false,
+		"tree.parent":   true,
+		"tree.children": true,
+	}, recursive)
+}
+
+func TestStructType(t *testing.T) {
+	node := reflect.TypeOf(treeNode{})
+	assert.Equal(t, node, StructType(reflect.TypeOf(&treeNode{})))
+	assert.Equal(t, node, StructType(reflect.TypeOf([]*treeNode{})))
+	assert.Equal(t, node, StructType(reflect.TypeOf(map[string]treeNode{})))
+	assert.Nil(t, StructType(reflect.TypeOf([]string{})))
+}
+
+func TestWalk_TextTypes(t *testing.T) {
+	type Config struct {
+		BaseURL url.URL    `mapstructure:"base_url"`
+		BindIP  net.IP     `mapstructure:"bind_ip"`
+		Allowed *net.IPNet `mapstructure:"allowed_cidr"`
+	}
+
+	var keys []string
+	require.NoError(t, Walk(reflect.TypeOf(Config{}), func(f Field) error {
+		keys = append(keys, f.BindKey)
+		return nil
+	}))
+	assert.Equal(t, []string{"base_url", "bind_ip", "allowed_cidr"}, keys)
+
+	assert.True(t, TextType(reflect.TypeOf(url.URL{})))
+	assert.False(t, TextType(reflect.TypeOf(&url.URL{})))
+	assert.False(t, TextType(reflect.TypeOf("")))
+}
+
+func TestWalk_Unit(t *testing.T) {
+	type config struct {
+		MaxUpload int `mapstructure:"max_upload" unit:"MB" help:"Max upload size"`
+		Timeout   int `mapstructure:"timeout" unit:"ms"`
+		Retries   int `mapstructure:"retries" help:"Retries"`
+	}
+
+	fields := Fields(reflect.TypeOf(config{}), nil)
+	require.Len(t, fields, 3)
+	assert.Equal(t, "MB", fields[0].Unit)
+	assert.Equal(t, "Max upload size (MB)", HelpWithUnit(fields[0].Help, fields[0].Unit))
+	assert.Equal(t, "(ms)", HelpWithUnit(fields[1].Help, fields[1].Unit))
+	assert.Equal(t, "Retries", HelpWithUnit(fields[2].Help, fields[2].Unit))
+}
+
+func TestField_KeyFor(t *testing.T) {
+	type config struct {
+		Host  string `yaml:"host" json:"hostname"`
+		Port  int    `mapstructure:"port" json:"listen_port"`
+		Name  string `json:"name,omitempty"`
+		Plain string
+	}
+
+	fields := Fields(reflect.TypeOf(config{}), nil)
+	require.Len(t, fields, 4)
+
+	var yamlKeys, jsonKeys []string
+	for _, f := range fields {
+		yamlKeys = append(yamlKeys, f.KeyFor(YAMLKeyTags))
+		jsonKeys = append(jsonKeys, f.KeyFor(JSONKeyTags))
+		assert.Equal(t, f.Key, f.KeyFor(YAMLKeyTags))
+	}
+	assert.Equal(t, []string{"host", "port", "name", "plain"}, yamlKeys)
+	assert.Equal(t, []string{"hostname", "listen_port", "name", "plain"}, jsonKeys)
+}
@@ -0,0 +1,176 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/vsysa/configo/internal/parser/walker"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// DumpConfig serializes a populated config struct to YAML, using the same key
+// names as GenerateYAMLTemplate and keeping the field declaration order.
+//
+// When maskSecrets is true, the values of fields tagged with `secret:"true"`
+// are replaced by "***". The masking applies to secrets at any depth, inside
+// nested structs, slices and maps as well.
+func DumpConfig(cfg interface{}, maskSecrets bool) (string, error) {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return "", fmt.Errorf("cannot dump %T: not a struct", cfg)
+	}
+
+	node, err := valueNode(v, maskSecrets, false)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// valueNode converts a value into a YAML node. secret is set when the value
+// belongs to a field tagged as secret.
+func valueNode(v reflect.Value, maskSecrets, secret bool) (*yamlv3.Node, error) {
+	if secret && maskSecrets {
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Style: yamlv3.DoubleQuotedStyle, Value: "***"}, nil
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		v = v.Elem()
+	}
+
+	if text, ok := textString(v); ok {
+		return scalarNode(text)
+	}
+
+	if v.Type() == durationType {
+		return scalarNode(v.Interface().(fmt.Stringer).String())
+	}
+
+	if marshaler, ok := textMarshalerOf(v); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return scalarNode(string(text))
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		node := &yamlv3.Node{Kind: yamlv3.MappingNode}
+		if err := appendStructFields(node, v, maskSecrets, secret); err != nil {
+			return nil, err
+		}
+		return node, nil
+
+	case reflect.Slice, reflect.Array:
+		node := &yamlv3.Node{Kind: yamlv3.SequenceNode}
+		for i := 0; i < v.Len(); i++ {
+			item, err := valueNode(v.Index(i), maskSecrets, secret)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+		return node, nil
+
+	case reflect.Map:
+		// Map keys are sorted to keep the output stable.
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		node := &yamlv3.Node{Kind: yamlv3.MappingNode}
+		for _, key := range keys {
+			keyNode, err := scalarNode(key.Interface())
+			if err != nil {
+				return nil, err
+			}
+			valNode, err := valueNode(v.MapIndex(key), maskSecrets, secret)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, keyNode, valNode)
+		}
+		return node, nil
+
+	default:
+		return scalarNode(v.Interface())
+	}
+}
+
+// appendStructFields appends the key/value pairs of a struct to a mapping node.
+// Embedded structs without an explicit key are flattened into the same node.
+func appendStructFields(node *yamlv3.Node, v reflect.Value, maskSecrets, secret bool) error {
+	for _, field := range walker.Fields(v.Type(), nil) {
+		// Fields of a nil embedded pointer are left out.
+		fieldValue, err := v.FieldByIndexErr(field.Index)
+		if err != nil {
+			continue
+		}
+
+		valNode, err := valueNode(fieldValue, maskSecrets, secret || isSecret(field.Tag))
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.Key, err)
+		}
+		node.Content = append(node.Content,
+			&yamlv3.Node{Kind: yamlv3.ScalarNode, Value: field.Key},
+			valNode,
+		)
+	}
+	return nil
+}
+
+// scalarNode encodes a primitive Go value as a YAML scalar node.
+func scalarNode(value interface{}) (*yamlv3.Node, error) {
+	node := &yamlv3.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// textString returns the string form of a value of a walker.TextType, e.g. a
+// URL, a CIDR network or a registered type.
+func textString(v reflect.Value) (string, bool) {
+	if !walker.TextType(v.Type()) || !v.CanInterface() {
+		return "", false
+	}
+	return walker.TextString(v), true
+}
+
+// textMarshalerOf returns the encoding.TextMarshaler implementation of v,
+// looking at the pointer method set as well when v is addressable.
+func textMarshalerOf(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return marshaler, true
+	}
+	if v.CanAddr() {
+		if marshaler, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			return marshaler, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,71 @@
+package yaml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpConfig(t *testing.T) {
+	type User struct {
+		Name     string `yaml:"name"`
+		Password string `yaml:"password" secret:"true"`
+	}
+	type Config struct {
+		Host    string            `yaml:"host"`
+		Timeout time.Duration     `yaml:"timeout"`
+		Enabled string            `yaml:"enabled"`
+		Users   []User            `yaml:"users"`
+		Tokens  map[string]string `yaml:"tokens" secret:"true"`
+		Meta    *struct {
+			Version string `yaml:"version"`
+		} `yaml:"meta"`
+	}
+	cfg := Config{
+		Host:    "localhost",
+		Timeout: 30 * time.Second,
+		Enabled: "true",
+		Users:   []User{{Name: "admin", Password: "qwerty"}},
+		Tokens:  map[string]string{"ci": "abc"},
+	}
+
+	masked, err := DumpConfig(cfg, true)
+	require.NoError(t, err)
+	assert.Equal(t, `host: localhost
+timeout: 30s
+enabled: "true"
+users:
+  - name: admin
+    password: "***"
+tokens: "***"
+meta: null
+`, masked)
+
+	plain, err := DumpConfig(&cfg, false)
+	require.NoError(t, err)
+	assert.Equal(t, `host: localhost
+timeout: 30s
+enabled: "true"
+users:
+  - name: admin
+    password: qwerty
+tokens:
+  ci: abc
+meta: null
+`, plain)
+}
+
+func TestDumpConfig_NotAStruct(t *testing.T) {
+	_, err := DumpConfig(42, true)
+	assert.Error(t, err)
+}
+
+func TestDumpConfig_FieldOrder(t *testing.T) {
+	cfg := orderConfig{A: "1", OrderFirst: OrderFirst{B: "2", C: "3"}, D: "4", OrderSecond: &OrderSecond{E: "5"}, F: "6"}
+
+	out, err := DumpConfig(cfg, false)
+	require.NoError(t, err)
+	assert.Equal(t, "a: \"1\"\nb: \"2\"\nc: \"3\"\nd: \"4\"\nordersecond:\n  e: \"5\"\nf: \"6\"\n", out)
+}
@@ -0,0 +1,256 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsysa/configo/internal/parser/defaultValues"
+	"github.com/vsysa/configo/internal/parser/options"
+	"github.com/vsysa/configo/internal/parser/walker"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+type rtServer struct {
+	Host string `yaml:"host" default:"localhost" help:"Host"`
+	Port int    `yaml:"port" default:"8080"`
+}
+
+type rtNode struct {
+	Name     string   `yaml:"name"`
+	Children []rtNode `yaml:"children"`
+}
+
+func TestGenerateYAMLTemplate_RoundTrip(t *testing.T) {
+	shapes := map[string]interface{}{
+		"empty": struct{}{},
+		"scalars": struct {
+			Name    string        `yaml:"name" default:"app" help:"Name"`
+			Quoted  string        `yaml:"quoted" default:"say \"hi\"" help:"Has quotes"`
+			Path    string        `yaml:"path" default:"C:\\temp\\new"`
+			Hash    string        `yaml:"hash" default:"#not-a-comment"`
+			Colon   string        `yaml:"colon" default:"a: b"`
+			Debug   bool          `yaml:"debug" default:"true"`
+			Ratio   float64       `yaml:"ratio" default:"0.5" min:"0" max:"1"`
+			Timeout time.Duration `yaml:"timeout" default:"30s"`
+			Start   time.Time     `yaml:"start" default:"2024-01-01T00:00:00Z"`
+			Secret  string        `yaml:"secret" default:"hunter2" secret:"true"`
+			Any     interface{}   `yaml:"any" example:"[1, 2]"`
+			Help    string        `yaml:"help" help:"Line one\nline two: # tricky"`
+		}{},
+		"multiline": struct {
+			Banner string `yaml:"banner" default:"Welcome!\nBye" help:"Banner"`
+			Keep   string `yaml:"keep" default:"a\n\n"`
+		}{},
+		"slices": struct {
+			Tags    []string    `yaml:"tags" default:"a,b" help:"Tags"`
+			Special []string    `yaml:"special" default:"x: y,#z,[w"`
+			JSON    []int       `yaml:"json" default:"[1, 2]"`
+			Servers []rtServer  `yaml:"servers" help:"Servers"`
+			Ptrs    []*rtServer `yaml:"ptrs"`
+			Empty   []string    `yaml:"empty"`
+			Samples []string    `yaml:"samples" examples:"a.com,#b"`
+			Many    []rtServer  `yaml:"many" example_count:"3"`
+		}{},
+		"maps": struct {
+			Labels  map[string]string              `yaml:"labels" help:"Labels"`
+			Custom  map[string]string              `yaml:"custom" example_key:"a: b" example_value:"#v"`
+			Servers map[string]rtServer            `yaml:"servers"`
+			Nested  map[string]map[string]int      `yaml:"nested" example_key:"region,zone"`
+			Lists   map[string][]string            `yaml:"lists"`
+			Deep    map[string]map[string]rtServer `yaml:"deep"`
+			Prio    map[int]string                 `yaml:"prio"`
+			Sets    []map[string]int               `yaml:"sets" example_value:"1"`
+		}{},
+		"flow": struct {
+			Tags    []string          `yaml:"tags" default:"a,b" yamlstyle:"flow" help:"Tags"`
+			Special []string          `yaml:"special" default:"x: y,a]b,{c},host:80" yamlstyle:"flow"`
+			JSON    []int             `yaml:"json" default:"[1, 2]" yamlstyle:"flow"`
+			Empty   []string          `yaml:"empty" yamlstyle:"flow"`
+			Labels  map[string]string `yaml:"labels" yamlstyle:"flow"`
+			Custom  map[string]string `yaml:"custom" example_key:"a,b" example_value:"}" yamlstyle:"flow"`
+			Secret  []string          `yaml:"secret" default:"x" secret:"true" yamlstyle:"flow"`
+		}{},
+		"deep": struct {
+			Lists  [][]string                  `yaml:"lists" help:"Lists"`
+			Grid   [][]rtServer                `yaml:"grid"`
+			Matrix map[string][][]int          `yaml:"matrix"`
+			Layers []map[string][]map[int]bool `yaml:"layers"`
+		}{},
+		"nested": struct {
+			Server   rtServer  `yaml:"server" section_help:"Server settings"`
+			Backup   *rtServer `yaml:"backup" optional:"true"`
+			Tree     rtNode    `yaml:"tree"`
+			Squashed struct {
+				Inner string `yaml:"inner"`
+			} `yaml:",inline"`
+		}{},
+	}
+
+	optionSets := map[string][]options.Option{
+		"default":   nil,
+		"per-block": {options.WithAlignment(options.AlignPerBlock), options.WithBoolHints()},
+		"tabs":      {options.WithAlignChar('\t'), options.WithCommentPrefix("##")},
+		"optional":  {options.WithOptionalCommented(true), options.WithEnvNames()},
+		"minimal":   {options.WithOmitEmptyDefaults(true), options.WithoutTrailingNewline()},
+		"unquoted":  {options.WithStringQuoting(options.QuoteWhenNeeded)},
+		"above":     {options.WithCommentPlacement(options.CommentAbove), options.WithCommentWidth(20)},
+	}
+
+	for shapeName, cfg := range shapes {
+		for optName, opts := range optionSets {
+			for _, printDescription := range []bool{true, false} {
+				template, err := GenerateYAMLTemplateE(cfg, printDescription, opts...)
+				if !assert.NoError(t, err, "%s/%s", shapeName, optName) {
+					continue
+				}
+				var parsed map[string]interface{}
+				if !assert.NoError(t, yamlv3.Unmarshal([]byte(template), &parsed), "%s/%s:\n%s", shapeName, optName, template) {
+					continue
+				}
+
+				// Without options dropping fields, every field comes back as a key.
+				if optName == "optional" || optName == "minimal" {
+					continue
+				}
+				var keys, expected []string
+				for key := range parsed {
+					keys = append(keys, key)
+				}
+				for _, field := range walker.Fields(reflect.TypeOf(cfg), nil) {
+					expected = append(expected, field.Key)
+				}
+				assert.ElementsMatch(t, expected, keys, "%s/%s:\n%s", shapeName, optName, template)
+			}
+		}
+	}
+}
+
+// Test that values needing quotes in YAML read back unchanged.
+func TestGenerateYAMLTemplate_RoundTripValues(t *testing.T) {
+	cfg := struct {
+		Quoted  string            `yaml:"quoted" default:"say \"hi\""`
+		Path    string            `yaml:"path" default:"C:\\temp\\new"`
+		Special []string          `yaml:"special" default:"x: y,#z,[w,plain"`
+		Labels  map[string]string `yaml:"labels" example_key:"a: b" example_value:"#v"`
+		Lists   map[string][]int  `yaml:"lists" example_value:"1"`
+	}{}
+	template := GenerateYAMLTemplate(cfg, true)
+
+	var parsed struct {
+		Quoted  string            `yaml:"quoted"`
+		Path    string            `yaml:"path"`
+		Special []string          `yaml:"special"`
+		Labels  map[string]string `yaml:"labels"`
+		Lists   map[string][]int  `yaml:"lists"`
+	}
+	assert.NoError(t, yamlv3.Unmarshal([]byte(template), &parsed), template)
+	assert.Equal(t, `say "hi"`, parsed.Quoted)
+	assert.Equal(t, `C:\temp\new`, parsed.Path)
+	assert.Equal(t, []string{"x: y", "#z", "[w", "plain"}, parsed.Special)
+	assert.Equal(t, map[string]string{"a: b": "#v"}, parsed.Labels)
+	assert.Equal(t, map[string][]int{"key": {1}}, parsed.Lists)
+}
+
+// Test that `#` and `:` in defaults and help texts neither start a comment
+// early nor shift the comment column.
+func TestGenerateYAMLTemplate_HashesAndColons(t *testing.T) {
+	cfg := struct {
+		Colon  string   `yaml:"colon" default:"a:b" help:"Format: host:port"`
+		Hash   string   `yaml:"hash" default:"has # hash" help:"See #3, # is not a comment here"`
+		Spaced string   `yaml:"spaced" default:"a: b #c" help:"key: value # trailing"`
+		Items  []string `yaml:"items" default:"a:b,x #y" help:"Items: # and :"`
+	}{}
+
+	expected := `colon: "a:b"       # Format: host:port
+hash: "has # hash" # See #3, # is not a comment here
+spaced: "a: b #c"  # key: value # trailing
+items:             # Items: # and :
+  - a:b
+  - "x #y"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+
+	for name, opts := range map[string][]options.Option{
+		"default":  nil,
+		"unquoted": {options.WithStringQuoting(options.QuoteWhenNeeded)},
+		"above":    {options.WithCommentPlacement(options.CommentAbove)},
+	} {
+		template := GenerateYAMLTemplate(cfg, true, opts...)
+		var parsed struct {
+			Colon  string   `yaml:"colon"`
+			Hash   string   `yaml:"hash"`
+			Spaced string   `yaml:"spaced"`
+			Items  []string `yaml:"items"`
+		}
+		if !assert.NoError(t, yamlv3.Unmarshal([]byte(template), &parsed), "%s:\n%s", name, template) {
+			continue
+		}
+		assert.Equal(t, "a:b", parsed.Colon, name)
+		assert.Equal(t, "has # hash", parsed.Hash, name)
+		assert.Equal(t, "a: b #c", parsed.Spaced, name)
+		assert.Equal(t, []string{"a:b", "x #y"}, parsed.Items, name)
+	}
+}
+
+// FuzzGenerateYAMLTemplate checks that templates stay valid YAML whatever the
+// default, help and example tags hold.
+func FuzzGenerateYAMLTemplate(f *testing.F) {
+	f.Add("app", "Name", "key", "value")
+	f.Add(`say "hi"`, "Line one\nline two", "a: b", "#v")
+	f.Add("Welcome!\nBye\n\n", "", "[x", "{y")
+	f.Add("  indented\n\tlast", "# help", "- k", "&anchor")
+	f.Add(`C:\temp`, "tab\there", "", "")
+	f.Add("has # hash", "key: value # see #3", "a:b", "x #y")
+
+	f.Fuzz(func(t *testing.T, def, help, exampleKey, exampleValue string) {
+		// Help texts are prose written in Go source, defaults and examples
+		// can be any value.
+		if strings.IndexFunc(help, func(r rune) bool { return r == utf8.RuneError || r != '\n' && r != '\t' && !unicode.IsPrint(r) }) >= 0 {
+			return
+		}
+		// Defaults that don't parse as the field type are rejected, so those
+		// fields are left without one.
+		field := func(name, key string, v interface{}) reflect.StructField {
+			f := reflect.StructField{Name: name, Type: reflect.TypeOf(v),
+				Tag: reflect.StructTag(fmt.Sprintf(`yaml:%q default:%q help:%q`, key, def, help))}
+			if defaultValues.CheckDefault(f) != nil {
+				f.Tag = reflect.StructTag(fmt.Sprintf(`yaml:%q help:%q`, key, help))
+			}
+			return f
+		}
+		typ := reflect.StructOf([]reflect.StructField{
+			field("String", "string", ""),
+			field("Strings", "strings", []string{}),
+			field("Int", "int", 0),
+			field("Bool", "bool", false),
+			field("Duration", "duration", time.Duration(0)),
+			{Name: "Labels", Type: reflect.TypeOf(map[string]string{}),
+				Tag: reflect.StructTag(fmt.Sprintf(`yaml:"labels" help:%q example_key:%q example_value:%q`, help, exampleKey, exampleValue))},
+		})
+		template, err := GenerateYAMLTemplateE(reflect.New(typ).Elem().Interface(), true)
+		if err != nil {
+			return
+		}
+		var parsed interface{}
+		if err := yamlv3.Unmarshal([]byte(template), &parsed); err != nil {
+			t.Fatalf("invalid YAML: %v\n%s", err, template)
+		}
+
+		// Strings left unquoted must read back unchanged. Without a default
+		// the field is null.
+		template, _ = GenerateYAMLTemplateE(reflect.New(typ).Elem().Interface(), true, options.WithStringQuoting(options.QuoteWhenNeeded))
+		var values map[string]interface{}
+		if err := yamlv3.Unmarshal([]byte(template), &values); err != nil {
+			t.Fatalf("invalid YAML: %v\n%s", err, template)
+		}
+		if def != "" && utf8.ValidString(def) && values["string"] != def {
+			t.Fatalf("string default %q read back as %#v\n%s", def, values["string"], template)
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vsysa/configo/internal/parser/options"
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// GenerateYAMLFromValues generates a commented YAML document from the current
+// values of a populated config struct, instead of its `default` tags. Help
+// comments and annotations are attached like in GenerateYAMLTemplate.
+//
+// Zero values are rendered as their literals (0, "", false, [] and {}). Nil
+// pointers are rendered as the zero value of the pointed-to type, or as null
+// with WithNullPointers. Values of fields tagged with `secret:"true"` are
+// always masked. Inline values nested in slices or maps (e.g. a slice of
+// maps) are rendered in JSON flow form.
+func GenerateYAMLFromValues(cfg interface{}, printDescription bool, opts ...Option) string {
+	g := &generator{opts: options.New(opts...)}
+
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	g.parseValues(v, 0, g.newBlock())
+	return generateYAMLWithAlignment(g.lines, printDescription, g.opts)
+}
+
+// parseValues renders the fields of a struct value.
+func (g *generator) parseValues(v reflect.Value, indent int, block int) {
+	indentation := strings.Repeat("  ", indent)
+
+	for _, field := range walker.Fields(v.Type(), nil) {
+		// Skip the fields of inactive profiles.
+		tag := field.Tag
+		if !g.opts.InProfiles(tag) {
+			continue
+		}
+
+		// Fields of a nil embedded pointer have no value to render.
+		fieldValue, err := v.FieldByIndexErr(field.Index)
+		if err != nil {
+			continue
+		}
+
+		fieldName := field.Key
+		value, ok := g.derefValue(fieldValue)
+		if !ok {
+			g.addLine(block, fmt.Sprintf("%s%s: null", indentation, fieldName), g.buildComment(tag, field.Type))
+			continue
+		}
+
+		helpText := g.buildComment(tag, value.Type())
+		if g.opts.EnvNames && value.Kind() != reflect.Struct {
+			helpText = appendEnvName(helpText, tag, g.opts.EnvNaming)
+		}
+
+		g.addSectionHelp(block, indentation, tag, value.Type())
+
+		inSecret := g.inSecret
+		g.inSecret = g.inSecret || isSecret(tag)
+		g.renderValue(value, fieldName+":", indentation, indent, block, helpText)
+		g.inSecret = inSecret
+	}
+}
+
+// renderValue renders a value after its key (prefix), e.g. "port:" or "-".
+// Structs, slices and maps continue on the following lines.
+func (g *generator) renderValue(v reflect.Value, prefix, indentation string, indent, block int, helpText string) {
+	if scalar, ok := g.scalarValue(v); ok {
+		g.addLine(block, fmt.Sprintf("%s%s %s", indentation, prefix, scalar), helpText)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		g.addLine(block, fmt.Sprintf("%s%s", indentation, prefix), helpText)
+		g.parseValues(v, indent+1, g.newBlock())
+
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			g.addLine(block, fmt.Sprintf("%s%s []", indentation, prefix), helpText)
+			return
+		}
+		g.addLine(block, fmt.Sprintf("%s%s", indentation, prefix), helpText)
+		itemsBlock := g.newBlock()
+		for i := 0; i < v.Len(); i++ {
+			item, ok := g.derefValue(v.Index(i))
+			switch {
+			case !ok:
+				g.addLine(itemsBlock, fmt.Sprintf("%s  - null", indentation), "")
+			case item.Kind() == reflect.Struct && !g.isScalar(item):
+				// Struct elements are rendered like in templates: a "-" line
+				// followed by the fields.
+				g.addLine(itemsBlock, fmt.Sprintf("%s  -", indentation), "")
+				g.parseValues(item, indent+2, g.newBlock())
+			default:
+				g.addLine(itemsBlock, fmt.Sprintf("%s  - %s", indentation, g.inlineValue(item)), "")
+			}
+		}
+
+	case reflect.Map:
+		if v.Len() == 0 {
+			g.addLine(block, fmt.Sprintf("%s%s {}", indentation, prefix), helpText)
+			return
+		}
+		g.addLine(block, fmt.Sprintf("%s%s", indentation, prefix), helpText)
+
+		// Map keys are sorted to keep the output stable.
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		entriesBlock := g.newBlock()
+		for _, key := range keys {
+			item, ok := g.derefValue(v.MapIndex(key))
+			keyPrefix := fmt.Sprint(key.Interface()) + ":"
+			switch {
+			case !ok:
+				g.addLine(entriesBlock, fmt.Sprintf("%s  %s null", indentation, keyPrefix), "")
+			case item.Kind() == reflect.Struct && !g.isScalar(item):
+				g.addLine(entriesBlock, fmt.Sprintf("%s  %s", indentation, keyPrefix), "")
+				g.parseValues(item, indent+2, g.newBlock())
+			default:
+				g.addLine(entriesBlock, fmt.Sprintf("%s  %s %s", indentation, keyPrefix, g.inlineValue(item)), "")
+			}
+		}
+
+	default:
+		g.addLine(block, fmt.Sprintf("%s%s %s", indentation, prefix, g.inlineValue(v)), helpText)
+	}
+}
+
+// derefValue dereferences pointers and interfaces. It returns false for nil
+// values that have to be rendered as null.
+func (g *generator) derefValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if v.Kind() == reflect.Interface || g.opts.NullPointers {
+				return v, false
+			}
+			return reflect.Zero(v.Type().Elem()), true
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// isScalar reports whether the value is rendered on a single line.
+func (g *generator) isScalar(v reflect.Value) bool {
+	_, ok := g.scalarValue(v)
+	return ok
+}
+
+// scalarValue renders masked secrets, text types like url.URL or registered
+// types, durations, encoding.TextMarshaler types and primitives.
+func (g *generator) scalarValue(v reflect.Value) (string, bool) {
+	// Structs rendered as a single value are masked too.
+	if g.inSecret && (v.Kind() != reflect.Struct || walker.TextType(v.Type()) || implementsTextMarshaler(v.Type())) {
+		return maskedValue, true
+	}
+
+	if text, ok := textString(v); ok {
+		return g.quote(text), true
+	}
+
+	if v.Type() == durationType {
+		return g.quote(v.Interface().(fmt.Stringer).String()), true
+	}
+
+	if marshaler, ok := textMarshalerOf(v); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "null", true
+		}
+		return g.quote(string(text)), true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return g.quote(v.String()), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		// Floats are written without an exponent, like the defaults.
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), true
+	default:
+		return "", false
+	}
+}
+
+// inlineValue renders a value on a single line. Complex values use the JSON
+// flow form, which is valid YAML.
+func (g *generator) inlineValue(v reflect.Value) string {
+	if scalar, ok := g.scalarValue(v); ok {
+		return scalar
+	}
+	if !v.CanInterface() {
+		return "null"
+	}
+	out, err := json.Marshal(v.Interface())
+	if err != nil {
+		return "null"
+	}
+	return string(out)
+}
+
+// derefType unwraps pointer types.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,137 @@
+package yaml
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsysa/configo/internal/parser/options"
+)
+
+func TestGenerateYAMLFromValues(t *testing.T) {
+	type Server struct {
+		Host string `yaml:"host" help:"Server host"`
+		Port int    `yaml:"port" help:"Server port"`
+	}
+	type Config struct {
+		Name     string            `yaml:"name" default:"app" help:"App name"`
+		Debug    bool              `yaml:"debug"`
+		Timeout  time.Duration     `yaml:"timeout"`
+		Ratio    float64           `yaml:"ratio"`
+		Token    string            `yaml:"token" secret:"true"`
+		Tags     []string          `yaml:"tags"`
+		Servers  []Server          `yaml:"servers"`
+		Labels   map[string]string `yaml:"labels"`
+		Retries  *int              `yaml:"retries"`
+		Empty    []string          `yaml:"empty"`
+		Server   Server            `yaml:"server"`
+		Metadata map[string]any    `yaml:"metadata"`
+	}
+
+	cfg := Config{
+		Name:     "svc",
+		Timeout:  5 * time.Second,
+		Ratio:    0.5,
+		Token:    "s3cr3t",
+		Tags:     []string{"a", "b"},
+		Servers:  []Server{{Host: "one", Port: 1}},
+		Labels:   map[string]string{"zone": "b", "env": "prod"},
+		Server:   Server{Host: "localhost"},
+		Metadata: map[string]any{"limits": []int{1, 2}},
+	}
+
+	expected := `name: "svc"         # App name
+debug: false
+timeout: "5s"
+ratio: 0.5
+token: "***"
+tags:
+  - "a"
+  - "b"
+servers:
+  -
+    host: "one"     # Server host
+    port: 1         # Server port
+labels:
+  env: "prod"
+  zone: "b"
+retries: 0
+empty: []
+server:
+  host: "localhost" # Server host
+  port: 0           # Server port
+metadata:
+  limits: [1,2]
+`
+
+	assert.Equal(t, expected, GenerateYAMLFromValues(cfg, true))
+	assert.Contains(t, GenerateYAMLFromValues(&cfg, false, options.WithNullPointers()), "retries: null\n")
+}
+
+func TestGenerateYAMLFromValues_NotAStruct(t *testing.T) {
+	assert.Equal(t, "", GenerateYAMLFromValues(42, true))
+	assert.Equal(t, "", GenerateYAMLFromValues(nil, true))
+}
+
+// Test that numbers of every kind are rendered exactly, floats without an
+// exponent.
+func TestGenerateYAMLFromValues_Numbers(t *testing.T) {
+	cfg := struct {
+		Port  uint16  `yaml:"port"`
+		Delta int8    `yaml:"delta"`
+		Max   uint64  `yaml:"max"`
+		Ratio float32 `yaml:"ratio"`
+		Big   float64 `yaml:"big"`
+		Huge  float64 `yaml:"huge"`
+		Tiny  float64 `yaml:"tiny"`
+	}{Port: 65535, Delta: -128, Max: 18446744073709551615, Ratio: 0.1, Big: 100000000000, Huge: 1e21, Tiny: 1e-7}
+
+	expected := `port: 65535
+delta: -128
+max: 18446744073709551615
+ratio: 0.1
+big: 100000000000
+huge: 1000000000000000000000
+tiny: 0.0000001
+`
+
+	assert.Equal(t, expected, GenerateYAMLFromValues(cfg, false))
+}
+
+func TestGenerateYAMLFromValues_SectionHelp(t *testing.T) {
+	type DB struct {
+		Host string `yaml:"host"`
+	}
+	cfg := struct {
+		DB DB `yaml:"db" section_help:"Database connection settings"`
+	}{DB: DB{Host: "db.local"}}
+
+	expected := `# Database connection settings
+db:
+  host: "db.local"
+`
+	assert.Equal(t, expected, GenerateYAMLFromValues(cfg, true))
+}
+
+func TestGenerateYAMLFromValues_TextTypes(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	cfg := struct {
+		BaseURL url.URL    `yaml:"base_url"`
+		Allowed *net.IPNet `yaml:"allowed_cidr"`
+		Empty   net.IPNet  `yaml:"empty"`
+		Token   url.URL    `yaml:"token" secret:"true"`
+	}{
+		BaseURL: url.URL{Scheme: "https", Host: "example.com", Path: "/api"},
+		Allowed: network,
+		Token:   url.URL{Scheme: "https", Host: "user:pass@example.com"},
+	}
+
+	expected := `base_url: "https://example.com/api"
+allowed_cidr: "10.0.0.0/8"
+empty: ""
+token: "***"
+`
+	assert.Equal(t, expected, GenerateYAMLFromValues(cfg, false))
+}
@@ -1,185 +1,1148 @@
 package yaml
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"log"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vsysa/configo/internal/parser/defaultValues"
+	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/options"
+	"github.com/vsysa/configo/internal/parser/walker"
+	"golang.org/x/text/width"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
+// durationType is used to detect time.Duration fields, which would otherwise
+// be treated as plain int64 values.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// timeType is used to detect time.Time fields, which are structs but are
+// configured as a single RFC3339 string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// bytesType is used to detect []byte fields, which are configured as a
+// single string rather than a list of numbers.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// textMarshalerType is used to detect types that know how to render themselves
+// as text, such as net.IP, time.Time or custom enums.
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// recursiveAnnotation marks fields of a recursive type, which are rendered
+// empty instead of being expanded again.
+const recursiveAnnotation = "(recursive)"
+
+// maskedValue replaces the value of fields tagged with `secret:"true"`.
+const maskedValue = `"***"`
+
+// secretPlaceholder replaces the value of secret fields with
+// WithSecretPlaceholders.
+const secretPlaceholder = `""`
+
 // fieldInfo represents a single line in the generated YAML template
-// along with an optional help (comment) text.
+// along with an optional help (comment) text. Block identifies the group of
+// sibling lines the line belongs to and is used for per-block alignment.
+// A Section line is a comment of its own, rendered as Line (the indentation)
+// followed by the Help comment, and takes no part in the alignment. A Literal
+// line is part of a block scalar: it is written as is, without a comment, and
+// takes no part in the alignment either. A Commented line belongs to an
+// optional field and is written behind a comment prefix.
 type fieldInfo struct {
-	Line string
-	Help string
+	Line      string
+	Help      string
+	Block     int
+	Section   bool
+	Literal   bool
+	Commented bool
+}
+
+// Alignment controls how help comments are aligned to a column.
+type Alignment = options.Alignment
+
+const (
+	// AlignGlobal aligns every comment of the template to a single column.
+	AlignGlobal = options.AlignGlobal
+
+	// AlignPerBlock aligns comments of sibling keys only.
+	AlignPerBlock = options.AlignPerBlock
+)
+
+// Options holds the settings of the YAML generator.
+type Options = options.Options
+
+// Option configures the YAML generator.
+type Option = options.Option
+
+// generator holds the state of a single template generation.
+type generator struct {
+	opts   Options
+	lines  []fieldInfo
+	blocks int
+
+	// inSecret is set while rendering the children of a secret field.
+	inSecret bool
+	// expanding holds the struct types being rendered, to detect recursive types.
+	expanding walker.Expanding
 }
 
 // GenerateYAMLTemplate generates a YAML template from a given configuration struct.
 // It scans the struct using reflection, collects information about each field,
 // and then produces YAML lines aligned with optional help text (comments).
-func GenerateYAMLTemplate(cfg interface{}, printDescription bool) string {
-	var lines []fieldInfo
+//
+// If the template can't be generated (see GenerateYAMLTemplateE), the error
+// is logged and an empty string is returned.
+func GenerateYAMLTemplate(cfg interface{}, printDescription bool, opts ...Option) string {
+	template, err := GenerateYAMLTemplateE(cfg, printDescription, opts...)
+	if err != nil {
+		log.Printf("configo: %v", err)
+		return ""
+	}
+	return template
+}
+
+// GenerateYAMLTemplateE works like GenerateYAMLTemplate, but returns an error
+// instead of panicking when cfg is not a struct (or a pointer to one), when
+// a field type can't be rendered, e.g. a map keyed by structs, or when a
+// `default` tag doesn't parse as its field type, e.g. `default:"many"` on an
+// int or `default:"a,b,"` with an empty item.
+func GenerateYAMLTemplateE(cfg interface{}, printDescription bool, opts ...Option) (template string, err error) {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("cannot generate template for %T: not a struct", cfg)
+	}
+
+	err = walker.Walk(t, func(field walker.Field) error {
+		if err := walker.CheckMapKeys(field.Type); err != nil {
+			return fmt.Errorf("%s: %w", field.Path, err)
+		}
+		// Text marshalers are rendered with their default as is.
+		if implementsTextMarshaler(field.Type) {
+			return nil
+		}
+		if err := defaultValues.CheckDefault(field.StructField); err != nil {
+			return fmt.Errorf("%s: %w", field.Path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot generate template for %T: %w", cfg, err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			template, err = "", fmt.Errorf("cannot generate template for %T: %v", cfg, r)
+		}
+	}()
+
+	g := &generator{opts: options.New(opts...)}
 
 	// First pass: Parse the struct and collect the lines
-	parseStructure(reflect.TypeOf(cfg), reflect.ValueOf(cfg), 0, &lines)
+	g.parseStructure(t, 0, g.newBlock())
 
 	// Second pass: Align the resulting YAML lines with help comments
-	return generateYAMLWithAlignment(lines, printDescription)
+	return generateYAMLWithAlignment(g.lines, printDescription, g.opts), nil
+}
+
+// newBlock allocates an identifier for a new group of sibling lines.
+func (g *generator) newBlock() int {
+	g.blocks++
+	return g.blocks
+}
+
+// addLine appends a line to the given block.
+func (g *generator) addLine(block int, line, help string) {
+	g.lines = append(g.lines, fieldInfo{Line: line, Help: help, Block: block})
+}
+
+// addSectionHelp adds the `section_help` comment of a field above its key,
+// if the field renders as a section.
+func (g *generator) addSectionHelp(block int, indentation string, tag reflect.StructTag, t reflect.Type) {
+	help := tag.Get("section_help")
+	if help == "" || !isSection(t) {
+		return
+	}
+	g.lines = append(g.lines, fieldInfo{Line: indentation, Help: help, Block: block, Section: true})
+}
+
+// parseMapExample renders the sample entry of a map of type mapType. Struct
+// values are expanded under the sample key. Maps of maps get a sample key per
+// level of nesting, each indented under the previous one, and only the
+// innermost entry carries the "Map example" comment.
+func (g *generator) parseMapExample(mapType reflect.Type, indent, level int, tag reflect.StructTag, secret bool) {
+	indentation := strings.Repeat("  ", indent)
+	exampleKey, exampleValue := getMapExample(tag, mapType.Key(), level)
+	exampleKey, exampleValue = plainScalar(exampleKey), plainScalar(exampleValue)
+	elemType := derefType(mapType.Elem())
+
+	switch elemType.Kind() {
+	case reflect.Map:
+		g.addLine(g.newBlock(), fmt.Sprintf("%s%s:", indentation, exampleKey), "")
+		g.parseMapExample(elemType, indent+1, level+1, tag, secret)
+	case reflect.Struct:
+		g.addLine(g.newBlock(), fmt.Sprintf("%s%s:", indentation, exampleKey), "Map example")
+		g.parseNested(elemType, indent+1, g.newBlock(), secret)
+	case reflect.Slice, reflect.Array:
+		// Lists are rendered as a single sample item under the key.
+		g.addLine(g.newBlock(), fmt.Sprintf("%s%s:", indentation, exampleKey), "Map example")
+		g.addListItem(derefType(elemType.Elem()), indent+1, level+1, tag, secret, exampleValue)
+	default:
+		if secret {
+			exampleValue = g.masked()
+		}
+		g.addLine(g.newBlock(), fmt.Sprintf("%s%s: %s", indentation, exampleKey, exampleValue), "Map example")
+	}
+}
+
+// addListItem renders a single sample item of a list with items of type
+// itemType, its dash at indent. Structs and maps are expanded one level below
+// the dash and nested lists get a sample item of their own there, so that
+// every level of nesting adds the same two spaces. Other items are written
+// as sample, e.g. "- example".
+func (g *generator) addListItem(itemType reflect.Type, indent, level int, tag reflect.StructTag, secret bool, sample string) {
+	indentation := strings.Repeat("  ", indent)
+
+	switch {
+	case itemType.Kind() == reflect.Struct && isSection(itemType):
+		g.addLine(g.newBlock(), indentation+"-", "")
+		g.parseNested(itemType, indent+1, g.newBlock(), secret)
+	case itemType.Kind() == reflect.Map:
+		g.addLine(g.newBlock(), indentation+"-", "")
+		g.parseMapExample(itemType, indent+1, level, tag, secret)
+	case isList(itemType):
+		g.addLine(g.newBlock(), indentation+"-", "")
+		g.addListItem(derefType(itemType.Elem()), indent+1, level, tag, secret, sample)
+	case secret:
+		g.addLine(g.newBlock(), fmt.Sprintf("%s- %s", indentation, g.masked()), "")
+	default:
+		g.addLine(g.newBlock(), fmt.Sprintf("%s- %s", indentation, sample), "")
+	}
+}
+
+// isList reports whether t is a slice or array configured as a list, unlike
+// []byte, which is a single string.
+func isList(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t != bytesType
+}
+
+// isFlow reports whether the field is tagged with `yamlstyle:"flow"`, which
+// renders slices and maps of scalars inline, e.g. `options: [1, 2, 3]`.
+func isFlow(tag reflect.StructTag) bool {
+	return tag.Get("yamlstyle") == "flow"
+}
+
+// flowSlice renders the default of a slice of scalars of kind as a flow
+// sequence, e.g. "[a, b]", with a sample item if there is no default.
+func (g *generator) flowSlice(kind reflect.Kind, defaultValue string, secret bool) string {
+	switch {
+	case secret:
+		return "[" + g.masked() + "]"
+	case defaultValue == "":
+		return "[example]"
+	}
+	items, isJSON := splitSliceDefault(defaultValue)
+	if !isJSON {
+		for i, item := range items {
+			if literal, ok := walker.FormatScalar(kind, item, walker.YAML); ok {
+				items[i] = literal
+				continue
+			}
+			items[i] = flowScalar(item)
+		}
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// flowMap renders the sample entry of a map of scalars as a flow mapping,
+// e.g. "{key: value}".
+func (g *generator) flowMap(tag reflect.StructTag, keyType reflect.Type, secret bool) string {
+	key, value := getMapExample(tag, keyType, 0)
+	value = flowScalar(value)
+	if secret {
+		value = g.masked()
+	}
+	return "{" + flowScalar(key) + ": " + value + "}"
+}
+
+// isPrintable reports whether s is valid UTF-8 made of printable characters.
+func isPrintable(s string) bool {
+	return utf8.ValidString(s) && strings.IndexFunc(s, func(r rune) bool { return !unicode.IsPrint(r) }) < 0
+}
+
+// quote renders a string value according to the StringQuoting option.
+func (g *generator) quote(s string) string {
+	switch g.opts.StringQuoting {
+	case options.QuoteNever:
+		return s
+	case options.QuoteWhenNeeded:
+		if needsQuotes(s) {
+			return strconv.Quote(s)
+		}
+		return s
+	default:
+		return strconv.Quote(s)
+	}
+}
+
+// yaml11Words are the plain scalars YAML 1.1 parsers read as booleans or
+// null, although YAML 1.2 reads most of them as strings.
+var yaml11Words = map[string]bool{
+	"y": true, "yes": true, "n": true, "no": true, "true": true, "false": true,
+	"on": true, "off": true, "null": true, "~": true,
+}
+
+// sexagesimal matches the base 60 numbers of YAML 1.1, e.g. "1:30".
+var sexagesimal = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+
+// needsQuotes reports whether a string must be quoted to be read back as the
+// same string by YAML 1.1 and 1.2 parsers: it is empty, has leading or
+// trailing spaces, looks like a number, a boolean or null, holds control
+// characters, or isn't a plain scalar, e.g. "a: b", "#tag" or "- item".
+func needsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s || yaml11Words[strings.ToLower(s)] {
+		return true
+	}
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64); err == nil || sexagesimal.MatchString(s) {
+		return true
+	}
+	if _, err := strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 0, 64); err == nil {
+		return true
+	}
+	if !isPrintable(s) {
+		return true
+	}
+	var value interface{}
+	err := yamlv3.Unmarshal([]byte(s), &value)
+	str, ok := value.(string)
+	return err != nil || !ok || str != s
+}
+
+// flowScalar works like plainScalar, but also quotes the characters that
+// end or start a collection in flow style.
+func flowScalar(s string) string {
+	if value := plainScalar(s); value != s || !strings.ContainsAny(s, ",[]{}") {
+		return value
+	}
+	return strconv.Quote(s)
+}
+
+// isLiteralText reports whether a string is rendered as a literal block: it
+// spans several lines, isn't blank and holds only printable characters and
+// tabs, which a literal block can't escape.
+func isLiteralText(s string) bool {
+	if !strings.Contains(s, "\n") || strings.TrimSpace(s) == "" || !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// plainScalar returns s as is when it reads back as the same plain YAML
+// scalar, e.g. "key" or "127.0.0.1", and quoted otherwise, e.g. "a: b",
+// "#tag" or "[x". Numbers and bools are kept as they are. Control characters
+// always need quotes.
+func plainScalar(s string) string {
+	if !isPrintable(s) {
+		return strconv.Quote(s)
+	}
+	var value interface{}
+	if err := yamlv3.Unmarshal([]byte(s), &value); err == nil {
+		switch v := value.(type) {
+		case string:
+			if v == s {
+				return s
+			}
+		case int, float64, bool:
+			return s
+		}
+	}
+	return strconv.Quote(s)
+}
+
+// scalar renders an item of a slice of kind, or the default of a field of
+// kind other than string: bools and numbers as native literals (see
+// walker.FormatScalar), anything else with plainScalar.
+func scalar(kind reflect.Kind, s string) string {
+	if literal, ok := walker.FormatScalar(kind, s, walker.YAML); ok {
+		return literal
+	}
+	return plainScalar(s)
+}
+
+// addLiteralBlock renders a multi-line string as a literal block scalar. The
+// help comment goes on the key line, and the chomping indicator keeps the
+// trailing newlines of the value exactly: "|-" for none, "|" for one and
+// "|+" for more.
+func (g *generator) addLiteralBlock(block int, indentation, fieldName, value, helpText string) {
+	header := "|"
+	switch {
+	case strings.HasSuffix(value, "\n\n"):
+		header = "|+"
+	case !strings.HasSuffix(value, "\n"):
+		header = "|-"
+	}
+	// Leading spaces of the first line would be taken as the indentation of
+	// the block, so it is given explicitly.
+	if strings.HasPrefix(value, " ") {
+		header = "|2" + header[1:]
+	}
+	g.addLine(block, fmt.Sprintf("%s%s: %s", indentation, fieldName, header), helpText)
+
+	for _, line := range strings.Split(strings.TrimSuffix(value, "\n"), "\n") {
+		if line != "" {
+			line = indentation + "  " + line
+		}
+		g.lines = append(g.lines, fieldInfo{Line: line, Block: block, Literal: true})
+	}
+}
+
+// parseNested renders a nested struct, masking all of its values if secret is set.
+func (g *generator) parseNested(t reflect.Type, indent int, block int, secret bool) {
+	inSecret := g.inSecret
+	g.inSecret = secret
+	g.parseStructure(t, indent, block)
+	g.inSecret = inSecret
 }
 
 // parseStructure recursively traverses a struct (and nested structs)
 // to build a list of fieldInfo lines that represent the YAML structure.
-func parseStructure(t reflect.Type, v reflect.Value, indent int, lines *[]fieldInfo) {
+// The fields of the struct are added to the given block.
+func (g *generator) parseStructure(t reflect.Type, indent int, block int) {
 	indentation := strings.Repeat("  ", indent)
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
+	if g.expanding == nil {
+		g.expanding = walker.Expanding{}
+	}
+	g.expanding[t] = true
+	defer delete(g.expanding, t)
 
-		// Skip unexported fields.
-		// In Go, an exported field has an uppercase first letter and an empty PkgPath.
-		if field.PkgPath != "" {
-			continue
+	// The lines of an optional field, including its nested lines, are
+	// commented out once the field is rendered.
+	commentFrom := -1
+	commentOut := func() {
+		if commentFrom < 0 {
+			return
+		}
+		for i := commentFrom; i < len(g.lines); i++ {
+			g.lines[i].Commented = true
 		}
+		commentFrom = -1
+	}
+	defer commentOut()
 
-		// Check if the field is intentionally ignored by `yaml:"-"` or `mapstructure:"-"`.
-		tag := field.Tag
-		if tag.Get("yaml") == "-" || tag.Get("mapstructure") == "-" {
+	for _, field := range walker.Fields(t, nil) {
+		commentOut()
+		if !g.opts.InProfiles(field.Tag) {
 			continue
 		}
+		if g.opts.OmitEmptyDefaults && g.isOmitted(field) {
+			continue
+		}
+		if g.opts.OptionalCommented && isOptional(field) {
+			commentFrom = len(g.lines)
+		}
+		// The first line of the field, to drop a struct left empty.
+		start := len(g.lines)
 
 		// Determine the YAML (and Viper) key name.
-		fieldName := getFieldName(field)
+		fieldName := field.Key
+		tag := field.Tag
 
 		// Retrieve default value (if any).
-		defaultValue := getDefaultValue(tag)
+		defaultValue := field.Default
+
+		// Pointer fields (e.g. optional sub-sections like *TLSConfig) are rendered
+		// as the pointed-to type.
+		fieldType := field.Type
+
+		// Retrieve help text (if any) together with field annotations.
+		helpText := g.buildComment(tag, fieldType)
+		leaf := fieldType.Kind() != reflect.Struct || fieldType == timeType || walker.TextType(fieldType)
+		switch {
+		case g.opts.SecretPlaceholders && isSecret(tag) && leaf:
+			helpText = appendSecretHint(helpText, tag, g.opts.EnvNaming)
+		case g.opts.EnvNames && leaf:
+			helpText = appendEnvName(helpText, tag, g.opts.EnvNaming)
+		}
 
-		// Retrieve help text (if any).
-		helpText := getHelpText(tag)
+		// Secret fields never echo their defaults. Nested sections keep their
+		// shape, but every value inside them is masked.
+		secret := g.inSecret || isSecret(tag)
 
-		switch field.Type.Kind() {
+		// time.Duration is an int64 under the hood, but in a template it is
+		// much more useful as a human-readable string like "30s".
+		if fieldType == durationType {
+			value := defaultValue
+			if value == "" {
+				value = "0s"
+			}
+			value = g.quote(value)
+			if secret {
+				value = g.masked()
+			}
+			g.addLine(block, fmt.Sprintf("%s%s: %s", indentation, fieldName, value), helpText)
+			continue
+		}
+
+		// time.Time fields take their default as is, e.g. an RFC3339 string.
+		// Without a default they are null rather than the zero time. The same
+		// goes for []byte fields, whose default is a raw or base64 string, and
+		// for text types like url.URL or net.IPNet.
+		if fieldType == timeType || fieldType == bytesType || walker.TextType(fieldType) {
+			value := "null"
+			if defaultValue != "" {
+				value = g.quote(defaultValue)
+			}
+			if secret {
+				value = g.masked()
+			}
+			g.addLine(block, fmt.Sprintf("%s%s: %s", indentation, fieldName, value), helpText)
+			continue
+		}
+
+		// Types implementing encoding.TextMarshaler are rendered as their text form:
+		// the default tag if present, or the marshaled zero value otherwise.
+		if implementsTextMarshaler(fieldType) {
+			value := "null"
+			if defaultValue != "" {
+				value = g.quote(defaultValue)
+			} else if text := marshalZeroValue(fieldType); text != "" {
+				value = g.quote(text)
+			}
+			if secret {
+				value = g.masked()
+			}
+			g.addLine(block, fmt.Sprintf("%s%s: %s", indentation, fieldName, value), helpText)
+			continue
+		}
+
+		// A template of a recursive type would never end, so a field referring
+		// back to a struct being rendered gets an empty value instead.
+		if g.expanding.Recursive(fieldType) {
+			value := "null"
+			switch fieldType.Kind() {
+			case reflect.Slice, reflect.Array:
+				value = "[]"
+			case reflect.Map:
+				value = "{}"
+			}
+			help := strings.TrimSpace(helpText + " " + recursiveAnnotation)
+			g.addLine(block, fmt.Sprintf("%s%s: %s", indentation, fieldName, value), help)
+			continue
+		}
+
+		g.addSectionHelp(block, indentation, tag, fieldType)
+
+		switch fieldType.Kind() {
 		case reflect.Struct:
 			// For nested structs, we append the struct name and recurse deeper.
-			*lines = append(*lines, fieldInfo{
-				Line: fmt.Sprintf("%s%s:", indentation, fieldName),
-				Help: helpText,
-			})
-			parseStructure(field.Type, v.Field(i), indent+1, lines)
+			g.addLine(block, fmt.Sprintf("%s%s:", indentation, fieldName), helpText)
+			header := len(g.lines)
+			g.parseNested(fieldType, indent+1, g.newBlock(), secret)
+			if g.opts.OmitEmptyDefaults && len(g.lines) == header && !isRequired(tag) {
+				g.lines = g.lines[:start]
+			}
 
 		case reflect.Slice:
+			if g.isEmptyCollection(field) {
+				g.addLine(block, fmt.Sprintf("%s%s: []", indentation, fieldName), helpText)
+				continue
+			}
+			// Without a default, slices of scalars show their `examples` tag.
+			items := defaultValue
+			if items == "" {
+				items = tag.Get("examples")
+			}
+			if isFlow(tag) && !isSection(fieldType) && derefType(fieldType.Elem()).Kind() != reflect.Map {
+				g.addLine(block, fmt.Sprintf("%s%s: %s", indentation, fieldName, g.flowSlice(derefType(fieldType.Elem()).Kind(), items, secret)), helpText)
+				continue
+			}
+
 			// For slices, we append the slice name and then handle struct slices vs. primitive slices.
-			*lines = append(*lines, fieldInfo{
-				Line: fmt.Sprintf("%s%s:", indentation, fieldName),
-				Help: helpText,
-			})
-
-			// If the slice element is another struct, we recurse into it using a zero value placeholder.
-			if field.Type.Elem().Kind() == reflect.Struct {
-				*lines = append(*lines, fieldInfo{
-					Line: fmt.Sprintf("%s  -", indentation),
-					Help: "",
-				})
-				parseStructure(field.Type.Elem(), reflect.Zero(field.Type.Elem()), indent+2, lines)
+			g.addLine(block, fmt.Sprintf("%s%s:", indentation, fieldName), helpText)
+			itemsBlock := g.newBlock()
+
+			// If the slice element is another struct (or pointer to one), we recurse
+			// into it using zero value placeholders, `example_count` of them.
+			// Maps get a single item holding their sample entry.
+			if elemType := derefType(fieldType.Elem()); elemType.Kind() == reflect.Struct {
+				for i := 0; i < exampleCount(tag); i++ {
+					g.addLine(itemsBlock, fmt.Sprintf("%s  -", indentation), "")
+					g.parseNested(elemType, indent+2, g.newBlock(), secret)
+				}
+			} else if elemType.Kind() == reflect.Map || isList(elemType) && items == "" {
+				// Lists of lists without a default get a nested sample list.
+				g.addListItem(elemType, indent+1, 0, tag, secret, "example")
+			} else if secret {
+				g.addLine(itemsBlock, fmt.Sprintf("%s  - %s", indentation, g.masked()), "")
 			} else {
-				// For slices of primitives, we try to split the default value by commas.
-				if defaultValue != "" {
-					defaultItems := strings.Split(defaultValue, ",")
+				// For slices of primitives, we split the default value into items.
+				// JSON items are valid YAML flow scalars, so they are used as is.
+				if items != "" {
+					defaultItems, isJSON := splitSliceDefault(items)
+					elemKind := derefType(fieldType.Elem()).Kind()
 					for _, item := range defaultItems {
-						item = strings.TrimSpace(item)
-						*lines = append(*lines, fieldInfo{
-							Line: fmt.Sprintf("%s  - %s", indentation, item),
-							Help: "",
-						})
+						if !isJSON {
+							item = scalar(elemKind, item)
+						}
+						g.addLine(itemsBlock, fmt.Sprintf("%s  - %s", indentation, item), "")
 					}
 				} else {
 					// If no default is set, provide a sample item.
-					*lines = append(*lines, fieldInfo{
-						Line: fmt.Sprintf("%s  - example", indentation),
-						Help: "",
-					})
+					g.addLine(itemsBlock, fmt.Sprintf("%s  - example", indentation), "")
 				}
 			}
 
 		case reflect.Map:
+			if g.isEmptyCollection(field) {
+				g.addLine(block, fmt.Sprintf("%s%s: {}", indentation, fieldName), helpText)
+				continue
+			}
+			if elemKind := derefType(fieldType.Elem()).Kind(); isFlow(tag) && elemKind != reflect.Map && elemKind != reflect.Slice && !isSection(fieldType) {
+				if helpText == "" {
+					helpText = "Map example"
+				}
+				g.addLine(block, fmt.Sprintf("%s%s: %s", indentation, fieldName, g.flowMap(tag, fieldType.Key(), secret)), helpText)
+				continue
+			}
+
 			// For maps, we just show a sample key and value.
-			*lines = append(*lines, fieldInfo{
-				Line: fmt.Sprintf("%s%s:", indentation, fieldName),
-				Help: helpText,
-			})
-			*lines = append(*lines, fieldInfo{
-				Line: fmt.Sprintf("%s  key: value", indentation),
-				Help: "Map example",
-			})
+			g.addLine(block, fmt.Sprintf("%s%s:", indentation, fieldName), helpText)
+			g.parseMapExample(fieldType, indent+1, 0, tag, secret)
+
+		case reflect.Interface:
+			// Opaque fields (interface{} / any) take their value as a YAML
+			// literal from the `example` tag, then from `default`.
+			value := getExample(tag)
+			if value == "" {
+				value = defaultValue
+			}
+			if value == "" {
+				value = "null"
+			}
+			if secret {
+				value = g.masked()
+			}
+
+			g.addLine(block, fmt.Sprintf("%s%s: %s", indentation, fieldName, value), helpText)
 
 		default:
+			// Multi-line strings, e.g. PEM certificates, can't be written as a
+			// quoted scalar: they become a literal block under the key line.
+			if fieldType.Kind() == reflect.String && isLiteralText(defaultValue) && !secret {
+				g.addLiteralBlock(block, indentation, fieldName, defaultValue, helpText)
+				continue
+			}
+
 			// For primitive fields, we assign the default or "null" if none is provided.
 			value := defaultValue
 			if value == "" {
 				value = "null"
-			} else if field.Type.Kind() == reflect.String {
+			} else if fieldType.Kind() == reflect.String {
 				// If the field is a string, we enclose the value in quotes.
-				value = fmt.Sprintf(`"%s"`, value)
+				value = g.quote(value)
+			} else if literal, ok := walker.FormatInteger(fieldType.Kind(), value, walker.IntBase(tag), walker.YAML); ok {
+				// Integers tagged with `format:"octal"` or `format:"hex"`
+				// keep their base, e.g. 0644 or 0x1F.
+				value = literal
+			} else {
+				// Numbers and bools are written as native literals, a
+				// malformed default is quoted when it would break the document.
+				value = scalar(fieldType.Kind(), value)
+			}
+			if secret {
+				value = g.masked()
 			}
 
-			*lines = append(*lines, fieldInfo{
-				Line: fmt.Sprintf("%s%s: %s", indentation, fieldName, value),
-				Help: helpText,
-			})
+			g.addLine(block, fmt.Sprintf("%s%s: %s", indentation, fieldName, value), helpText)
 		}
 	}
 }
 
 // generateYAMLWithAlignment aligns the generated YAML lines with
 // optional help comments on the right side.
-func generateYAMLWithAlignment(lines []fieldInfo, printDescription bool) string {
+//
+// With AlignGlobal the comment column is one past the longest line of the
+// template. With AlignPerBlock it is one past the longest line of the block
+// the line belongs to, so every nesting level gets its own column. With
+// CommentAbove the comments are written above their lines instead.
+func generateYAMLWithAlignment(lines []fieldInfo, printDescription bool, opts Options) string {
 	var builder strings.Builder
-	maxLength := 0
 
-	// Determine the maximum line length (without help text)
+	// Determine the maximum line length (without help text) per block.
+	// In global mode all lines share the same "block".
+	maxLength := make(map[int]int)
+	blockOf := func(line fieldInfo) int {
+		if opts.Alignment == AlignPerBlock {
+			return line.Block
+		}
+		return 0
+	}
+	// Commented lines keep their indentation behind the comment prefix.
+	text := func(line fieldInfo) string {
+		if !line.Commented {
+			return line.Line
+		}
+		return strings.TrimRight(opts.Comment(line.Line), " ")
+	}
+
 	for _, line := range lines {
-		if len(line.Line) > maxLength {
-			maxLength = len(line.Line)
+		if line.Section || line.Literal {
+			continue
+		}
+		if w := displayWidth(text(line)); w > maxLength[blockOf(line)] {
+			maxLength[blockOf(line)] = w
 		}
 	}
 
 	// Write lines with alignment
 	for _, line := range lines {
-		builder.WriteString(line.Line)
+		if line.Section {
+			if printDescription {
+				builder.WriteString(text(fieldInfo{Line: line.Line + opts.Comment(line.Help), Commented: line.Commented}) + "\n")
+			}
+			continue
+		}
+		if printDescription && line.Help != "" && opts.CommentPlacement == options.CommentAbove {
+			indentation := line.Line[:len(line.Line)-len(strings.TrimLeft(line.Line, " "))]
+			for _, comment := range opts.CommentLines(line.Help, indentation) {
+				builder.WriteString(text(fieldInfo{Line: comment, Commented: line.Commented}) + "\n")
+			}
+			builder.WriteString(text(line) + "\n")
+			continue
+		}
+		builder.WriteString(text(line))
 		if printDescription && line.Help != "" {
-			spaces := strings.Repeat(" ", maxLength-len(line.Line)+1)
-			builder.WriteString(spaces + "# " + line.Help)
+			builder.WriteString(opts.Padding(displayWidth(text(line)), maxLength[blockOf(line)]))
+			builder.WriteString(opts.Comment(line.Help))
 		}
 		builder.WriteString("\n")
 	}
 
-	return builder.String()
+	// The blank lines ending a final "|+" literal block are part of its
+	// value, so they are kept.
+	if n := len(lines); n > 0 && lines[n-1].Literal && lines[n-1].Line == "" {
+		return builder.String()
+	}
+	return opts.Finish(builder.String())
+}
+
+// displayWidth returns the number of terminal columns the string occupies:
+// wide and fullwidth East Asian characters take two columns, combining
+// marks none.
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case isWide(r):
+			w += 2
+		default:
+			w++
+		}
+	}
+	return w
+}
+
+// isWide reports whether the rune is a wide or fullwidth East Asian character.
+func isWide(r rune) bool {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return true
+	default:
+		return false
+	}
+}
+
+// implementsTextMarshaler reports whether t (or a pointer to t) implements
+// encoding.TextMarshaler.
+func implementsTextMarshaler(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
 }
 
-// getFieldName determines the field name to be used in YAML and for Viper lookup.
-// Priority:
-// 1. yaml:"..." tag (excluding "-")
-// 2. mapstructure:"..." tag (excluding "-")
-// 3. fallback to lowercase struct field name.
-func getFieldName(field reflect.StructField) string {
-	yamlName := field.Tag.Get("yaml")
-	if yamlName != "" && yamlName != "-" {
-		return strings.Split(yamlName, ",")[0]
+// marshalZeroValue returns the text form of the zero value of t, or an empty
+// string if it can't be marshaled.
+func marshalZeroValue(t reflect.Type) string {
+	marshaler, ok := reflect.New(t).Interface().(encoding.TextMarshaler)
+	if !ok {
+		return ""
+	}
+	text, err := marshaler.MarshalText()
+	if err != nil {
+		return ""
 	}
+	return string(text)
+}
 
-	mapstructureName := field.Tag.Get("mapstructure")
-	if mapstructureName != "" && mapstructureName != "-" {
-		return mapstructureName
+// splitSliceDefault splits the default value of a slice into its items.
+// A value starting with "[" is parsed as a JSON array and its items are
+// returned in JSON form, which allows items containing commas, e.g.
+// `default:"[\"a,b\", \"c\"]"`. Otherwise the value is split by commas.
+func splitSliceDefault(value string) (items []string, isJSON bool) {
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var raw []json.RawMessage
+		if err := json.Unmarshal([]byte(value), &raw); err == nil {
+			for _, item := range raw {
+				items = append(items, string(item))
+			}
+			return items, true
+		}
 	}
+	for _, item := range strings.Split(value, ",") {
+		items = append(items, strings.TrimSpace(item))
+	}
+	return items, false
+}
 
-	return strings.ToLower(field.Name)
+// getMapExample returns the sample key and value rendered for map fields,
+// customizable with the `example_key` and `example_value` tags. For maps of
+// maps, level selects the key of the nesting level from the comma-separated
+// `example_key`, e.g. `example_key:"region,key"`; unnamed levels get a key
+// matching keyType, e.g. "key" or "1" for integer keys.
+func getMapExample(tag reflect.StructTag, keyType reflect.Type, level int) (string, string) {
+	key, value := "", tag.Get("example_value")
+	if keys := strings.Split(tag.Get("example_key"), ","); level < len(keys) {
+		key = strings.TrimSpace(keys[level])
+	}
+	if key == "" {
+		key = walker.MapKeyExample(keyType)
+	}
+	if value == "" {
+		value = "value"
+	}
+	return key, value
 }
 
-// getDefaultValue extracts the default value from struct tags.
-// It first checks the "default" tag, then falls back to "placeholder".
-func getDefaultValue(tag reflect.StructTag) string {
-	defaultVal := tag.Get("default")
-	return defaultVal
+// exampleCount returns the number of sample elements rendered for a slice of
+// structs: the `example_count` tag, or 1 if it is missing or not a positive
+// number.
+func exampleCount(tag reflect.StructTag) int {
+	count, err := strconv.Atoi(tag.Get("example_count"))
+	if err != nil || count < 1 {
+		return 1
+	}
+	return count
+}
+
+// getExample returns the placeholder value from the `example` tag.
+func getExample(tag reflect.StructTag) string {
+	return tag.Get("example")
 }
 
 // getHelpText retrieves help (description) text from struct tags.
 func getHelpText(tag reflect.StructTag) string {
 	return tag.Get("help")
 }
+
+// buildComment composes the comment rendered next to a field: the help text
+// followed by annotations derived from other tags, e.g.
+//
+//	Log level (one of: debug, info, warn, error) (required)
+//	Max upload size (MB) (1-1024)
+//	TLS certificate (required when tls_enabled=true)
+//	Port (1-65535)
+//	Ratio (> 0, <= 1)
+//	Chunk size (multiple of 512)
+//	Tags (1-10 items)
+//	Contact (email)
+//	Enable the feature (true|false)
+//
+// A required field without any help text is marked as "REQUIRED". A field
+// tagged with `deprecated:"..."` gets "# DEPRECATED: ..." appended.
+func (g *generator) buildComment(tag reflect.StructTag, t reflect.Type) string {
+	var annotations []string
+	// The integer formats "octal" and "hex" only set how the value is
+	// written, the literal itself shows them.
+	if format := tag.Get("format"); format != "" && walker.IntBase(tag) == 10 {
+		annotations = append(annotations, "("+format+")")
+	}
+	if isNumeric(t.Kind()) {
+		if r := formatRange(constraint(tag, "min"), constraint(tag, "max")); r != "" {
+			annotations = append(annotations, "("+r+")")
+		}
+		if c := formatComparison(tag); c != "" {
+			annotations = append(annotations, "("+c+")")
+		}
+	}
+	if step := constraint(tag, "multipleof"); step != "" && isInteger(t.Kind()) {
+		annotations = append(annotations, "(multiple of "+step+")")
+	}
+	if l := formatLength(tag, t.Kind()); l != "" {
+		annotations = append(annotations, "("+l+")")
+	}
+	if values := getOneOf(tag); len(values) > 0 {
+		annotations = append(annotations, fmt.Sprintf("(one of: %s)", strings.Join(values, ", ")))
+	} else if g.opts.BoolHints && t.Kind() == reflect.Bool {
+		annotations = append(annotations, "(true|false)")
+	}
+
+	comment := strings.Join(append([]string{walker.HelpWithUnit(getHelpText(tag), tag.Get("unit"))}, annotations...), " ")
+	comment = strings.TrimSpace(comment)
+
+	if isRequired(tag) {
+		if comment == "" {
+			comment = "REQUIRED"
+		} else {
+			comment += " (required)"
+		}
+	} else if condition := formatCondition(constraint(tag, "required_if")); condition != "" {
+		if comment == "" {
+			comment = condition
+		} else {
+			comment += " (" + condition + ")"
+		}
+	}
+	if message := tag.Get("deprecated"); message != "" {
+		comment = appendAnnotation(comment, "DEPRECATED: "+message)
+	}
+	return comment
+}
+
+// formatCondition renders a `required_if:"field value"` condition as
+// "required when field=value".
+func formatCondition(condition string) string {
+	key, value, ok := strings.Cut(strings.TrimSpace(condition), " ")
+	if !ok {
+		return ""
+	}
+	return "required when " + key + "=" + strings.TrimSpace(value)
+}
+
+// formatRange renders the bounds of the `min` and `max` tags, e.g. "1-65535",
+// ">= 1" or "<= 100".
+func formatRange(min, max string) string {
+	switch {
+	case min != "" && max != "":
+		return min + "-" + max
+	case min != "":
+		return ">= " + min
+	case max != "":
+		return "<= " + max
+	default:
+		return ""
+	}
+}
+
+// formatComparison renders the bounds of the `gt`, `gte`, `lt` and `lte`
+// comparators, e.g. "> 0, <= 1" or ">= 1s".
+func formatComparison(tag reflect.StructTag) string {
+	var bounds []string
+	for _, c := range []struct{ name, symbol string }{{"gt", ">"}, {"gte", ">="}, {"lt", "<"}, {"lte", "<="}} {
+		if param := constraint(tag, c.name); param != "" {
+			bounds = append(bounds, c.symbol+" "+param)
+		}
+	}
+	return strings.Join(bounds, ", ")
+}
+
+// formatLength renders the length constraints of strings, slices and maps,
+// e.g. "32 chars", "8-64 chars" or "1-10 items". As in validation, `min` and
+// `max` bound the length of such fields; `minlen` and `maxlen` take
+// precedence over them.
+func formatLength(tag reflect.StructTag, kind reflect.Kind) string {
+	var unit string
+	switch kind {
+	case reflect.String:
+		unit = "chars"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = "items"
+	default:
+		return ""
+	}
+
+	if exact := constraint(tag, "len"); exact != "" {
+		return exact + " " + unit
+	}
+	min, max := constraint(tag, "minlen"), constraint(tag, "maxlen")
+	if min == "" {
+		min = constraint(tag, "min")
+	}
+	if max == "" {
+		max = constraint(tag, "max")
+	}
+	if r := formatRange(min, max); r != "" {
+		return r + " " + unit
+	}
+	return ""
+}
+
+// constraint returns the parameter of a validation constraint, taken from its
+// standalone tag, e.g. `min:"1"`, or from the `validate` tag, e.g.
+// `validate:"min=1"`.
+func constraint(tag reflect.StructTag, name string) string {
+	if value := tag.Get(name); value != "" {
+		return value
+	}
+	for _, rule := range strings.Split(tag.Get("validate"), ",") {
+		if key, param, ok := strings.Cut(strings.TrimSpace(rule), "="); ok && key == name {
+			return param
+		}
+	}
+	return ""
+}
+
+// isInteger reports whether the kind is a signed or unsigned integer kind.
+func isInteger(kind reflect.Kind) bool {
+	return isNumeric(kind) && kind != reflect.Float32 && kind != reflect.Float64
+}
+
+// isNumeric reports whether the kind is an integer or floating point kind.
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// appendEnvName adds the fixed environment variable name from the `env` tag
+// to the comment, e.g. "Database URL # env: DATABASE_URL". Fixed names are
+// never prefixed, but are uppercased unless naming keeps the case.
+func appendEnvName(comment string, tag reflect.StructTag, naming env.Naming) string {
+	name := fixedEnvName(tag, naming)
+	if name == "" {
+		return comment
+	}
+	return appendAnnotation(comment, "env: "+name)
+}
+
+// appendSecretHint adds "(set via NAME env var)" to the comment of a secret
+// field with a fixed `env:"NAME,fixed"` tag, pointing to the override of the value
+// left out of the template.
+func appendSecretHint(comment string, tag reflect.StructTag, naming env.Naming) string {
+	name := fixedEnvName(tag, naming)
+	if name == "" {
+		return comment
+	}
+	return strings.TrimSpace(comment + " (set via " + name + " env var)")
+}
+
+// fixedEnvName returns the fixed environment variable name set by the `env`
+// tag, see env.FixedName, or "" if the field has none.
+func fixedEnvName(tag reflect.StructTag, naming env.Naming) string {
+	name := env.FixedName(tag)
+	if name == "" {
+		return ""
+	}
+	if !naming.KeepCase {
+		name = strings.ToUpper(name)
+	}
+	return name
+}
+
+// masked returns the value written for secret fields: "***", or an empty
+// string with WithSecretPlaceholders.
+func (g *generator) masked() string {
+	if g.opts.SecretPlaceholders {
+		return secretPlaceholder
+	}
+	return maskedValue
+}
+
+// appendAnnotation adds a "# "-separated annotation to the comment.
+func appendAnnotation(comment, annotation string) string {
+	if comment == "" {
+		return annotation
+	}
+	return comment + " # " + annotation
+}
+
+// isOptional reports whether a field is rendered commented out with
+// WithOptionalCommented: a pointer to a struct without `default:"enabled"`
+// or a field tagged with `optional:"true"`, as long as it is not required.
+func isOptional(field walker.Field) bool {
+	if isRequired(field.Tag) {
+		return false
+	}
+	if field.Tag.Get("optional") == "true" {
+		return true
+	}
+	if field.Default == defaultValues.EnabledSection {
+		return false
+	}
+	return field.StructField.Type.Kind() == reflect.Ptr && field.Type.Kind() == reflect.Struct && isSection(field.Type)
+}
+
+// isOmitted reports whether a field is left out of the template with
+// OmitEmptyDefaults: it is not required, has no help text and its default is
+// the zero value. A nested struct is left out only once none of its fields
+// is rendered, see parseStructure.
+func (g *generator) isOmitted(field walker.Field) bool {
+	if isRequired(field.Tag) || getHelpText(field.Tag) != "" {
+		return false
+	}
+	if field.Kind == reflect.Struct && isSection(field.Type) && !g.expanding.Recursive(field.Type) {
+		return false
+	}
+	return defaultValues.IsZeroDefault(field.StructField)
+}
+
+// isEmptyCollection reports whether a slice or map field is rendered as an
+// empty collection with EmptyCollections: a slice of scalars without a
+// default or examples, or a map of scalars without example tags.
+func (g *generator) isEmptyCollection(field walker.Field) bool {
+	if !g.opts.EmptyCollections {
+		return false
+	}
+	elemKind := derefType(field.Type.Elem()).Kind()
+	if isSection(field.Type) || elemKind == reflect.Slice || elemKind == reflect.Map {
+		return false
+	}
+	if field.Kind == reflect.Map {
+		return field.Tag.Get("example_key") == "" && field.Tag.Get("example_value") == ""
+	}
+	return field.Default == "" && field.Tag.Get("examples") == ""
+}
+
+// isSection reports whether a field of type t renders as a section of its
+// own: a nested struct, or a slice or map of structs.
+func isSection(t reflect.Type) bool {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		t = derefType(t.Elem())
+	}
+	return t.Kind() == reflect.Struct && t != timeType && !implementsTextMarshaler(t) && !walker.TextType(t)
+}
+
+// isSecret reports whether the field is tagged with `secret:"true"`.
+func isSecret(tag reflect.StructTag) bool {
+	return tag.Get("secret") == "true"
+}
+
+// getOneOf returns the allowed values listed in the space-separated `oneof` tag.
+func getOneOf(tag reflect.StructTag) []string {
+	return strings.Fields(tag.Get("oneof"))
+}
+
+// isRequired reports whether the field is marked as mandatory either with
+// `required:"true"` or with `validate:"required"`.
+func isRequired(tag reflect.StructTag) bool {
+	if tag.Get("required") == "true" {
+		return true
+	}
+	for _, rule := range strings.Split(tag.Get("validate"), ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+	return false
+}
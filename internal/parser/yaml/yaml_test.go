@@ -1,9 +1,14 @@
 package yaml
 
 import (
+	"net"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vsysa/configo/internal/parser/options"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 func TestGenerateYAMLTemplate(t *testing.T) {
@@ -117,6 +122,35 @@ func TestGenerateYAMLTemplate_ArrayOfStructs(t *testing.T) {
 	assert.Equal(t, expected, yamlTemplate)
 }
 
+// Test that slices of pointers render like slices of values.
+func TestGenerateYAMLTemplate_ArrayOfPointers(t *testing.T) {
+	type Item struct {
+		Name  string `yaml:"name" default:"item1" help:"Item name"`
+		Value int    `yaml:"value"`
+	}
+	cfg := struct {
+		Items []*Item `yaml:"items" help:"Array of items"`
+	}{}
+
+	// Same output as for []Item in TestGenerateYAMLTemplate_ArrayOfStructs.
+	expectedItems := `items:            # Array of items
+  -
+    name: "item1" # Item name
+    value: null
+`
+	assert.Equal(t, expectedItems, GenerateYAMLTemplate(cfg, true))
+
+	ints := struct {
+		Ports []*int `yaml:"ports" default:"80,443"`
+	}{}
+
+	expected := `ports:
+  - 80
+  - 443
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(ints, true))
+}
+
 // Test YAML generation with maps.
 func TestGenerateYAMLTemplate_Map(t *testing.T) {
 	cfg := struct {
@@ -188,3 +222,1334 @@ nickname: null               # User nickname
 
 	assert.Equal(t, expected, yamlTemplate)
 }
+
+// Test YAML generation with time.Duration fields.
+func TestGenerateYAMLTemplate_Duration(t *testing.T) {
+	cfg := struct {
+		Timeout  time.Duration `yaml:"timeout" default:"30s" help:"Request timeout"`
+		Interval time.Duration `yaml:"interval" help:"Polling interval"`
+		Port     int           `yaml:"port" default:"8080" help:"The port number"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `timeout: "30s" # Request timeout
+interval: "0s" # Polling interval
+port: 8080     # The port number
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation falls back to json tags.
+func TestGenerateYAMLTemplate_JSONTag(t *testing.T) {
+	cfg := struct {
+		Host    string `json:"host,omitempty" default:"localhost"`
+		Port    int    `mapstructure:"port" json:"json_port" default:"8080"`
+		Timeout int    `json:",omitempty" default:"10"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `host: "localhost"
+port: 8080
+timeout: 10
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation marks required fields.
+func TestGenerateYAMLTemplate_Required(t *testing.T) {
+	cfg := struct {
+		Host     string `yaml:"host" required:"true" help:"The hostname"`
+		Password string `yaml:"password" validate:"required,min=8"`
+		Port     int    `yaml:"port" default:"8080" help:"The port number"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `host: null     # The hostname (required)
+password: null # (>= 8 chars) (required)
+port: 8080     # The port number
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation with pointer fields.
+func TestGenerateYAMLTemplate_Pointers(t *testing.T) {
+	type TLSConfig struct {
+		CertFile string `yaml:"cert_file" default:"cert.pem" help:"Certificate file"`
+		Enabled  bool   `yaml:"enabled"`
+	}
+	cfg := struct {
+		TLS     *TLSConfig `yaml:"tls" help:"Optional TLS settings"`
+		Retries *int       `yaml:"retries" default:"3" help:"Retry count"`
+		Name    *string    `yaml:"name" default:"app"`
+		Limit   *int       `yaml:"limit"`
+		Options *[]string  `yaml:"options" default:"a,b"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `tls:                    # Optional TLS settings
+  cert_file: "cert.pem" # Certificate file
+  enabled: null
+retries: 3              # Retry count
+name: "app"
+limit: null
+options:
+  - a
+  - b
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation flattens embedded structs without an explicit tag.
+func TestGenerateYAMLTemplate_EmbeddedStruct(t *testing.T) {
+	type BaseConfig struct {
+		Name     string `yaml:"name" default:"app" help:"Service name"`
+		LogLevel string `yaml:"log_level" default:"info"`
+	}
+	type Config struct {
+		BaseConfig
+		Extra string `yaml:"extra" default:"x"`
+	}
+	type TaggedConfig struct {
+		BaseConfig `yaml:"base"`
+		Extra      string `yaml:"extra" default:"x"`
+	}
+
+	expected := `name: "app"       # Service name
+log_level: "info"
+extra: "x"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(Config{}, true))
+
+	expectedTagged := `base:
+  name: "app"       # Service name
+  log_level: "info"
+extra: "x"
+`
+	assert.Equal(t, expectedTagged, GenerateYAMLTemplate(TaggedConfig{}, true))
+}
+
+// Test YAML generation of a struct mixing exported, unexported, squashed and
+// ignored fields.
+func TestGenerateYAMLTemplate_MixedFields(t *testing.T) {
+	type Common struct {
+		Name string `yaml:"name" default:"app"`
+	}
+	type Config struct {
+		Common  Common `mapstructure:",squash"`
+		Port    int    `yaml:"port" default:"8080"`
+		secret  string `default:"hidden"`
+		Ignored string `yaml:"-" default:"x"`
+		Skipped string `mapstructure:"-" default:"y"`
+	}
+
+	expected := `name: "app"
+port: 8080
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(Config{}, false))
+}
+
+// Test comment alignment with three nesting levels in both alignment modes.
+func TestGenerateYAMLTemplate_Alignment(t *testing.T) {
+	type Pool struct {
+		MaxConnections int `yaml:"max_connections" default:"10" help:"Pool size"`
+	}
+	type Database struct {
+		Host string `yaml:"host" default:"db" help:"Database host"`
+		Pool Pool   `yaml:"pool" help:"Connection pool"`
+	}
+	cfg := struct {
+		Name     string   `yaml:"name" default:"app" help:"Application name"`
+		Database Database `yaml:"database" help:"Database settings"`
+		Debug    bool     `yaml:"debug" default:"false" help:"Debug mode"`
+	}{}
+
+	expectedGlobal := `name: "app"             # Application name
+database:               # Database settings
+  host: "db"            # Database host
+  pool:                 # Connection pool
+    max_connections: 10 # Pool size
+debug: false            # Debug mode
+`
+	assert.Equal(t, expectedGlobal, GenerateYAMLTemplate(cfg, true))
+	assert.Equal(t, expectedGlobal, GenerateYAMLTemplate(cfg, true, options.WithAlignment(AlignGlobal)))
+
+	expectedPerBlock := `name: "app"  # Application name
+database:    # Database settings
+  host: "db" # Database host
+  pool:      # Connection pool
+    max_connections: 10 # Pool size
+debug: false # Debug mode
+`
+	assert.Equal(t, expectedPerBlock, GenerateYAMLTemplate(cfg, true, options.WithAlignment(AlignPerBlock)))
+}
+
+// Test YAML generation with maps of structs.
+func TestGenerateYAMLTemplate_MapOfStructs(t *testing.T) {
+	type Server struct {
+		Host string `yaml:"host" default:"localhost" help:"Server host"`
+		Port int    `yaml:"port" default:"8080"`
+	}
+
+	expected := `servers:              # Servers by name
+  key:                # Map example
+    host: "localhost" # Server host
+    port: 8080
+`
+
+	cfg := struct {
+		Servers map[string]Server `yaml:"servers" help:"Servers by name"`
+	}{}
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+
+	cfgPtr := struct {
+		Servers map[string]*Server `yaml:"servers" help:"Servers by name"`
+	}{}
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfgPtr, true))
+}
+
+// Test YAML generation with custom map examples.
+func TestGenerateYAMLTemplate_MapExampleTags(t *testing.T) {
+	type Zone struct {
+		Replicas int `yaml:"replicas" default:"2"`
+	}
+	cfg := struct {
+		Regions map[string]string `yaml:"regions" example_key:"region" example_value:"us-east-1" help:"Regions"`
+		Zones   map[string]Zone   `yaml:"zones" example_key:"eu-west-1a"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `regions:            # Regions
+  region: us-east-1 # Map example
+zones:
+  eu-west-1a:       # Map example
+    replicas: 2
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation documents allowed values.
+func TestGenerateYAMLTemplate_OneOf(t *testing.T) {
+	cfg := struct {
+		LogLevel string `yaml:"log_level" default:"info" oneof:"debug info warn error" help:"Log level"`
+		Mode     int    `yaml:"mode" oneof:"1 2" required:"true"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `log_level: "info" # Log level (one of: debug, info, warn, error)
+mode: null        # (one of: 1, 2) (required)
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// LogLevel is a custom enum type implementing encoding.TextMarshaler.
+type LogLevel int
+
+func (l LogLevel) MarshalText() ([]byte, error) {
+	switch l {
+	case 1:
+		return []byte("debug"), nil
+	default:
+		return []byte("info"), nil
+	}
+}
+
+// Test YAML generation with types implementing encoding.TextMarshaler.
+func TestGenerateYAMLTemplate_TextMarshaler(t *testing.T) {
+	cfg := struct {
+		Level     LogLevel  `yaml:"level" help:"Log level"`
+		Debug     LogLevel  `yaml:"debug" default:"debug"`
+		BindIP    net.IP    `yaml:"bind_ip" default:"127.0.0.1"`
+		PublicIP  net.IP    `yaml:"public_ip"`
+		ValidFrom time.Time `yaml:"valid_from"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `level: "info"        # Log level
+debug: "debug"
+bind_ip: "127.0.0.1"
+public_ip: null
+valid_from: null
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation masks secret fields.
+func TestGenerateYAMLTemplate_Secret(t *testing.T) {
+	type Credentials struct {
+		User     string `yaml:"user" default:"admin"`
+		Password string `yaml:"password" default:"changeme"`
+	}
+	cfg := struct {
+		Password    string            `yaml:"db_password" default:"qwerty" secret:"true" help:"Database password"`
+		Credentials Credentials       `yaml:"credentials" secret:"true"`
+		Keys        []string          `yaml:"keys" default:"a,b" secret:"true"`
+		Tokens      map[string]string `yaml:"tokens" secret:"true"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `db_password: "***" # Database password
+credentials:
+  user: "***"
+  password: "***"
+keys:
+  - "***"
+tokens:
+  key: "***"       # Map example
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation renders secret fields as empty placeholders with a
+// hint to their environment variable.
+func TestGenerateYAMLTemplate_SecretPlaceholders(t *testing.T) {
+	type Credentials struct {
+		User     string `yaml:"user" default:"admin" env:"db_user,fixed"`
+		Password string `yaml:"password" default:"changeme"`
+	}
+	cfg := struct {
+		Password    string            `yaml:"db_password" default:"qwerty" secret:"true" env:"DB_PASSWORD,fixed" help:"The DB password"`
+		Token       string            `yaml:"token" secret:"true" env:"API_TOKEN,fixed" required:"true"`
+		Key         string            `yaml:"key" default:"-----BEGIN KEY-----\nabc\n-----END KEY-----" secret:"true"`
+		Host        string            `yaml:"host" default:"localhost" env:"DB_HOST,fixed" help:"Host"`
+		Credentials Credentials       `yaml:"credentials" secret:"true" env:"creds"`
+		Keys        []string          `yaml:"keys" default:"a,b" secret:"true" env:"KEYS,fixed"`
+		Tokens      map[string]string `yaml:"tokens" secret:"true" yamlstyle:"flow"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true, options.WithSecretPlaceholders(), options.WithEnvNames())
+
+	expected := `db_password: ""   # The DB password (set via DB_PASSWORD env var)
+token: ""         # REQUIRED (set via API_TOKEN env var)
+key: ""
+host: "localhost" # Host # env: DB_HOST
+credentials:
+  user: ""        # env: DB_USER
+  password: ""
+keys:             # (set via KEYS env var)
+  - ""
+tokens: {key: ""} # Map example
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+	assert.NotContains(t, yamlTemplate, "qwerty")
+	assert.NotContains(t, yamlTemplate, "changeme")
+}
+
+func TestGenerateYAMLTemplate_Profiles(t *testing.T) {
+	type SSO struct {
+		Issuer string `yaml:"issuer" default:"https://sso.example.com"`
+		Audit  bool   `yaml:"audit" default:"true" profiles:"audit"`
+	}
+	cfg := struct {
+		Host  string `yaml:"host" default:"localhost" help:"Host"`
+		Seats int    `yaml:"seats" default:"100" profiles:"enterprise" help:"Licensed seats"`
+		SSO   SSO    `yaml:"sso" profiles:"enterprise,cloud"`
+		Forum string `yaml:"forum" default:"https://forum.example.com" profiles:"community"`
+	}{}
+
+	community := `host: "localhost"                  # Host
+forum: "https://forum.example.com"
+`
+	assert.Equal(t, community, GenerateYAMLTemplate(cfg, true, options.WithProfiles("community")))
+
+	cloud := `host: "localhost"                   # Host
+sso:
+  issuer: "https://sso.example.com"
+`
+	assert.Equal(t, cloud, GenerateYAMLTemplate(cfg, true, options.WithProfiles("cloud")))
+
+	// Without WithProfiles every field is rendered.
+	all := GenerateYAMLTemplate(cfg, true)
+	for _, key := range []string{"seats: 100", "audit: true", "forum:"} {
+		assert.Contains(t, all, key)
+	}
+
+	values := GenerateYAMLFromValues(cfg, false, options.WithProfiles("enterprise"))
+	assert.Equal(t, "host: \"\"\nseats: 0\nsso:\n  issuer: \"\"\n", values)
+}
+
+// Test that every level of nesting adds two spaces, whatever mix of structs,
+// maps and slices leads to it.
+func TestGenerateYAMLTemplate_DeepNesting(t *testing.T) {
+	type Leaf struct {
+		Name string `yaml:"name" default:"leaf" help:"Leaf name"`
+		Tags []int  `yaml:"tags" default:"1,2"`
+	}
+	type Level5 struct {
+		Leaves []Leaf `yaml:"leaves" help:"Leaves"`
+	}
+	type Level4 struct {
+		Groups map[string][]Level5 `yaml:"groups"`
+	}
+	type Level3 struct {
+		Items []Level4              `yaml:"items"`
+		Grid  [][]Leaf              `yaml:"grid"`
+		Lists map[string][][]string `yaml:"lists"`
+	}
+	type Level2 struct {
+		Level3 Level3 `yaml:"level3" help:"Level 3"`
+	}
+	type Config struct {
+		Level1 struct {
+			Level2 []Level2 `yaml:"level2"`
+		} `yaml:"level1"`
+	}
+
+	expected := `level1:
+  level2:
+    -
+      level3:                      # Level 3
+        items:
+          -
+            groups:
+              key:                 # Map example
+                -
+                  leaves:          # Leaves
+                    -
+                      name: "leaf" # Leaf name
+                      tags:
+                        - 1
+                        - 2
+        grid:
+          -
+            -
+              name: "leaf"         # Leaf name
+              tags:
+                - 1
+                - 2
+        lists:
+          key:                     # Map example
+            -
+              - value
+`
+	template, err := GenerateYAMLTemplateE(Config{}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, template)
+
+	// The template decodes into the struct it was generated from.
+	var cfg Config
+	assert.NoError(t, yamlv3.Unmarshal([]byte(template), &cfg))
+	assert.Equal(t, "leaf", cfg.Level1.Level2[0].Level3.Items[0].Groups["key"][0].Leaves[0].Name)
+	assert.Equal(t, []int{1, 2}, cfg.Level1.Level2[0].Level3.Grid[0][0].Tags)
+}
+
+// Test YAML generation keeps the base of octal and hex integers.
+func TestGenerateYAMLTemplate_IntBases(t *testing.T) {
+	cfg := struct {
+		Mode  uint32 `yaml:"mode" default:"644" format:"octal" help:"File mode"`
+		Flags int    `yaml:"flags" default:"0x1f" format:"hex"`
+		Mask  int    `yaml:"mask" default:"-0x10" format:"hex"`
+		Port  int    `yaml:"port" default:"0644"`
+		Umask int    `yaml:"umask" format:"octal"`
+	}{}
+
+	expected := `mode: 0644  # File mode
+flags: 0x1F
+mask: -0x10
+port: 644
+umask: null
+`
+
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+// Test YAML generation documents numeric ranges.
+func TestGenerateYAMLTemplate_Range(t *testing.T) {
+	cfg := struct {
+		Port    int     `yaml:"port" default:"8080" min:"1" max:"65535" help:"Port"`
+		Workers uint    `yaml:"workers" min:"1"`
+		Ratio   float64 `yaml:"ratio" max:"0.5" help:"Sampling ratio"`
+		Name    string  `yaml:"name" min:"1" help:"Not a number"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `port: 8080    # Port (1-65535)
+workers: null # (>= 1)
+ratio: null   # Sampling ratio (<= 0.5)
+name: null    # Not a number (>= 1 chars)
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation renders time.Time fields as their default or null.
+func TestGenerateYAMLTemplate_Time(t *testing.T) {
+	cfg := struct {
+		ValidFrom time.Time `yaml:"valid_from" default:"2024-01-01T00:00:00Z" help:"Start"`
+		Day       time.Time `yaml:"day" default:"2024-03-05" timeformat:"2006-01-02"`
+		Until     time.Time `yaml:"until" help:"End"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `valid_from: "2024-01-01T00:00:00Z" # Start
+day: "2024-03-05"
+until: null                        # End
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation renders []byte fields as strings.
+func TestGenerateYAMLTemplate_Bytes(t *testing.T) {
+	cfg := struct {
+		Key []byte `yaml:"key" default:"aGVsbG8=" encoding:"base64" help:"Key"`
+		Raw []byte `yaml:"raw" help:"Raw"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `key: "aGVsbG8=" # Key
+raw: null       # Raw
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation renders section help above nested sections.
+func TestGenerateYAMLTemplate_SectionHelp(t *testing.T) {
+	type Server struct {
+		Host string `yaml:"host" default:"localhost"`
+	}
+	type DB struct {
+		Host string `yaml:"host" default:"localhost" help:"Host"`
+		Port int    `yaml:"port" default:"5432"`
+	}
+	cfg := struct {
+		Name    string   `yaml:"name" section_help:"Not a section"`
+		DB      DB       `yaml:"db" section_help:"Database connection settings" help:"Primary DB"`
+		Servers []Server `yaml:"servers" section_help:"Upstream servers"`
+	}{}
+
+	expected := `name: null
+# Database connection settings
+db:                   # Primary DB
+  host: "localhost"   # Host
+  port: 5432
+# Upstream servers
+servers:
+  -
+    host: "localhost"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+
+	// Без описаний комментарии секций не выводятся
+	expected = `name: null
+db:
+  host: "localhost"
+  port: 5432
+servers:
+  -
+    host: "localhost"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, false))
+}
+
+// Test YAML generation renders a sample entry per level of nested maps.
+func TestGenerateYAMLTemplate_NestedMaps(t *testing.T) {
+	type Limits struct {
+		RPS int `yaml:"rps" default:"100"`
+	}
+	cfg := struct {
+		Regions map[string]map[string]string         `yaml:"regions" example_key:"region" help:"Per-region settings"`
+		Zones   map[string]map[string]*Limits        `yaml:"zones" example_key:"region,zone"`
+		Deep    map[string]map[string]map[string]int `yaml:"deep" example_value:"1"`
+	}{}
+
+	expected := `regions:       # Per-region settings
+  region:
+    key: value # Map example
+zones:
+  region:
+    zone:      # Map example
+      rps: 100
+deep:
+  key:
+    key:
+      key: 1   # Map example
+`
+
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+func TestGenerateYAMLTemplate_SliceOfMaps(t *testing.T) {
+	cfg := struct {
+		Labels  []map[string]string            `yaml:"labels" help:"Label sets"`
+		Weights []map[string]int               `yaml:"weights" example_key:"canary" example_value:"10"`
+		Groups  map[string][]map[string]string `yaml:"groups" example_key:"team,owner" example_value:"alice"`
+		Flow    []map[string]string            `yaml:"flow" yamlstyle:"flow"`
+	}{}
+
+	expected := `labels:            # Label sets
+  -
+    key: value     # Map example
+weights:
+  -
+    canary: 10     # Map example
+groups:
+  team:            # Map example
+    -
+      owner: alice # Map example
+flow:
+  -
+    key: value     # Map example
+`
+
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+	assert.Equal(t, expected, yamlTemplate)
+
+	var parsed struct {
+		Labels  []map[string]string            `yaml:"labels"`
+		Weights []map[string]int               `yaml:"weights"`
+		Groups  map[string][]map[string]string `yaml:"groups"`
+	}
+	assert.NoError(t, yamlv3.Unmarshal([]byte(yamlTemplate), &parsed))
+	assert.Equal(t, []map[string]string{{"key": "value"}}, parsed.Labels)
+	assert.Equal(t, []map[string]int{{"canary": 10}}, parsed.Weights)
+	assert.Equal(t, map[string][]map[string]string{"team": {{"owner": "alice"}}}, parsed.Groups)
+}
+
+func TestGenerateYAMLTemplate_NonStringMapKeys(t *testing.T) {
+	cfg := struct {
+		Priorities map[int]string          `yaml:"priorities" help:"Priority to name"`
+		Weights    map[uint8]map[bool]int  `yaml:"weights" example_value:"1"`
+		Named      map[int]string          `yaml:"named" example_key:"10"`
+		Flow       map[int]string          `yaml:"flow" yamlstyle:"flow"`
+		Servers    map[int]struct{ A int } `yaml:"servers"`
+	}{}
+
+	expected := `priorities:      # Priority to name
+  1: value       # Map example
+weights:
+  1:
+    true: 1      # Map example
+named:
+  10: value      # Map example
+flow: {1: value} # Map example
+servers:
+  1:             # Map example
+    a: null
+`
+
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+func TestGenerateYAMLTemplateE_UnsupportedMapKey(t *testing.T) {
+	type key struct{ ID int }
+	cfg := struct {
+		Nested struct {
+			Index map[key]string `yaml:"index"`
+		} `yaml:"nested"`
+	}{}
+
+	_, err := GenerateYAMLTemplateE(cfg, true)
+	assert.ErrorContains(t, err, "nested.index: unsupported map key type yaml.key")
+}
+
+// Test YAML generation renders multi-line defaults as literal blocks.
+func TestGenerateYAMLTemplate_MultilineDefault(t *testing.T) {
+	type Server struct {
+		Banner string `yaml:"banner" default:"Welcome!\nAuthorized use only" help:"Login banner"`
+		Port   int    `yaml:"port" default:"22" help:"Port"`
+	}
+	cfg := struct {
+		Server Server `yaml:"server"`
+		MOTD   string `yaml:"motd" default:"  indented\n\nlast\n"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `server:
+  banner: |- # Login banner
+    Welcome!
+    Authorized use only
+  port: 22   # Port
+motd: |2
+    indented
+
+  last
+`
+	assert.Equal(t, expected, yamlTemplate)
+
+	// The generated template must round-trip to the original values.
+	var parsed struct {
+		Server struct {
+			Banner string `yaml:"banner"`
+		} `yaml:"server"`
+		MOTD string `yaml:"motd"`
+	}
+	assert.NoError(t, yamlv3.Unmarshal([]byte(yamlTemplate), &parsed))
+	assert.Equal(t, "Welcome!\nAuthorized use only", parsed.Server.Banner)
+	assert.Equal(t, "  indented\n\nlast\n", parsed.MOTD)
+}
+
+// Test YAML generation comments out optional sections.
+func TestGenerateYAMLTemplate_OptionalCommented(t *testing.T) {
+	type TLS struct {
+		Cert string `yaml:"cert" default:"cert.pem" help:"Certificate file"`
+		Key  string `yaml:"key" help:"Key file"`
+	}
+	type Auth struct {
+		Token string `yaml:"token" required:"true"`
+	}
+	cfg := struct {
+		Host    string   `yaml:"host" default:"localhost" help:"Hostname"`
+		TLS     *TLS     `yaml:"tls" help:"TLS settings"`
+		Auth    *Auth    `yaml:"auth" required:"true"`
+		Proxies []string `yaml:"proxies" optional:"true" default:"a,b"`
+		Backup  *TLS     `yaml:"backup" default:"enabled"`
+	}{}
+
+	expected := `host: "localhost"    # Hostname
+# tls:               # TLS settings
+#   cert: "cert.pem" # Certificate file
+#   key: null        # Key file
+auth:                # REQUIRED
+  token: null        # REQUIRED
+# proxies:
+#   - a
+#   - b
+backup:
+  cert: "cert.pem"   # Certificate file
+  key: null          # Key file
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true, options.WithOptionalCommented(true)))
+
+	// Без опции шаблон не меняется
+	assert.NotContains(t, GenerateYAMLTemplate(cfg, true), "# tls:")
+}
+
+// Test YAML generation renders URLs and networks as strings.
+func TestGenerateYAMLTemplate_TextTypes(t *testing.T) {
+	cfg := struct {
+		BaseURL url.URL    `yaml:"base_url" default:"https://example.com/api" help:"API URL"`
+		BindIP  net.IP     `yaml:"bind_ip" default:"0.0.0.0"`
+		Allowed *net.IPNet `yaml:"allowed_cidr" help:"Allowed network"`
+	}{}
+
+	expected := `base_url: "https://example.com/api" # API URL
+bind_ip: "0.0.0.0"
+allowed_cidr: null                  # Allowed network
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+// Test YAML generation documents length constraints.
+func TestGenerateYAMLTemplate_Length(t *testing.T) {
+	cfg := struct {
+		APIKey   string   `yaml:"api_key" len:"32" help:"API key"`
+		Password string   `yaml:"password" minlen:"8" maxlen:"64"`
+		Name     string   `yaml:"name" maxlen:"10" help:"Name"`
+		Hosts    []string `yaml:"hosts" minlen:"1" help:"Hosts"`
+		Port     int      `yaml:"port" len:"4" help:"Not a length"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `api_key: null  # API key (32 chars)
+password: null # (8-64 chars)
+name: null     # Name (<= 10 chars)
+hosts:         # Hosts (>= 1 items)
+  - example
+port: null     # Not a length
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation documents min and max of strings and slices as length
+// constraints, from standalone tags and validate rules alike.
+func TestGenerateYAMLTemplate_MinMaxLength(t *testing.T) {
+	cfg := struct {
+		Tags    []string          `yaml:"tags" min:"1" max:"10" help:"Tags"`
+		Labels  map[string]string `yaml:"labels" validate:"max=5"`
+		Name    string            `yaml:"name" validate:"required,min=3,max=20" help:"Name"`
+		Code    string            `yaml:"code" min:"1" maxlen:"8"`
+		Workers int               `yaml:"workers" validate:"min=1,max=64" help:"Workers"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `tags:         # Tags (1-10 items)
+  - example
+labels:       # (<= 5 items)
+  key: value  # Map example
+name: null    # Name (3-20 chars) (required)
+code: null    # (1-8 chars)
+workers: null # Workers (1-64)
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation with fixed environment variable names.
+func TestGenerateYAMLTemplate_EnvNames(t *testing.T) {
+	type Database struct {
+		URL  string `yaml:"url" env:"DATABASE_URL,fixed" help:"Database URL"`
+		Pool int    `yaml:"pool" env:"db_pool,fixed"`
+		Name string `yaml:"name" env:"-" help:"Database name"`
+	}
+	cfg := struct {
+		Database Database `yaml:"database" env:"db"`
+	}{}
+
+	expected := `database:
+  url: null  # Database URL # env: DATABASE_URL
+  pool: null # env: DB_POOL
+  name: null # Database name
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true, options.WithEnvNames()))
+
+	expected = `database:
+  url: null  # Database URL
+  pool: null
+  name: null # Database name
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+// Test YAML generation with JSON-array slice defaults.
+func TestGenerateYAMLTemplate_JSONSliceDefault(t *testing.T) {
+	cfg := struct {
+		Queries []string `yaml:"queries" default:"[\"a,b\", \"c\"]"`
+		Ports   []int    `yaml:"ports" default:"[80, 443]"`
+		Tags    []string `yaml:"tags" default:"x, y"`
+	}{}
+
+	expected := `queries:
+  - "a,b"
+  - "c"
+ports:
+  - 80
+  - 443
+tags:
+  - x
+  - y
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, false))
+}
+
+// Test comment alignment with multibyte and wide characters.
+func TestGenerateYAMLTemplate_WideCharacters(t *testing.T) {
+	cfg := struct {
+		Name  string `yaml:"name" default:"東京" help:"都市名"`
+		City  string `yaml:"city" default:"Zürich" help:"City"`
+		Label string `yaml:"label" default:"ｔｅｓｔ" help:"Fullwidth"`
+		Port  int    `yaml:"port" default:"8080" help:"Port"`
+	}{}
+	yamlTemplate := GenerateYAMLTemplate(cfg, true)
+
+	expected := `name: "東京"      # 都市名
+city: "Zürich"    # City
+label: "ｔｅｓｔ" # Fullwidth
+port: 8080        # Port
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test YAML generation of deprecated fields.
+func TestGenerateYAMLTemplate_Deprecated(t *testing.T) {
+	cfg := struct {
+		OldVersion string `yaml:"old_version" help:"Version" deprecated:"use meta.version instead"`
+		Legacy     bool   `yaml:"legacy" deprecated:"no longer used"`
+	}{}
+
+	expected := `old_version: null # Version # DEPRECATED: use meta.version instead
+legacy: null      # DEPRECATED: no longer used
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+type OrderFirst struct {
+	B string `yaml:"b"`
+	C string `yaml:"c"`
+}
+
+type OrderSecond struct {
+	E string `yaml:"e"`
+}
+
+type orderConfig struct {
+	A string `yaml:"a"`
+	OrderFirst
+	D string `yaml:"d"`
+	*OrderSecond
+	F string `yaml:"f"`
+}
+
+// Test that flattened embedded fields keep the position of the embedded field.
+// An embedded pointer stays nested under its name, as mapstructure decodes it.
+func TestGenerateYAMLTemplate_FieldOrder(t *testing.T) {
+	expected := `a: null
+b: null
+c: null
+d: null
+ordersecond:
+  e: null
+f: null
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(orderConfig{}, false))
+}
+
+// Test YAML generation of interface{} fields.
+func TestGenerateYAMLTemplate_Interface(t *testing.T) {
+	cfg := struct {
+		Payload interface{}            `yaml:"payload" example:"{retries: 3}" help:"Pass-through payload"`
+		Value   any                    `yaml:"value" help:"Opaque value"`
+		Extra   map[string]interface{} `yaml:"extra" example_key:"feature" example_value:"true"`
+	}{}
+
+	expected := `payload: {retries: 3} # Pass-through payload
+value: null           # Opaque value
+extra:
+  feature: true       # Map example
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+// Test YAML generation with valid-literal hints for bool fields.
+func TestGenerateYAMLTemplate_BoolHints(t *testing.T) {
+	cfg := struct {
+		Enabled bool   `yaml:"enabled" default:"true" help:"Enable the feature"`
+		Debug   *bool  `yaml:"debug" required:"true"`
+		Name    string `yaml:"name" help:"Name"`
+	}{}
+
+	expected := `enabled: true # Enable the feature (true|false)
+debug: null   # (true|false) (required)
+name: null    # Name
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true, options.WithBoolHints()))
+
+	expected = `enabled: true # Enable the feature
+debug: null   # REQUIRED
+name: null    # Name
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+// Test that templates keep ${VAR} references in defaults unexpanded.
+func TestGenerateYAMLTemplate_UnexpandedDefault(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	cfg := struct {
+		DataDir string `yaml:"data_dir" default:"${HOME}/app"`
+	}{}
+
+	assert.Equal(t, "data_dir: \"${HOME}/app\"\n", GenerateYAMLTemplate(cfg, false))
+}
+
+type treeNode struct {
+	Name     string     `yaml:"name" default:"root"`
+	Children []treeNode `yaml:"children" help:"Child nodes"`
+	Next     *treeNode  `yaml:"next"`
+}
+
+// Test YAML generation renders a recursive type one level deep.
+func TestGenerateYAMLTemplate_Recursive(t *testing.T) {
+	cfg := struct {
+		Tree treeNode `yaml:"tree"`
+	}{}
+	yamlTemplate, err := GenerateYAMLTemplateE(cfg, true)
+	assert.NoError(t, err)
+
+	expected := `tree:
+  name: "root"
+  children: [] # Child nodes (recursive)
+  next: null   # (recursive)
+`
+
+	assert.Equal(t, expected, yamlTemplate)
+}
+
+// Test that unsupported input is reported as an error instead of a panic.
+func TestGenerateYAMLTemplateE_Errors(t *testing.T) {
+	for _, cfg := range []interface{}{nil, 42, map[string]string{}, (*int)(nil)} {
+		_, err := GenerateYAMLTemplateE(cfg, true)
+		assert.ErrorContains(t, err, "not a struct")
+	}
+
+	assert.Equal(t, "", GenerateYAMLTemplate(nil, true))
+
+	out, err := GenerateYAMLTemplateE(&struct {
+		Name string `yaml:"name"`
+	}{}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "name: null\n", out)
+}
+
+// Test that defaults that don't parse as their field type are reported by
+// GenerateYAMLTemplateE, with the path of the field.
+func TestGenerateYAMLTemplateE_MalformedDefaults(t *testing.T) {
+	type Server struct {
+		Port int `yaml:"port" default:"http"`
+	}
+	for _, tt := range []struct {
+		cfg interface{}
+		err string
+	}{
+		{struct {
+			Tags []string `yaml:"tags" default:"a,b,"`
+		}{}, `tags: cannot parse default value "a,b,": item 2 is empty`},
+		{struct {
+			Ports []int `yaml:"ports" default:"[unterminated"`
+		}{}, `ports: cannot parse default value "[unterminated" as []int`},
+		{struct {
+			Server *Server `yaml:"server"`
+		}{}, `server.port: cannot parse default value "http" as int`},
+		{struct {
+			Debug bool `yaml:"debug" default:"maybe"`
+		}{}, `debug: cannot parse default value "maybe" as bool`},
+	} {
+		_, err := GenerateYAMLTemplateE(tt.cfg, true)
+		assert.ErrorContains(t, err, tt.err)
+	}
+}
+
+// Test a custom comment prefix and tab alignment.
+func TestGenerateYAMLTemplate_CommentStyle(t *testing.T) {
+	cfg := struct {
+		Host string `yaml:"host" default:"localhost" help:"The hostname"`
+		Port int    `yaml:"port" default:"8080" help:"The port"`
+	}{}
+
+	expected := "host: \"localhost\"\t## The hostname\n" +
+		"port: 8080\t\t## The port\n"
+
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true, options.WithCommentPrefix("##"), options.WithAlignChar('\t')))
+}
+
+// Test that non-empty YAML templates end with exactly one newline, unless
+// WithoutTrailingNewline is given, and empty structs give an empty template.
+func TestGenerateYAMLTemplate_TrailingNewline(t *testing.T) {
+	type Empty struct{}
+	mapOnly := struct {
+		Labels map[string]string `yaml:"labels"`
+	}{}
+	nested := struct {
+		Server struct {
+			TLS struct {
+				Cert string `yaml:"cert" default:"cert.pem"`
+			} `yaml:"tls"`
+		} `yaml:"server"`
+	}{}
+
+	assert.Equal(t, "", GenerateYAMLTemplate(Empty{}, true))
+	assert.Equal(t, "", GenerateYAMLTemplate(struct{ Empty }{}, true))
+	assert.Equal(t, "", GenerateYAMLFromValues(Empty{}, true))
+	assert.Equal(t, "labels:\n  key: value # Map example\n", GenerateYAMLTemplate(mapOnly, true))
+	assert.Equal(t, "labels: {}\n", GenerateYAMLFromValues(mapOnly, true))
+	assert.Equal(t, "server:\n  tls:\n    cert: \"cert.pem\"\n", GenerateYAMLTemplate(nested, true))
+
+	omit := options.WithoutTrailingNewline()
+	assert.Equal(t, "labels:\n  key: value # Map example", GenerateYAMLTemplate(mapOnly, true, omit))
+	assert.Equal(t, "labels: {}", GenerateYAMLFromValues(mapOnly, true, omit))
+	assert.Equal(t, "", GenerateYAMLTemplate(Empty{}, true, omit))
+
+	// The blank lines of a final "|+" block belong to its value.
+	keep := struct {
+		MOTD string `yaml:"motd" default:"hello\n\n"`
+	}{}
+	template := GenerateYAMLTemplate(keep, true)
+	assert.Equal(t, "motd: |+\n  hello\n\n", template)
+	var parsed struct {
+		MOTD string `yaml:"motd"`
+	}
+	assert.NoError(t, yamlv3.Unmarshal([]byte(template), &parsed))
+	assert.Equal(t, "hello\n\n", parsed.MOTD)
+}
+
+// Test YAML generation leaves out fields with zero defaults.
+func TestGenerateYAMLTemplate_OmitEmptyDefaults(t *testing.T) {
+	type Limits struct {
+		Burst   int  `yaml:"burst" default:"0"`
+		Enabled bool `yaml:"enabled"`
+	}
+	type TLS struct {
+		Cert string `yaml:"cert" default:"cert.pem"`
+		Key  string `yaml:"key"`
+	}
+	cfg := struct {
+		Host    string            `yaml:"host" default:"localhost"`
+		Debug   bool              `yaml:"debug" default:"false"`
+		Verbose bool              `yaml:"verbose" help:"Verbose logging"`
+		Token   string            `yaml:"token" required:"true"`
+		Timeout time.Duration     `yaml:"timeout" default:"0s"`
+		Retries *int              `yaml:"retries" default:"3"`
+		Tags    []string          `yaml:"tags"`
+		Labels  map[string]string `yaml:"labels"`
+		Limits  Limits            `yaml:"limits" section_help:"Rate limits"`
+		TLS     *TLS              `yaml:"tls"`
+	}{}
+
+	expected := `host: "localhost"
+verbose: null      # Verbose logging
+token: null        # REQUIRED
+retries: 3
+tls:
+  cert: "cert.pem"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true, options.WithOmitEmptyDefaults(true)))
+
+	// Without the option every field is rendered.
+	assert.Contains(t, GenerateYAMLTemplate(cfg, true, options.WithOmitEmptyDefaults(false)), "burst: 0")
+}
+
+// Test YAML generation documents multipleof constraints of integers.
+func TestGenerateYAMLTemplate_MultipleOf(t *testing.T) {
+	cfg := struct {
+		ChunkSize int     `yaml:"chunk_size" default:"4096" multipleof:"512" help:"Chunk size"`
+		Pages     uint    `yaml:"pages" validate:"min=8,multipleof=8"`
+		Ratio     float64 `yaml:"ratio" multipleof:"2"`
+	}{}
+
+	expected := `chunk_size: 4096 # Chunk size (multiple of 512)
+pages: null      # (>= 8) (multiple of 8)
+ratio: null
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+// Test YAML generation documents gt, gte, lt and lte comparators.
+func TestGenerateYAMLTemplate_Comparators(t *testing.T) {
+	cfg := struct {
+		Ratio   float64       `yaml:"ratio" default:"0.5" gt:"0" lte:"1" help:"Ratio"`
+		Workers int           `yaml:"workers" default:"4" validate:"min=1,lt=100"`
+		Timeout time.Duration `yaml:"timeout" default:"30s" validate:"gte=1s"`
+		Name    string        `yaml:"name" default:"app" gt:"3"`
+	}{}
+
+	expected := `ratio: 0.5     # Ratio (> 0, <= 1)
+workers: 4     # (>= 1) (< 100)
+timeout: "30s" # (>= 1s)
+name: "app"
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+func TestGenerateYAMLTemplate_RequiredIf(t *testing.T) {
+	cfg := struct {
+		TLSEnabled bool   `yaml:"tls_enabled" default:"false"`
+		TLSCert    string `yaml:"tls_cert" required_if:"tls_enabled true" help:"TLS certificate"`
+		TLSKey     string `yaml:"tls_key" validate:"required_if=tls_enabled true"`
+		Token      string `yaml:"token" required:"true" required_if:"tls_enabled true"`
+	}{}
+
+	expected := `tls_enabled: false
+tls_cert: null     # TLS certificate (required when tls_enabled=true)
+tls_key: null      # required when tls_enabled=true
+token: null        # REQUIRED
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+func TestGenerateYAMLTemplate_FlowStyle(t *testing.T) {
+	cfg := struct {
+		Options []int             `yaml:"options" default:"1,2,3" yamlstyle:"flow" help:"Options"`
+		Tags    []string          `yaml:"tags" default:"web,a b,x:y,[z]" yamlstyle:"flow"`
+		Hosts   []string          `yaml:"hosts" yamlstyle:"flow"`
+		Labels  map[string]string `yaml:"labels" yamlstyle:"flow" help:"Labels"`
+		Env     map[string]string `yaml:"env" example_key:"LOG_LEVEL" example_value:"debug" yamlstyle:"flow"`
+		Block   []string          `yaml:"block" default:"a"`
+		Servers []struct {
+			Host string `yaml:"host"`
+		} `yaml:"servers" yamlstyle:"flow"`
+	}{}
+
+	expected := `options: [1, 2, 3]           # Options
+tags: [web, a b, x:y, "[z]"]
+hosts: [example]
+labels: {key: value}         # Labels
+env: {LOG_LEVEL: debug}      # Map example
+block:
+  - a
+servers:
+  -
+    host: null
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+func TestGenerateYAMLTemplate_EmptyCollections(t *testing.T) {
+	cfg := struct {
+		Options  []string          `yaml:"options" help:"Extra options"`
+		Tags     []string          `yaml:"tags" default:"a"`
+		Flow     []int             `yaml:"flow" yamlstyle:"flow"`
+		Settings map[string]string `yaml:"settings" help:"Settings"`
+		Env      map[string]string `yaml:"env" example_key:"LOG_LEVEL"`
+		Servers  []struct {
+			Host string `yaml:"host"`
+		} `yaml:"servers"`
+	}{}
+
+	expected := `options: []        # Extra options
+tags:
+  - a
+flow: []
+settings: {}       # Settings
+env:
+  LOG_LEVEL: value # Map example
+servers:
+  -
+    host: null
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true, options.WithEmptyCollections(true)))
+	assert.Contains(t, GenerateYAMLTemplate(cfg, true, options.WithEmptyCollections(false)), "  - example")
+}
+
+func TestGenerateYAMLTemplate_Unit(t *testing.T) {
+	cfg := struct {
+		MaxUpload int `yaml:"max_upload" default:"10" unit:"MB" min:"1" max:"1024" help:"Max upload size"`
+		Timeout   int `yaml:"timeout" default:"500" unit:"ms"`
+	}{}
+
+	expected := `max_upload: 10 # Max upload size (MB) (1-1024)
+timeout: 500   # (ms)
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
+
+func TestGenerateYAMLTemplate_StringQuoting(t *testing.T) {
+	cfg := struct {
+		Host    string        `yaml:"host" default:"localhost"`
+		Enabled string        `yaml:"enabled" default:"true"`
+		Port    string        `yaml:"port" default:"123"`
+		Switch  string        `yaml:"switch" default:"on"`
+		Padded  string        `yaml:"padded" default:" x "`
+		Colon   string        `yaml:"colon" default:"a: b"`
+		Path    string        `yaml:"path" default:"C:\\temp"`
+		Timeout time.Duration `yaml:"timeout" default:"30s"`
+	}{}
+
+	assert.Contains(t, GenerateYAMLTemplate(cfg, false), `host: "localhost"`)
+	assert.Equal(t, `host: localhost
+enabled: "true"
+port: "123"
+switch: "on"
+padded: " x "
+colon: "a: b"
+path: C:\temp
+timeout: 30s
+`, GenerateYAMLTemplate(cfg, false, options.WithStringQuoting(options.QuoteWhenNeeded)))
+	assert.Equal(t, `host: localhost
+enabled: true
+port: 123
+switch: on
+padded:  x 
+colon: a: b
+path: C:\temp
+timeout: 30s
+`, GenerateYAMLTemplate(cfg, false, options.WithStringQuoting(options.QuoteNever)))
+}
+
+func TestGenerateYAMLTemplate_Examples(t *testing.T) {
+	type Endpoint struct {
+		URL     string `yaml:"url" default:"https://example.com"`
+		Retries int    `yaml:"retries" default:"3"`
+	}
+	cfg := struct {
+		Hosts     []string   `yaml:"hosts" examples:"a.com,b.com" help:"Hosts"`
+		Ports     []int      `yaml:"ports" default:"80" examples:"8080,8443"`
+		Flow      []string   `yaml:"flow" examples:"x,y" yamlstyle:"flow"`
+		Endpoints []Endpoint `yaml:"endpoints" example_count:"2"`
+		Single    []Endpoint `yaml:"single" example_count:"zero"`
+	}{}
+
+	expected := `hosts:                         # Hosts
+  - a.com
+  - b.com
+ports:
+  - 80
+flow: [x, y]
+endpoints:
+  -
+    url: "https://example.com"
+    retries: 3
+  -
+    url: "https://example.com"
+    retries: 3
+single:
+  -
+    url: "https://example.com"
+    retries: 3
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+	assert.Contains(t, GenerateYAMLTemplate(cfg, true, options.WithEmptyCollections(true)), "  - a.com")
+}
+
+func TestGenerateYAMLTemplate_CommentPlacement(t *testing.T) {
+	type TLS struct {
+		Cert string `yaml:"cert" default:"cert.pem" help:"Path to the certificate file used for incoming connections"`
+	}
+	cfg := struct {
+		Host   string   `yaml:"host" default:"localhost" help:"Hostname"`
+		Banner string   `yaml:"banner" help:"Line one\nline two"`
+		TLS    TLS      `yaml:"tls" section_help:"TLS settings" help:"TLS"`
+		Backup *TLS     `yaml:"backup"`
+		Tags   []string `yaml:"tags" default:"a" help:"Tags"`
+	}{}
+
+	inline := `host: "localhost"  # Hostname
+banner: null       # Line one line two
+# TLS settings
+tls:               # TLS
+  cert: "cert.pem" # Path to the certificate file used for incoming connections
+backup:
+  cert: "cert.pem" # Path to the certificate file used for incoming connections
+tags:              # Tags
+  - a
+`
+	assert.Equal(t, inline, GenerateYAMLTemplate(cfg, true))
+	assert.Equal(t, inline, GenerateYAMLTemplate(cfg, true, options.WithCommentPlacement(options.CommentInline)))
+
+	above := `# Hostname
+host: "localhost"
+# Line one
+# line two
+banner: null
+# TLS settings
+# TLS
+tls:
+  # Path to the certificate file used for incoming connections
+  cert: "cert.pem"
+backup:
+  # Path to the certificate file used for incoming connections
+  cert: "cert.pem"
+# Tags
+tags:
+  - a
+`
+	assert.Equal(t, above, GenerateYAMLTemplate(cfg, true, options.WithCommentPlacement(options.CommentAbove)))
+
+	// Длинные комментарии переносятся по словам с учётом отступа
+	wrapped := struct {
+		TLS TLS `yaml:"tls"`
+	}{}
+	assert.Equal(t, `tls:
+  # Path to the certificate
+  # file used for incoming
+  # connections
+  cert: "cert.pem"
+`, GenerateYAMLTemplate(wrapped, true, options.WithCommentPlacement(options.CommentAbove), options.WithCommentWidth(27)))
+
+	commented := GenerateYAMLTemplate(cfg, true, options.WithCommentPlacement(options.CommentAbove), options.WithOptionalCommented(true))
+	assert.Contains(t, commented, "# backup:\n#   # Path to the certificate file used for incoming connections\n#   cert: \"cert.pem\"\n")
+
+	assert.NotContains(t, GenerateYAMLTemplate(cfg, false, options.WithCommentPlacement(options.CommentAbove)), "#")
+}
+
+func TestGenerateYAMLTemplate_Format(t *testing.T) {
+	cfg := struct {
+		Contact string `yaml:"contact" format:"email" help:"Contact"`
+		ID      string `yaml:"id" format:"uuid" required:"true"`
+		Mode    uint32 `yaml:"mode" default:"755" format:"octal" help:"File mode"`
+	}{}
+
+	expected := `contact: null # Contact (email)
+id: null      # (uuid) (required)
+mode: 0755    # File mode
+`
+	assert.Equal(t, expected, GenerateYAMLTemplate(cfg, true))
+}
@@ -0,0 +1,654 @@
+package configo
+
+import (
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/walker"
+	"github.com/vsysa/configo/validation"
+)
+
+var (
+	RequiredFieldsError     error = errors.New("required fields are not set")
+	InvalidValueError       error = errors.New("invalid config value")
+	UnknownKeysError        error = errors.New("unknown config keys")
+	ConfigFileNotFoundError error = errors.New("config file not found")
+)
+
+// timeType is used to detect time.Time fields, which are structs but are
+// configured as a single string.
+var timeType = reflect.TypeOf(time.Time{})
+
+// bytesType is used to detect []byte fields, which are configured as a single
+// string instead of a list of numbers.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// urlType is used to detect url.URL fields, which are configured as a single
+// string.
+var urlType = reflect.TypeOf(url.URL{})
+
+// LoaderOption configures a single call to Load.
+type LoaderOption func(*loader)
+
+type loader struct {
+	configFilePath       string
+	envNaming            env.Naming
+	envCaseInsensitive   bool
+	defaultsFilePath     string
+	defaultsFileRequired bool
+	strict               bool
+	flags                *pflag.FlagSet
+	profile              string
+	rootKey              string
+	aggregate            bool
+	logger               *slog.Logger
+	name                 string
+	searchPaths          []string
+	formats              []Format
+	optionalFile         bool
+}
+
+// WithFile sets the path of the YAML file to read. Defaults to DefaultConfigPath.
+// See WithName to search for the file instead.
+func WithFile(path string) LoaderOption {
+	return func(l *loader) {
+		l.configFilePath = path
+	}
+}
+
+// WithEnvPrefix prepends prefix to every derived environment variable name,
+// so that `meta.version` is read from PREFIX_META_VERSION.
+func WithEnvPrefix(prefix string) LoaderOption {
+	return func(l *loader) {
+		l.envNaming.Prefix = prefix
+	}
+}
+
+// WithEnvSeparator sets the separator joining the prefix and the names of
+// nested fields, e.g. "__" to read `meta.version` from PREFIX__META__VERSION.
+// The default is "_".
+func WithEnvSeparator(separator string) LoaderOption {
+	return func(l *loader) {
+		l.envNaming.Separator = separator
+	}
+}
+
+// WithEnvKeepCase reads environment variables named exactly as the tags (or
+// Go field names) are written, e.g. myapp_meta_version, instead of
+// uppercasing the names.
+func WithEnvKeepCase() LoaderOption {
+	return func(l *loader) {
+		l.envNaming.KeepCase = true
+	}
+}
+
+// WithEnvCaseInsensitive makes the environment variables match regardless of
+// case when enabled, so that `meta.version` is read from META_VERSION,
+// Meta_Version or meta_version. The prefix of WithEnvPrefix and explicit
+// `env` tag names are matched regardless of case too. Loading fails with
+// ConfigParsingError if several variables of the environment match the same
+// field. The default is an exact match.
+func WithEnvCaseInsensitive(enabled bool) LoaderOption {
+	return func(l *loader) {
+		l.envCaseInsensitive = enabled
+	}
+}
+
+// WithDefaultsFile reads baseline values from a YAML file. They override the
+// `default` tags and are overridden by the config file and environment
+// variables. A missing defaults file is ignored unless
+// WithDefaultsFileRequired is given too.
+func WithDefaultsFile(path string) LoaderOption {
+	return func(l *loader) {
+		l.defaultsFilePath = path
+	}
+}
+
+// WithDefaultsFileRequired makes a missing defaults file an error.
+func WithDefaultsFileRequired() LoaderOption {
+	return func(l *loader) {
+		l.defaultsFileRequired = true
+	}
+}
+
+// WithStrict makes Load fail with UnknownKeysError when the file has keys that
+// don't map to any struct field, e.g. a typo like "meta.verson".
+func WithStrict() LoaderOption {
+	return func(l *loader) {
+		l.strict = true
+	}
+}
+
+// WithFlags applies the flags registered by RegisterFlags on fs that were set
+// on the command line, with the highest precedence: they override the
+// environment, the file and the defaults. Call it after fs is parsed. Unlike
+// BindFlags, the flag values take part in the required checks and are
+// reported by Result.Source.
+func WithFlags(fs *pflag.FlagSet) LoaderOption {
+	return func(l *loader) {
+		l.flags = fs
+	}
+}
+
+// WithLogger makes Load log what it does to logger: the files read, the source
+// of every field at debug level, e.g. an environment variable overriding the
+// file, and the warnings, such as deprecated keys, at warn level. Values are
+// never logged. Nothing is logged by default.
+func WithLogger(logger *slog.Logger) LoaderOption {
+	return func(l *loader) {
+		l.logger = logger
+	}
+}
+
+// Warning is a non-fatal problem found while loading a config.
+type Warning struct {
+	// Path is the dotted bind key of the field, e.g. "meta.version".
+	Path string
+	// Message is a human-readable description of the problem.
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Path, w.Message)
+}
+
+// Result holds the details of a successful Load.
+type Result struct {
+	// Warnings lists the non-fatal problems found, e.g. deprecated keys
+	// set in the file.
+	Warnings []Warning
+
+	// File is the path of the config file read, e.g. the one found with
+	// WithName, or empty if none was read.
+	File string
+
+	// sources holds the source of every field by bind key, see Source.
+	sources map[string]origin
+}
+
+// Load populates cfg, which must be a non-nil pointer to a struct, from the
+// YAML file, environment variables and `default` tags.
+//
+// Precedence: flags (see WithFlags) > env > file > defaults file (see
+// WithDefaultsFile) > default.
+// Fields tagged with `required:"true"` or
+// `validate:"required"` that are not set by any of the sources are reported
+// together in a single RequiredFieldsError. If the struct has a Validate()
+// error method, it is called after decoding. Values violating tag constraints
+// (see validation.Validate) are reported in a single InvalidValueError, which
+// wraps a validation.ViolationsError listing every violation.
+//
+// With WithStrict, keys of the file that don't map to a struct field are
+// reported together in a single UnknownKeysError.
+//
+// Use LoadWithResult to get the warnings collected while loading and the
+// source of every value.
+func Load(cfg interface{}, opts ...LoaderOption) error {
+	_, err := LoadWithResult(cfg, opts...)
+	return err
+}
+
+// LoadWithResult works like Load and also returns the details of the load.
+// Keys of fields tagged with `deprecated:"..."` that are set in the file are
+// reported as warnings; such fields are still decoded as usual.
+func LoadWithResult(cfg interface{}, opts ...LoaderOption) (*Result, error) {
+	l := newLoader(opts)
+	return l.load(cfg, l.readFile)
+}
+
+// readConfigFile reads the config file at path into v.
+func readConfigFile(v *viper.Viper, path string) error {
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	return nil
+}
+
+// newLoader builds a loader with the defaults and the given options applied.
+func newLoader(opts []LoaderOption) *loader {
+	l := &loader{
+		configFilePath: DefaultConfigPath,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// load populates cfg from the config read by read, which is called once the
+// defaults and the environment variables are bound.
+func (l *loader) load(cfg interface{}, read func(v *viper.Viper) error) (*Result, error) {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected a non-nil pointer to a struct, got %T", ConfigParsingError, cfg)
+	}
+
+	v := viper.New()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	if err := bindDefaultsAndEnv(v, cfg, l.envNaming); err != nil {
+		return nil, err
+	}
+	envs := env.GetEnvsWithNaming(cfg, l.envNaming)
+	if l.envCaseInsensitive {
+		var err error
+		if envs, err = resolveEnvCase(envs, os.Environ()); err != nil {
+			return nil, err
+		}
+		for _, e := range envs {
+			if err := v.BindEnv(e.BindKey, e.EnvVar); err != nil {
+				return nil, fmt.Errorf("error binding env var: %w", err)
+			}
+		}
+	}
+	var defaults *viper.Viper
+	if l.defaultsFilePath != "" {
+		var err error
+		if defaults, err = setFileDefaults(v, l.defaultsFilePath, l.defaultsFileRequired); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.rootKey != "" {
+		read = readRootKey(l.rootKey, read)
+	}
+	if l.profile != "" {
+		if err := readProfile(v, l.profile, read); err != nil {
+			return nil, err
+		}
+	} else if err := read(v); err != nil {
+		return nil, err
+	}
+	l.logFiles(v, defaults)
+
+	aliasWarnings, aliases, err := applyAliases(v, rv.Elem().Type())
+	if err != nil {
+		return nil, err
+	}
+
+	var flags map[string]string
+	if l.flags != nil {
+		var err error
+		if flags, err = setFlags(v, rv.Elem().Type(), l.flags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := setSectionDefaults(v, cfg); err != nil {
+		return nil, err
+	}
+
+	sources, err := collectSources(v, defaults, cfg, envs, flags)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ConfigParsingError, err)
+	}
+	l.logSources(sources)
+
+	// With aggregate set, the problems found from here on are collected and
+	// reported together, see LoadAndValidate. skip holds the keys whose
+	// violations would only repeat a problem already reported.
+	var failures []error
+	var skip []string
+
+	var missing []string
+	for _, key := range requiredBindKeys(rv.Elem().Type(), "") {
+		if !v.IsSet(key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		err := fmt.Errorf("%w: %s", RequiredFieldsError, strings.Join(missing, ", "))
+		if !l.aggregate {
+			return nil, err
+		}
+		failures = append(failures, err)
+		skip = append(skip, missing...)
+	}
+
+	if err := parseEncodedFields(v, rv.Elem().Type(), ""); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Warnings: aliasWarnings, File: v.ConfigFileUsed(), sources: sources}
+	for _, d := range deprecatedFields(rv.Elem().Type(), "") {
+		if v.InConfig(d.BindKey) {
+			result.Warnings = append(result.Warnings, Warning{Path: d.BindKey, Message: "deprecated: " + d.Message})
+		}
+	}
+	l.logWarnings(result.Warnings)
+
+	var metadata mapstructure.Metadata
+	collectMetadata := func(c *mapstructure.DecoderConfig) {
+		c.Metadata = &metadata
+	}
+	if err := v.Unmarshal(cfg, decoderConfig, collectMetadata); err != nil {
+		if !l.aggregate {
+			return nil, fmt.Errorf("Unable to decode into struct: %v", err)
+		}
+		failures = append(failures, fmt.Errorf("%w: unable to decode into struct: %w", ConfigParsingError, err))
+		skip = append(skip, decodeFailures(v, rv.Elem().Type(), "")...)
+	}
+	allocateEnabledSections(rv)
+	if unused := withoutAliases(metadata.Unused, aliases); l.strict && len(unused) > 0 {
+		err := fmt.Errorf("%w: %s", UnknownKeysError, strings.Join(unknownKeys(unused), ", "))
+		if !l.aggregate {
+			return nil, err
+		}
+		failures = append(failures, err)
+	}
+
+	if err := validation.Validate(cfg); err != nil {
+		if !l.aggregate {
+			return nil, fmt.Errorf("%w: %w", InvalidValueError, err)
+		}
+		if err := skipViolations(err, skip); err != nil {
+			failures = append(failures, fmt.Errorf("%w: %w", InvalidValueError, err))
+		}
+	}
+	if len(failures) > 0 {
+		return nil, errors.Join(failures...)
+	}
+
+	if err := callValidateIfExists(cfg); err != nil {
+		return nil, fmt.Errorf("Validation error: %w", err)
+	}
+
+	return result, nil
+}
+
+// setFileDefaults registers the values of the YAML file at path as defaults,
+// replacing the `default` tag values of the same keys, and returns the file
+// read. A missing file is skipped, giving nil, unless required is set.
+func setFileDefaults(v *viper.Viper, path string, required bool) (*viper.Viper, error) {
+	fv := viper.New()
+	fv.SetConfigFile(path)
+	if err := fv.ReadInConfig(); err != nil {
+		if !required && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading defaults file: %w", err)
+	}
+
+	for _, key := range fv.AllKeys() {
+		v.SetDefault(key, fv.Get(key))
+	}
+	return fv, nil
+}
+
+// resolveEnvCase renames the variables of envs to the names they are set
+// under in environ, a list of "NAME=value" entries like os.Environ, ignoring
+// case. Variables that aren't set keep their derived names. Several entries
+// matching the same variable are an error.
+func resolveEnvCase(envs []env.EnvInfo, environ []string) ([]env.EnvInfo, error) {
+	names := make(map[string][]string)
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		names[strings.ToUpper(name)] = append(names[strings.ToUpper(name)], name)
+	}
+
+	resolved := make([]env.EnvInfo, 0, len(envs))
+	for _, e := range envs {
+		switch candidates := names[strings.ToUpper(e.EnvVar)]; len(candidates) {
+		case 0:
+		case 1:
+			e.EnvVar = candidates[0]
+		default:
+			slices.Sort(candidates)
+			return nil, fmt.Errorf("%w: environment variables %s all match %s (%s)",
+				ConfigParsingError, strings.Join(candidates, ", "), e.EnvVar, e.BindKey)
+		}
+		resolved = append(resolved, e)
+	}
+	return resolved, nil
+}
+
+// unknownKeys converts the unused keys reported by mapstructure, such as
+// "servers[0].hots", to sorted dotted paths like "servers.0.hots".
+func unknownKeys(unused []string) []string {
+	keys := make([]string, 0, len(unused))
+	for _, key := range unused {
+		key = strings.NewReplacer("[", ".", "]", "").Replace(key)
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// parseEncodedFields parses the values of fields whose text form depends on
+// their tags, descending into nested structs: time.Time fields with a custom
+// `timeformat` layout and []byte fields tagged with `encoding:"base64"`. The
+// parsed values replace the strings read from the file or the environment,
+// which the decoder could only parse as RFC3339 times or raw bytes.
+func parseEncodedFields(v *viper.Viper, t reflect.Type, parentBindKey string) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		if isFlattenedField(field) {
+			if err := parseEncodedFields(v, field.Type, parentBindKey); err != nil {
+				return err
+			}
+			continue
+		}
+
+		bindKey := childBindKey(field, parentBindKey)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		switch {
+		case isRegisteredType(fieldType):
+			// Registered types parse their string form themselves.
+			continue
+
+		case fieldType == timeType:
+			layout := field.Tag.Get("timeformat")
+			value, ok := v.Get(bindKey).(string)
+			if layout == "" || !ok {
+				continue
+			}
+			parsed, err := time.Parse(layout, value)
+			if err != nil {
+				return fmt.Errorf("%w: %s: cannot parse %q as time with layout %q: %w", ConfigParsingError, bindKey, value, layout, err)
+			}
+			v.Set(bindKey, parsed)
+
+		case fieldType == bytesType:
+			value, ok := v.Get(bindKey).(string)
+			if field.Tag.Get("encoding") != "base64" || !ok {
+				continue
+			}
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return fmt.Errorf("%w: %s: cannot decode value as base64: %w", ConfigParsingError, bindKey, err)
+			}
+			v.Set(bindKey, decoded)
+
+		case walker.IntBase(field.Tag) != 10 && isInteger(fieldType.Kind()):
+			value, ok := v.Get(bindKey).(string)
+			if !ok {
+				continue
+			}
+			parsed, err := parseInteger(fieldType, value, walker.IntBase(field.Tag))
+			if err != nil {
+				return fmt.Errorf("%w: %s: cannot parse %q as %s: %w", ConfigParsingError, bindKey, value, field.Tag.Get("format"), err)
+			}
+			v.Set(bindKey, parsed)
+
+		case field.Type.Kind() == reflect.Struct:
+			if err := parseEncodedFields(v, field.Type, bindKey); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isInteger reports whether kind is a signed or unsigned integer kind.
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseInteger parses s as an integer of type t written in base, see
+// walker.ParseInt. Signed integers are returned as int64, unsigned ones as
+// uint64.
+func parseInteger(t reflect.Type, s string, base int) (interface{}, error) {
+	if t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64 {
+		return walker.ParseUint(s, base, t.Bits())
+	}
+	return walker.ParseInt(s, base, t.Bits())
+}
+
+// textUnmarshalerType is used to detect structs decoded from a single string,
+// like time.Time.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// requiredBindKeys collects the bind keys of all fields marked as required,
+// descending into nested structs. Structs configured as a single string, like
+// time.Time or url.URL, are required as a whole.
+func requiredBindKeys(t reflect.Type, parentBindKey string) []string {
+	var keys []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported and ignored fields
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		if isFlattenedField(field) {
+			keys = append(keys, requiredBindKeys(field.Type, parentBindKey)...)
+			continue
+		}
+
+		bindKey := childBindKey(field, parentBindKey)
+
+		if field.Type.Kind() == reflect.Struct && !isTextStruct(field.Type) {
+			keys = append(keys, requiredBindKeys(field.Type, bindKey)...)
+			continue
+		}
+
+		if isRequiredField(field.Tag) {
+			keys = append(keys, bindKey)
+		}
+	}
+
+	return keys
+}
+
+// isTextStruct reports whether the struct type t is configured as a single
+// string, like time.Time or url.URL, rather than field by field.
+func isTextStruct(t reflect.Type) bool {
+	return isTextValue(t) || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// deprecatedField is a field tagged with `deprecated:"..."`.
+type deprecatedField struct {
+	BindKey string
+	Message string
+}
+
+// deprecatedFields collects the fields tagged as deprecated, descending into
+// nested structs.
+func deprecatedFields(t reflect.Type, parentBindKey string) []deprecatedField {
+	var fields []deprecatedField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported and ignored fields
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		if isFlattenedField(field) {
+			fields = append(fields, deprecatedFields(field.Type, parentBindKey)...)
+			continue
+		}
+
+		bindKey := childBindKey(field, parentBindKey)
+
+		if message := field.Tag.Get("deprecated"); message != "" {
+			fields = append(fields, deprecatedField{BindKey: bindKey, Message: message})
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			fields = append(fields, deprecatedFields(field.Type, bindKey)...)
+		}
+	}
+
+	return fields
+}
+
+// isFlattenedField reports whether the fields of a struct field are decoded
+// at the parent level, exactly like the templates flatten them: an embedded
+// struct without a yaml, mapstructure or json name, or a field tagged with
+// `mapstructure:",squash"`. See walker.Flattened.
+func isFlattenedField(field reflect.StructField) bool {
+	return walker.Flattened(field)
+}
+
+// isSquashed reports whether a struct field is tagged with
+// `mapstructure:",squash"`.
+func isSquashed(field reflect.StructField) bool {
+	return field.Type.Kind() == reflect.Struct && slices.Contains(strings.Split(field.Tag.Get("mapstructure"), ",")[1:], "squash")
+}
+
+// childBindKey builds the dotted Viper key of a struct field, using the
+// mapstructure tag or the lowercase field name.
+func childBindKey(field reflect.StructField, parentBindKey string) string {
+	bindKey := strings.ToLower(field.Name)
+	if msKey := strings.Split(field.Tag.Get("mapstructure"), ",")[0]; msKey != "" {
+		bindKey = msKey
+	}
+	if parentBindKey != "" {
+		bindKey = parentBindKey + "." + bindKey
+	}
+	return bindKey
+}
+
+// isRequiredField reports whether the field is marked with `required:"true"`
+// or `validate:"required"`.
+func isRequiredField(tag reflect.StructTag) bool {
+	if tag.Get("required") == "true" {
+		return true
+	}
+	for _, rule := range strings.Split(tag.Get("validate"), ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+	return false
+}
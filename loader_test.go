@@ -0,0 +1,1100 @@
+package configo
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vsysa/configo/validation"
+)
+
+type LoaderMetaConfig struct {
+	Version string `mapstructure:"version" default:"1.0"`
+	Build   string `mapstructure:"build" default:"dev"`
+}
+
+type LoaderTestConfig struct {
+	Host string           `mapstructure:"host" default:"localhost"`
+	Port int              `mapstructure:"port" default:"8080"`
+	Meta LoaderMetaConfig `mapstructure:"meta"`
+}
+
+// Проверка приоритета источников: env > file > default
+func TestLoad_Precedence(t *testing.T) {
+	yamlContent := `
+host: "filehost"
+meta:
+  version: "2.0"
+`
+	configPath := createTempYAMLConfig(t, yamlContent)
+	defer os.Remove(configPath)
+
+	setEnv(t, "META_VERSION", "3.0")
+	defer unsetEnv(t, "META_VERSION")
+
+	var cfg LoaderTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Host != "filehost" {
+		t.Errorf("Expected Host to be 'filehost', got '%s'", cfg.Host)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("Expected Port to be 8080, got %d", cfg.Port)
+	}
+	if cfg.Meta.Version != "3.0" {
+		t.Errorf("Expected Meta.Version to be '3.0', got '%s'", cfg.Meta.Version)
+	}
+	if cfg.Meta.Build != "dev" {
+		t.Errorf("Expected Meta.Build to be 'dev', got '%s'", cfg.Meta.Build)
+	}
+}
+
+func TestLoad_EnvPrefix(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "host: filehost\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "MYAPP_HOST", "envhost")
+	setEnv(t, "PORT", "9090")
+	defer unsetEnv(t, "MYAPP_HOST")
+	defer unsetEnv(t, "PORT")
+
+	var cfg LoaderTestConfig
+	if err := Load(&cfg, WithFile(configPath), WithEnvPrefix("myapp")); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Host != "envhost" {
+		t.Errorf("Expected Host to be 'envhost', got '%s'", cfg.Host)
+	}
+	// Без префикса переменная не должна учитываться
+	if cfg.Port != 8080 {
+		t.Errorf("Expected Port to be 8080, got %d", cfg.Port)
+	}
+}
+
+func TestLoad_EnvSeparator(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "MYAPP__META__VERSION", "2.0")
+	setEnv(t, "MYAPP_META_BUILD", "ignored")
+	defer unsetEnv(t, "MYAPP__META__VERSION")
+	defer unsetEnv(t, "MYAPP_META_BUILD")
+
+	var cfg LoaderTestConfig
+	if err := Load(&cfg, WithFile(configPath), WithEnvPrefix("myapp"), WithEnvSeparator("__")); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Meta.Version != "2.0" {
+		t.Errorf("Expected Meta.Version to be '2.0', got '%s'", cfg.Meta.Version)
+	}
+	// Переменная с одинарным разделителем не учитывается
+	if cfg.Meta.Build != "dev" {
+		t.Errorf("Expected Meta.Build to be 'dev', got '%s'", cfg.Meta.Build)
+	}
+}
+
+func TestLoad_EnvKeepCase(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "myapp_meta_version", "2.0")
+	defer unsetEnv(t, "myapp_meta_version")
+
+	var cfg LoaderTestConfig
+	if err := Load(&cfg, WithFile(configPath), WithEnvPrefix("myapp"), WithEnvKeepCase()); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Meta.Version != "2.0" {
+		t.Errorf("Expected Meta.Version to be '2.0', got '%s'", cfg.Meta.Version)
+	}
+}
+
+func TestLoad_EnvCaseInsensitive(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "MyApp_Meta_Version", "2.0")
+	setEnv(t, "myapp_host", "envhost")
+	defer unsetEnv(t, "MyApp_Meta_Version")
+	defer unsetEnv(t, "myapp_host")
+
+	var cfg LoaderTestConfig
+	result, err := LoadWithResult(&cfg, WithFile(configPath), WithEnvPrefix("myapp"), WithEnvCaseInsensitive(true))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Meta.Version != "2.0" {
+		t.Errorf("Expected Meta.Version to be '2.0', got '%s'", cfg.Meta.Version)
+	}
+	if cfg.Host != "envhost" {
+		t.Errorf("Expected Host to be 'envhost', got '%s'", cfg.Host)
+	}
+	if source, origin := result.Source("meta.version"); source != SourceEnv || origin != "MyApp_Meta_Version" {
+		t.Errorf("Expected meta.version from MyApp_Meta_Version, got %v (%s)", source, origin)
+	}
+
+	// По умолчанию регистр имеет значение
+	cfg = LoaderTestConfig{}
+	if err := Load(&cfg, WithFile(configPath), WithEnvPrefix("myapp")); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Meta.Version != "1.0" {
+		t.Errorf("Expected Meta.Version to be '1.0', got '%s'", cfg.Meta.Version)
+	}
+}
+
+func TestLoad_EnvCaseInsensitiveAmbiguous(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "META_VERSION", "2.0")
+	setEnv(t, "meta_version", "3.0")
+	defer unsetEnv(t, "META_VERSION")
+	defer unsetEnv(t, "meta_version")
+
+	var cfg LoaderTestConfig
+	err := Load(&cfg, WithFile(configPath), WithEnvCaseInsensitive(true))
+	if !errors.Is(err, ConfigParsingError) {
+		t.Fatalf("Expected ConfigParsingError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "META_VERSION, meta_version") {
+		t.Errorf("Expected the error to list the candidates, got %v", err)
+	}
+}
+
+func TestLoad_RequiredFields(t *testing.T) {
+	type Config struct {
+		Host     string `mapstructure:"host" required:"true"`
+		Password string `mapstructure:"password" validate:"required"`
+		Port     int    `mapstructure:"port" required:"true" default:"8080"`
+		Meta     struct {
+			Version string `mapstructure:"version" required:"true"`
+		} `mapstructure:"meta"`
+	}
+
+	configPath := createTempYAMLConfig(t, "host: filehost\n")
+	defer os.Remove(configPath)
+
+	var cfg Config
+	err := Load(&cfg, WithFile(configPath))
+	if !errors.Is(err, RequiredFieldsError) {
+		t.Fatalf("Expected RequiredFieldsError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "password, meta.version") {
+		t.Errorf("Expected error to list missing fields, got %v", err)
+	}
+}
+
+// Структуры, задаваемые одной строкой, обязательны целиком
+func TestLoad_RequiredTextFields(t *testing.T) {
+	type Config struct {
+		Started  time.Time `mapstructure:"started" required:"true"`
+		Endpoint url.URL   `mapstructure:"endpoint" required:"true"`
+	}
+
+	configPath := createTempYAMLConfig(t, "endpoint: https://example.com\n")
+	defer os.Remove(configPath)
+
+	var cfg Config
+	err := Load(&cfg, WithFile(configPath))
+	if !errors.Is(err, RequiredFieldsError) {
+		t.Fatalf("Expected RequiredFieldsError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "started") || strings.Contains(err.Error(), "endpoint") {
+		t.Errorf("Expected only started to be missing, got %v", err)
+	}
+
+	setEnv(t, "STARTED", "2024-01-02T03:04:05Z")
+	defer unsetEnv(t, "STARTED")
+	cfg = Config{}
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Started.Year() != 2024 || cfg.Endpoint.Host != "example.com" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_InvalidTarget(t *testing.T) {
+	var cfg LoaderTestConfig
+	if err := Load(cfg); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for non-pointer target, got %v", err)
+	}
+}
+
+type LoaderBaseConfig struct {
+	Name string `mapstructure:"name" default:"app"`
+	Env  string `mapstructure:"env"`
+}
+
+func TestLoad_EmbeddedStruct(t *testing.T) {
+	type Config struct {
+		LoaderBaseConfig
+		Port int `mapstructure:"port"`
+	}
+
+	configPath := createTempYAMLConfig(t, "env: prod\nport: 9000\n")
+	defer os.Remove(configPath)
+
+	var cfg Config
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Name != "app" || cfg.Env != "prod" || cfg.Port != 9000 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+// Встроенная структура с именем остаётся вложенной секцией, без имени читается с верхнего уровня
+func TestLoad_TaggedAndUntaggedEmbeds(t *testing.T) {
+	type Inner struct {
+		Env string `mapstructure:"env" default:"dev"`
+	}
+	type Nested struct {
+		LoaderBaseConfig `mapstructure:"base"`
+	}
+	type Config struct {
+		Inner
+		Nested `mapstructure:"nested"`
+		Port   int `mapstructure:"port"`
+	}
+
+	configPath := createTempYAMLConfig(t, "env: prod\nport: 9000\nnested:\n  base:\n    env: fromfile\n")
+	defer os.Remove(configPath)
+
+	var cfg Config
+	if err := Load(&cfg, WithFile(configPath), WithStrict()); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Inner.Env != "prod" || cfg.Port != 9000 {
+		t.Errorf("Expected the untagged embed from the top level, got %+v", cfg)
+	}
+	if cfg.Nested.Name != "app" || cfg.Nested.LoaderBaseConfig.Env != "fromfile" {
+		t.Errorf("Expected the tagged embed nested under base with its default, got %+v", cfg.Nested)
+	}
+
+	setEnv(t, "NESTED_BASE_NAME", "fromenv")
+	defer unsetEnv(t, "NESTED_BASE_NAME")
+	cfg = Config{}
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Nested.Name != "fromenv" {
+		t.Errorf("Expected the env override of nested.base.name, got %+v", cfg.Nested)
+	}
+
+	// Ключ вложенной секции на верхнем уровне неизвестен
+	topPath := createTempYAMLConfig(t, "name: top\n")
+	defer os.Remove(topPath)
+	if err := Load(&Config{}, WithFile(topPath), WithStrict()); !errors.Is(err, UnknownKeysError) {
+		t.Errorf("Expected UnknownKeysError for name at the top level, got %v", err)
+	}
+}
+
+// Встроенная структура с одним yaml-тегом вложена и в шаблонах, и при загрузке
+func TestLoad_YAMLTaggedEmbed(t *testing.T) {
+	type Extra struct {
+		Name string `mapstructure:"name" default:"app"`
+	}
+	type Config struct {
+		Extra `yaml:"extra"`
+		Port  int `mapstructure:"port"`
+	}
+
+	if yaml := GenerateYAMLTemplate(Config{}, false); !strings.Contains(yaml, "extra:\n  name: \"app\"\n") {
+		t.Errorf("Expected the embed nested under extra in the YAML template, got:\n%s", yaml)
+	}
+	if env := GenerateEnvTemplate(Config{}); !strings.Contains(env, "EXTRA_NAME=app") {
+		t.Errorf("Expected EXTRA_NAME in the env template, got:\n%s", env)
+	}
+	if values := GenerateYAMLFromValues(Config{Extra: Extra{Name: "x"}}, false); !strings.Contains(values, "extra:\n  name: \"x\"\n") {
+		t.Errorf("Expected the embed nested under extra in the values, got:\n%s", values)
+	}
+
+	var cfg Config
+	if err := Load(&cfg, WithOptionalFile()); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Name != "app" {
+		t.Errorf("Expected the default of extra.name, got %+v", cfg)
+	}
+
+	configPath := createTempYAMLConfig(t, "extra:\n  name: fromfile\nport: 9000\n")
+	defer os.Remove(configPath)
+	cfg = Config{}
+	if err := Load(&cfg, WithFile(configPath), WithStrict()); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Name != "fromfile" || cfg.Port != 9000 {
+		t.Errorf("Expected extra.name from the file, got %+v", cfg)
+	}
+}
+
+// Встроенный указатель на структуру остаётся вложенным и в шаблоне, и при загрузке
+func TestLoad_EmbeddedPointer(t *testing.T) {
+	type Base struct {
+		Region string `mapstructure:"region" default:"eu"`
+	}
+	type Config struct {
+		*Base
+		Name string `mapstructure:"name"`
+	}
+
+	template := GenerateYAMLTemplate(Config{}, false)
+	if !strings.Contains(template, "base:\n  region: \"eu\"\n") {
+		t.Fatalf("Expected the embedded pointer nested under base, got:\n%s", template)
+	}
+
+	configPath := createTempYAMLConfig(t, template)
+	defer os.Remove(configPath)
+	var cfg Config
+	if err := Load(&cfg, WithFile(configPath), WithStrict()); err != nil {
+		t.Fatalf("Failed to load the template: %v", err)
+	}
+	if cfg.Base == nil || cfg.Region != "eu" {
+		t.Errorf("Expected base.region from the template, got %+v", cfg.Base)
+	}
+}
+
+type LoaderMixedConfig struct {
+	Base    LoaderBaseConfig `mapstructure:",squash"`
+	Port    int              `mapstructure:"port" default:"8080" required:"true"`
+	secret  string
+	Ignored string `mapstructure:"-" default:"x"`
+}
+
+// Squashed структура читается с верхнего уровня, скрытые поля игнорируются
+func TestLoad_MixedFields(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "env: prod\nport: 9000\nignored: y\n")
+	defer os.Remove(configPath)
+
+	var cfg LoaderMixedConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Base.Name != "app" || cfg.Base.Env != "prod" || cfg.Port != 9000 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if cfg.secret != "" || cfg.Ignored != "" {
+		t.Errorf("Unexported and ignored fields must stay empty: %+v", cfg)
+	}
+}
+
+type OneOfTestConfig struct {
+	LogLevel string `mapstructure:"log_level" default:"info" oneof:"debug info warn error"`
+	Mode     int    `mapstructure:"mode" default:"1" oneof:"1 2"`
+}
+
+func TestLoad_OneOf(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "log_level: warn\nmode: 2\n")
+	defer os.Remove(configPath)
+
+	var cfg OneOfTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.LogLevel != "warn" || cfg.Mode != 2 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_OneOfViolation(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "log_level: trace\nmode: 3\n")
+	defer os.Remove(configPath)
+
+	var cfg OneOfTestConfig
+	err := Load(&cfg, WithFile(configPath))
+	if !errors.Is(err, InvalidValueError) {
+		t.Fatalf("Expected InvalidValueError, got %v", err)
+	}
+	for _, want := range []string{
+		`log_level: value "trace" is not one of [debug, info, warn, error]`,
+		`mode: value "3" is not one of [1, 2]`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestLoad_ValidationViolations(t *testing.T) {
+	type Config struct {
+		Name string `mapstructure:"name" validate:"min=3,max=5"`
+		Port int    `mapstructure:"port" validate:"max=65535"`
+	}
+
+	configPath := createTempYAMLConfig(t, "name: ab\nport: 70000\n")
+	defer os.Remove(configPath)
+
+	var cfg Config
+	err := Load(&cfg, WithFile(configPath))
+	if !errors.Is(err, InvalidValueError) {
+		t.Fatalf("Expected InvalidValueError, got %v", err)
+	}
+
+	var violationsErr validation.ViolationsError
+	if !errors.As(err, &violationsErr) {
+		t.Fatalf("Expected error to wrap validation.ViolationsError, got %v", err)
+	}
+	if len(violationsErr.Violations()) != 2 {
+		t.Errorf("Expected 2 violations, got %v", violationsErr.Violations())
+	}
+}
+
+func TestLoad_RangeViolation(t *testing.T) {
+	type Config struct {
+		Port  int     `mapstructure:"port" default:"8080" min:"1" max:"65535"`
+		Ratio float64 `mapstructure:"ratio" min:"0" max:"1"`
+	}
+
+	configPath := createTempYAMLConfig(t, "port: 0\nratio: 1.5\n")
+	defer os.Remove(configPath)
+
+	var cfg Config
+	err := Load(&cfg, WithFile(configPath))
+	if !errors.Is(err, InvalidValueError) {
+		t.Fatalf("Expected InvalidValueError, got %v", err)
+	}
+	for _, want := range []string{"port: 0 is less than 1", "ratio: 1.5 is greater than 1"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestLoad_FixedEnvName(t *testing.T) {
+	type Config struct {
+		Database struct {
+			URL  string `mapstructure:"url" env:"DATABASE_URL,fixed"`
+			Host string `mapstructure:"host" env:"db_host"`
+			Name string `mapstructure:"name" env:"-" default:"app"`
+		} `mapstructure:"database"`
+	}
+
+	configPath := createTempYAMLConfig(t, "database:\n  url: file\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "DATABASE_URL", "postgres://env")
+	setEnv(t, "MYAPP_DATABASE_NAME", "envname")
+	setEnv(t, "MYAPP_DATABASE_DB_HOST", "envhost")
+	defer unsetEnv(t, "MYAPP_DATABASE_DB_HOST")
+	defer unsetEnv(t, "DATABASE_URL")
+	defer unsetEnv(t, "MYAPP_DATABASE_NAME")
+
+	var cfg Config
+	if err := Load(&cfg, WithFile(configPath), WithEnvPrefix("myapp")); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Фиксированное имя не получает префикс
+	if cfg.Database.URL != "postgres://env" {
+		t.Errorf("Expected Database.URL to be 'postgres://env', got '%s'", cfg.Database.URL)
+	}
+	// Без опции fixed тег env — часть имени под префиксом родителя
+	if cfg.Database.Host != "envhost" {
+		t.Errorf("Expected Database.Host to be 'envhost', got '%s'", cfg.Database.Host)
+	}
+	// env:"-" исключает поле из переопределения
+	if cfg.Database.Name != "app" {
+		t.Errorf("Expected Database.Name to be 'app', got '%s'", cfg.Database.Name)
+	}
+}
+
+func TestLoad_JSONSliceDefault(t *testing.T) {
+	type Config struct {
+		Queries []string `mapstructure:"queries" default:"[\"a=1,b=2\", \"c=3\"]"`
+		Tags    []string `mapstructure:"tags" default:"x,y"`
+	}
+
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	var cfg Config
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !slices.Equal(cfg.Queries, []string{"a=1,b=2", "c=3"}) {
+		t.Errorf("Expected Queries to be [a=1,b=2 c=3], got %v", cfg.Queries)
+	}
+	if !slices.Equal(cfg.Tags, []string{"x", "y"}) {
+		t.Errorf("Expected Tags to be [x y], got %v", cfg.Tags)
+	}
+}
+
+func TestLoadWithResult_Deprecated(t *testing.T) {
+	type Config struct {
+		OldVersion string `mapstructure:"old_version" deprecated:"use meta.version instead"`
+		Meta       struct {
+			Version string `mapstructure:"version"`
+			Build   string `mapstructure:"build" deprecated:"no longer used"`
+		} `mapstructure:"meta"`
+	}
+
+	configPath := createTempYAMLConfig(t, "old_version: \"1.0\"\nmeta:\n  version: \"2.0\"\n")
+	defer os.Remove(configPath)
+
+	var cfg Config
+	result, err := LoadWithResult(&cfg, WithFile(configPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Устаревшее поле по-прежнему заполняется
+	if cfg.OldVersion != "1.0" {
+		t.Errorf("Expected OldVersion to be '1.0', got '%s'", cfg.OldVersion)
+	}
+	// Предупреждение только для ключей, заданных в файле
+	expected := []Warning{{Path: "old_version", Message: "deprecated: use meta.version instead"}}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("Expected warnings %v, got %v", expected, result.Warnings)
+	}
+}
+
+func TestLoadWithResult_Alias(t *testing.T) {
+	type TLS struct {
+		Cert string `mapstructure:"cert" alias:"certificate"`
+	}
+	type Config struct {
+		Host string `mapstructure:"host" alias:"hostname,server_name" default:"localhost"`
+		Meta struct {
+			Version string `mapstructure:"version" alias:"ver"`
+		} `mapstructure:"meta"`
+		TLS  *TLS   `mapstructure:"tls"`
+		Port int    `mapstructure:"port" alias:"listen_port" default:"80"`
+		Name string `mapstructure:"name" alias:"title"`
+	}
+
+	configPath := createTempYAMLConfig(t, `hostname: old.example.com
+meta:
+  ver: "2.0"
+tls:
+  certificate: cert.pem
+port: 8080
+listen_port: 9090
+`)
+	defer os.Remove(configPath)
+
+	var cfg Config
+	result, err := LoadWithResult(&cfg, WithFile(configPath), WithStrict())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Старые имена ключей заполняют те же поля, а текущее имя имеет приоритет
+	if cfg.Host != "old.example.com" || cfg.Meta.Version != "2.0" || cfg.Port != 8080 {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if cfg.TLS == nil || cfg.TLS.Cert != "cert.pem" {
+		t.Errorf("Expected tls.cert from the old key, got %+v", cfg.TLS)
+	}
+	if source, _ := result.Source("host"); source != SourceFile {
+		t.Errorf("Expected host to come from the file, got %v", source)
+	}
+
+	expected := []Warning{
+		{Path: "host", Message: `deprecated: set under the old key "hostname", rename it to "host"`},
+		{Path: "meta.version", Message: `deprecated: set under the old key "meta.ver", rename it to "meta.version"`},
+		{Path: "tls.cert", Message: `deprecated: set under the old key "tls.certificate", rename it to "tls.cert"`},
+		{Path: "port", Message: `deprecated: old key "listen_port" is ignored, "port" is set`},
+	}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("Expected warnings %v, got %v", expected, result.Warnings)
+	}
+
+	// Шаблон содержит только текущие имена
+	if template := GenerateYAMLTemplate(Config{}, true); strings.Contains(template, "hostname") {
+		t.Errorf("Expected no alias in the template:\n%s", template)
+	}
+}
+
+// Переменные окружения важнее значения из файла, заданного под старым именем
+func TestLoad_AliasPriority(t *testing.T) {
+	type Config struct {
+		Host string `mapstructure:"host" alias:"hostname"`
+	}
+
+	configPath := createTempYAMLConfig(t, "hostname: file.example.com\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "HOST", "env.example.com")
+	defer unsetEnv(t, "HOST")
+
+	var cfg Config
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "env.example.com" {
+		t.Errorf("Expected host from the environment, got %q", cfg.Host)
+	}
+}
+
+func TestLoad_ExpandedDefault(t *testing.T) {
+	type Config struct {
+		DataDir string `mapstructure:"data_dir" default:"${CONFIGO_TEST_HOME}/app"`
+		LogDir  string `mapstructure:"log_dir" default:"${CONFIGO_TEST_HOME}/log"`
+	}
+
+	configPath := createTempYAMLConfig(t, "log_dir: /var/log/app\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "CONFIGO_TEST_HOME", "/home/user")
+	defer unsetEnv(t, "CONFIGO_TEST_HOME")
+
+	var cfg Config
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.DataDir != "/home/user/app" {
+		t.Errorf("Expected DataDir to be '/home/user/app', got '%s'", cfg.DataDir)
+	}
+	// Явное значение из файла важнее раскрытого default
+	if cfg.LogDir != "/var/log/app" {
+		t.Errorf("Expected LogDir to be '/var/log/app', got '%s'", cfg.LogDir)
+	}
+}
+
+type NumericDefaultsConfig struct {
+	Port    uint16  `mapstructure:"port" default:"8080"`
+	Retries int8    `mapstructure:"retries" default:"-3"`
+	Limit   uint64  `mapstructure:"limit" default:"18446744073709551615"`
+	Ratio   float32 `mapstructure:"ratio" default:"0.25"`
+	Scale   float64 `mapstructure:"scale" default:"1.5"`
+}
+
+// Значения по умолчанию разбираются по точному типу поля
+func TestLoad_NumericDefaults(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	var cfg NumericDefaultsConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	expected := NumericDefaultsConfig{Port: 8080, Retries: -3, Limit: 18446744073709551615, Ratio: 0.25, Scale: 1.5}
+	if cfg != expected {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_NumericDefaultOverflow(t *testing.T) {
+	type Config struct {
+		Port uint16 `mapstructure:"port" default:"70000"`
+	}
+
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	var cfg Config
+	err := Load(&cfg, WithFile(configPath))
+	if !errors.Is(err, ConfigParsingError) {
+		t.Fatalf("Expected ConfigParsingError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "port") {
+		t.Errorf("Error should name the field: %v", err)
+	}
+}
+
+// Приоритет: файл > файл значений по умолчанию > тег default
+func TestLoad_DefaultsFile(t *testing.T) {
+	defaultsPath := createTempYAMLConfig(t, `
+port: 9090
+meta:
+  version: "1.5"
+  build: "base"
+`)
+	defer os.Remove(defaultsPath)
+
+	configPath := createTempYAMLConfig(t, "meta:\n  build: \"release\"\n")
+	defer os.Remove(configPath)
+
+	var cfg LoaderTestConfig
+	if err := Load(&cfg, WithFile(configPath), WithDefaultsFile(defaultsPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	expected := LoaderTestConfig{
+		Host: "localhost",
+		Port: 9090,
+		Meta: LoaderMetaConfig{Version: "1.5", Build: "release"},
+	}
+	if cfg != expected {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_MissingDefaultsFile(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	var cfg LoaderTestConfig
+	if err := Load(&cfg, WithFile(configPath), WithDefaultsFile("missing-defaults.yml")); err != nil {
+		t.Fatalf("Missing defaults file should be ignored: %v", err)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("Expected Port to be 8080, got %d", cfg.Port)
+	}
+
+	err := Load(&cfg, WithFile(configPath), WithDefaultsFile("missing-defaults.yml"), WithDefaultsFileRequired())
+	if err == nil || !strings.Contains(err.Error(), "defaults file") {
+		t.Errorf("Expected defaults file error, got %v", err)
+	}
+}
+
+type TimeTestConfig struct {
+	ValidFrom time.Time  `mapstructure:"valid_from" default:"2024-01-01T00:00:00Z"`
+	Day       time.Time  `mapstructure:"day" default:"2024-03-05" timeformat:"2006-01-02"`
+	Until     *time.Time `mapstructure:"until"`
+}
+
+func TestLoad_TimeDefaults(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	var cfg TimeTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !cfg.ValidFrom.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected ValidFrom: %v", cfg.ValidFrom)
+	}
+	if !cfg.Day.Equal(time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected Day: %v", cfg.Day)
+	}
+	// Без значения по умолчанию указатель остаётся nil
+	if cfg.Until != nil {
+		t.Errorf("Expected Until to be nil, got %v", cfg.Until)
+	}
+}
+
+func TestLoad_TimeValues(t *testing.T) {
+	yamlContent := `
+valid_from: "2025-06-01T12:30:00+02:00"
+until: "2026-01-01T00:00:00Z"
+`
+	configPath := createTempYAMLConfig(t, yamlContent)
+	defer os.Remove(configPath)
+
+	setEnv(t, "DAY", "2025-07-04")
+	defer unsetEnv(t, "DAY")
+
+	var cfg TimeTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !cfg.ValidFrom.Equal(time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected ValidFrom: %v", cfg.ValidFrom)
+	}
+	if !cfg.Day.Equal(time.Date(2025, 7, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected Day: %v", cfg.Day)
+	}
+	if cfg.Until == nil || !cfg.Until.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected Until: %v", cfg.Until)
+	}
+}
+
+func TestLoad_TimeFormatMismatch(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "day: \"04.07.2025\"\n")
+	defer os.Remove(configPath)
+
+	var cfg TimeTestConfig
+	err := Load(&cfg, WithFile(configPath))
+	if !errors.Is(err, ConfigParsingError) {
+		t.Fatalf("Expected ConfigParsingError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "day") {
+		t.Errorf("Error should name the field: %v", err)
+	}
+}
+
+type BytesTestConfig struct {
+	Key []byte `mapstructure:"key" default:"aGVsbG8=" encoding:"base64"`
+	Raw []byte `mapstructure:"raw" default:"hello"`
+}
+
+func TestLoad_BytesDefaults(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "{}\n")
+	defer os.Remove(configPath)
+
+	var cfg BytesTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if string(cfg.Key) != "hello" {
+		t.Errorf("Expected Key 'hello', got %q", cfg.Key)
+	}
+	if string(cfg.Raw) != "hello" {
+		t.Errorf("Expected Raw 'hello', got %q", cfg.Raw)
+	}
+}
+
+func TestLoad_BytesValues(t *testing.T) {
+	yamlContent := `
+key: "d29ybGQ="
+raw: "world,x"
+`
+	configPath := createTempYAMLConfig(t, yamlContent)
+	defer os.Remove(configPath)
+
+	var cfg BytesTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if string(cfg.Key) != "world" {
+		t.Errorf("Expected Key 'world', got %q", cfg.Key)
+	}
+	// Строка без кодировки не разбивается по запятым
+	if string(cfg.Raw) != "world,x" {
+		t.Errorf("Expected Raw 'world,x', got %q", cfg.Raw)
+	}
+}
+
+func TestLoad_BytesInvalidBase64(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "key: \"not base64!\"\n")
+	defer os.Remove(configPath)
+
+	var cfg BytesTestConfig
+	err := Load(&cfg, WithFile(configPath))
+	if !errors.Is(err, ConfigParsingError) {
+		t.Fatalf("Expected ConfigParsingError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "key") {
+		t.Errorf("Error should name the field: %v", err)
+	}
+}
+
+type TextTypesTestConfig struct {
+	BaseURL url.URL    `mapstructure:"base_url" default:"https://example.com/api"`
+	Proxy   *url.URL   `mapstructure:"proxy"`
+	BindIP  net.IP     `mapstructure:"bind_ip" default:"0.0.0.0"`
+	Allowed *net.IPNet `mapstructure:"allowed_cidr"`
+}
+
+func TestLoad_TextTypes(t *testing.T) {
+	yamlContent := `
+proxy: "http://proxy.local:3128"
+allowed_cidr: "10.0.0.0/8"
+`
+	configPath := createTempYAMLConfig(t, yamlContent)
+	defer os.Remove(configPath)
+
+	setEnv(t, "BIND_IP", "::1")
+	defer unsetEnv(t, "BIND_IP")
+
+	var cfg TextTypesTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.BaseURL.Host != "example.com" || cfg.BaseURL.Path != "/api" {
+		t.Errorf("Unexpected BaseURL: %v", cfg.BaseURL.String())
+	}
+	if cfg.Proxy == nil || cfg.Proxy.Host != "proxy.local:3128" {
+		t.Errorf("Unexpected Proxy: %v", cfg.Proxy)
+	}
+	if !cfg.BindIP.Equal(net.IPv6loopback) {
+		t.Errorf("Unexpected BindIP: %v", cfg.BindIP)
+	}
+	if cfg.Allowed == nil || cfg.Allowed.String() != "10.0.0.0/8" {
+		t.Errorf("Unexpected Allowed: %v", cfg.Allowed)
+	}
+}
+
+func TestLoad_InvalidIP(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "bind_ip: \"not-an-ip\"\n")
+	defer os.Remove(configPath)
+
+	var cfg TextTypesTestConfig
+	err := Load(&cfg, WithFile(configPath))
+	if err == nil || !strings.Contains(err.Error(), "bind_ip") {
+		t.Errorf("Expected an error naming bind_ip, got %v", err)
+	}
+}
+
+type StrictServerConfig struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
+
+type StrictTestConfig struct {
+	LoaderBaseConfig
+	Meta    LoaderMetaConfig              `mapstructure:"meta"`
+	Servers []StrictServerConfig          `mapstructure:"servers"`
+	Pools   map[string]StrictServerConfig `mapstructure:"pools"`
+	Labels  map[string]string             `mapstructure:"labels"`
+}
+
+func TestLoad_Strict(t *testing.T) {
+	yamlContent := `
+name: app
+meta:
+  verson: "2.0"
+servers:
+  - host: a
+  - hots: b
+pools:
+  main:
+    prot: 80
+labels:
+  anything: goes
+timeout: 5
+`
+	configPath := createTempYAMLConfig(t, yamlContent)
+	defer os.Remove(configPath)
+
+	// Без WithStrict неизвестные ключи игнорируются
+	var cfg StrictTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	err := Load(&cfg, WithFile(configPath), WithStrict())
+	if !errors.Is(err, UnknownKeysError) {
+		t.Fatalf("Expected UnknownKeysError, got %v", err)
+	}
+	expected := "meta.verson, pools.main.prot, servers.1.hots, timeout"
+	if !strings.HasSuffix(err.Error(), expected) {
+		t.Errorf("Expected unknown keys %q, got %v", expected, err)
+	}
+}
+
+func TestLoad_StrictValid(t *testing.T) {
+	yamlContent := `
+name: app
+meta:
+  version: "2.0"
+servers:
+  - host: a
+    port: 80
+`
+	configPath := createTempYAMLConfig(t, yamlContent)
+	defer os.Remove(configPath)
+
+	var cfg StrictTestConfig
+	if err := Load(&cfg, WithFile(configPath), WithStrict()); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Name != "app" || cfg.Meta.Version != "2.0" || len(cfg.Servers) != 1 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+type LoaderTLSConfig struct {
+	Cert string `mapstructure:"cert" default:"cert.pem"`
+	Port int    `mapstructure:"port" default:"443"`
+}
+
+type LoaderEmptySection struct {
+	Name string `mapstructure:"name"`
+}
+
+type LoaderSectionsConfig struct {
+	TLS    *LoaderTLSConfig    `mapstructure:"tls"`
+	Backup *LoaderTLSConfig    `mapstructure:"backup" default:"enabled"`
+	Extra  *LoaderEmptySection `mapstructure:"extra" default:"enabled"`
+}
+
+// Указатель на структуру без default остаётся nil, пока секция не задана
+func TestLoad_PointerSectionDefaults(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "name: app\n")
+	defer os.Remove(configPath)
+
+	var cfg LoaderSectionsConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.TLS != nil {
+		t.Errorf("Expected TLS to stay nil, got %+v", cfg.TLS)
+	}
+	if cfg.Backup == nil || *cfg.Backup != (LoaderTLSConfig{Cert: "cert.pem", Port: 443}) {
+		t.Errorf("Expected Backup to be allocated with its defaults, got %+v", cfg.Backup)
+	}
+	if cfg.Extra == nil {
+		t.Error("Expected Extra to be allocated")
+	}
+
+	configPath = createTempYAMLConfig(t, "tls:\n  port: 8443\n")
+	defer os.Remove(configPath)
+
+	cfg = LoaderSectionsConfig{}
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.TLS == nil || *cfg.TLS != (LoaderTLSConfig{Cert: "cert.pem", Port: 8443}) {
+		t.Errorf("Expected TLS to take the child defaults, got %+v", cfg.TLS)
+	}
+}
+
+func TestLoad_PointerSectionInvalidDefault(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "")
+	defer os.Remove(configPath)
+
+	var cfg struct {
+		TLS *LoaderTLSConfig `mapstructure:"tls" default:"true"`
+	}
+	err := Load(&cfg, WithFile(configPath))
+	if !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError, got: %v", err)
+	}
+}
+
+type IntBaseLoaderConfig struct {
+	Mode  uint32 `mapstructure:"mode" default:"0644" format:"octal"`
+	Umask int    `mapstructure:"umask" format:"octal"`
+	Flags uint8  `mapstructure:"flags" default:"0x1F" format:"hex"`
+	Mask  int64  `mapstructure:"mask" format:"hex"`
+}
+
+func TestLoad_IntBases(t *testing.T) {
+	var cfg IntBaseLoaderConfig
+	if err := LoadFromBytes(&cfg, []byte("{}"), JSON); err != nil {
+		t.Fatalf("Failed to load defaults: %v", err)
+	}
+	if cfg.Mode != 0o644 || cfg.Flags != 0x1F {
+		t.Errorf("expected defaults 0644 and 0x1F, got %+v", cfg)
+	}
+
+	// Строки из файла и окружения разбираются в основании поля, префикс не обязателен
+	setEnv(t, "MODE", "755")
+	defer unsetEnv(t, "MODE")
+	setEnv(t, "FLAGS", "ff")
+	defer unsetEnv(t, "FLAGS")
+	if err := LoadFromBytes(&cfg, []byte(`{"umask": "0o022", "mask": "0xFF00"}`), JSON); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	expected := IntBaseLoaderConfig{Mode: 0o755, Umask: 0o022, Flags: 0xFF, Mask: 0xFF00}
+	if cfg != expected {
+		t.Errorf("expected %+v, got %+v", expected, cfg)
+	}
+
+	for _, data := range []string{`{"umask": "0x1F"}`, `{"umask": "9"}`, `{"mask": "0xZZ"}`} {
+		if err := LoadFromBytes(&cfg, []byte(data), JSON); !errors.Is(err, ConfigParsingError) {
+			t.Errorf("%s: expected ConfigParsingError, got %v", data, err)
+		}
+	}
+
+	type BadDefault struct {
+		Mode int `mapstructure:"mode" default:"0x1F" format:"octal"`
+	}
+	if err := LoadFromBytes(&BadDefault{}, []byte("{}"), JSON); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("expected ConfigParsingError for an octal default in hex, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package configo
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/spf13/viper"
+)
+
+// logFiles logs the config file read into v, if any, and the defaults file.
+func (l *loader) logFiles(v, defaults *viper.Viper) {
+	if l.logger == nil {
+		return
+	}
+	if defaults != nil {
+		l.logger.Debug("configo: defaults file loaded", "file", defaults.ConfigFileUsed())
+	}
+	if file := v.ConfigFileUsed(); file != "" {
+		l.logger.Debug("configo: config file loaded", "file", file)
+	}
+	if l.profile != "" {
+		l.logger.Debug("configo: profile applied", "profile", l.profile)
+	}
+}
+
+// logSources logs the source of every field set by one, in key order.
+func (l *loader) logSources(sources map[string]origin) {
+	if l.logger == nil || !l.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+
+	keys := make([]string, 0, len(sources))
+	for key := range sources {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		o := sources[key]
+		switch o.Source {
+		case SourceDefault:
+			l.logger.Debug("configo: default used", "key", key)
+		case SourceDefaultsFile:
+			l.logger.Debug("configo: default read from defaults file", "key", key, "file", o.Name)
+		case SourceFile:
+			l.logger.Debug("configo: value read from file", "key", key, "file", o.Name)
+		case SourceEnv:
+			l.logger.Debug("configo: env override applied", "key", key, "env", o.Name)
+		case SourceFlag:
+			l.logger.Debug("configo: flag override applied", "key", key, "flag", o.Name)
+		}
+	}
+}
+
+// logWarnings logs the warnings found while loading, e.g. deprecated keys.
+func (l *loader) logWarnings(warnings []Warning) {
+	if l.logger == nil {
+		return
+	}
+	for _, w := range warnings {
+		l.logger.Warn("configo: "+w.Message, "key", w.Path)
+	}
+}
@@ -0,0 +1,78 @@
+package configo
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoad_WithLogger(t *testing.T) {
+	type Config struct {
+		Host     string `mapstructure:"host" default:"localhost"`
+		Port     int    `mapstructure:"port" default:"8080"`
+		Password string `mapstructure:"password" secret:"true"`
+		Legacy   string `mapstructure:"legacy" deprecated:"no longer used"`
+		Unset    string `mapstructure:"unset"`
+	}
+
+	configPath := createTempYAMLConfig(t, "port: 9090\npassword: hunter2\nlegacy: x\n")
+	defer os.Remove(configPath)
+	setEnv(t, "PORT", "7070")
+	defer unsetEnv(t, "PORT")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	var cfg Config
+	if err := Load(&cfg, WithFile(configPath), WithLogger(logger)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	expected := []string{
+		`level=DEBUG msg="configo: config file loaded" file=` + configPath,
+		`level=DEBUG msg="configo: default used" key=host`,
+		`level=DEBUG msg="configo: value read from file" key=legacy file=` + configPath,
+		`level=DEBUG msg="configo: value read from file" key=password file=` + configPath,
+		`level=DEBUG msg="configo: env override applied" key=port env=PORT`,
+		`level=WARN msg="configo: deprecated: no longer used" key=legacy`,
+	}
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected log:\n%s\nwant:\n%s", buf.String(), strings.Join(expected, "\n"))
+	}
+	// Значения никогда не попадают в лог
+	if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "7070") {
+		t.Errorf("values must not be logged:\n%s", buf.String())
+	}
+}
+
+func TestLoad_WithLoggerLevel(t *testing.T) {
+	type Config struct {
+		Host   string `mapstructure:"host" default:"localhost"`
+		Legacy string `mapstructure:"legacy" deprecated:"no longer used"`
+	}
+
+	configPath := createTempYAMLConfig(t, "legacy: x\n")
+	defer os.Remove(configPath)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	var cfg Config
+	if err := Load(&cfg, WithFile(configPath), WithLogger(logger)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if out := buf.String(); strings.Contains(out, "DEBUG") || !strings.Contains(out, "deprecated: no longer used") {
+		t.Errorf("expected only the warning at warn level, got:\n%s", out)
+	}
+}
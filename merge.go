@@ -0,0 +1,157 @@
+package configo
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// textMarshalerType is used to detect structs that behave as single values,
+// such as time.Time, which are merged as a whole.
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// MergeOption configures a single call to Merge.
+type MergeOption func(*merger)
+
+type merger struct {
+	appendSlices bool
+}
+
+// WithAppendSlices makes Merge append the elements of non-empty src slices to
+// the dst slices instead of replacing them.
+func WithAppendSlices() MergeOption {
+	return func(m *merger) {
+		m.appendSlices = true
+	}
+}
+
+// Merge deep-merges the non-zero fields of src into dst. dst must be a non-nil
+// pointer to a struct and src a struct of the same type, or a pointer to one.
+//
+//   - Nested structs are merged field by field.
+//   - Non-empty slices replace the dst slice (see WithAppendSlices).
+//   - Maps are merged key by key: keys of src overwrite the same keys of dst,
+//     other keys of dst are kept.
+//   - Pointers to structs are merged like nested structs, a nil dst pointer
+//     is allocated first. Other pointers, interfaces and funcs overwrite dst
+//     only when they are non-nil.
+//   - Other values, and structs configured as a single string like time.Time,
+//     url.URL, net.IPNet or a type registered with RegisterType, overwrite
+//     dst only when they are not the zero value.
+//
+// A struct value can't tell a field explicitly set to its zero value apart
+// from an unset one, so src can never reset a dst field to 0, "" or false.
+// Declare such fields as pointers: a non-nil pointer to a zero value is
+// merged like any other non-nil pointer. For the same reason an empty slice or
+// map in src is treated as unset. Unexported fields are not merged.
+func Merge(dst, src interface{}, opts ...MergeOption) error {
+	m := &merger{}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected a non-nil pointer to a struct as dst, got %T", ConfigParsingError, dst)
+	}
+	dv = dv.Elem()
+
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr {
+		if sv.IsNil() {
+			return nil
+		}
+		sv = sv.Elem()
+	}
+	if !sv.IsValid() || sv.Type() != dv.Type() {
+		return fmt.Errorf("%w: cannot merge %T into %T", ConfigParsingError, src, dst)
+	}
+
+	m.mergeStruct(dv, sv)
+	return nil
+}
+
+// mergeStruct merges the exported fields of src into dst.
+func (m *merger) mergeStruct(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		if !dst.Field(i).CanSet() {
+			continue
+		}
+		m.mergeValue(dst.Field(i), src.Field(i))
+	}
+}
+
+// mergeValue merges a single value of src into dst.
+func (m *merger) mergeValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		if isMergedWhole(src.Type()) {
+			if !src.IsZero() {
+				dst.Set(src)
+			}
+			return
+		}
+		m.mergeStruct(dst, src)
+
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		elem := src.Type().Elem()
+		if elem.Kind() == reflect.Struct && !isMergedWhole(elem) {
+			// A section behind a pointer is merged field by field, like a
+			// nested struct, so the fields only dst sets are kept.
+			if dst.IsNil() {
+				dst.Set(reflect.New(elem))
+			}
+			m.mergeValue(dst.Elem(), src.Elem())
+			return
+		}
+		// Other pointers are set even to a zero value, on a copy that
+		// doesn't share the pointee with src.
+		value := reflect.New(elem)
+		value.Elem().Set(src.Elem())
+		dst.Set(value)
+
+	case reflect.Interface, reflect.Func, reflect.Chan:
+		if !src.IsNil() {
+			dst.Set(src)
+		}
+
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return
+		}
+		if m.appendSlices {
+			dst.Set(reflect.AppendSlice(dst, src))
+			return
+		}
+		// The slice is copied, so that later changes to dst don't leak into src.
+		dst.Set(reflect.AppendSlice(reflect.MakeSlice(src.Type(), 0, src.Len()), src))
+
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), iter.Value())
+		}
+
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// isMergedWhole reports whether a struct of type t is configured as a single
+// string, like time.Time or url.URL, and thus replaces dst as a whole.
+func isMergedWhole(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) || walker.TextType(t)
+}
@@ -0,0 +1,155 @@
+package configo
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type MergeServerConfig struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
+
+type MergeTestConfig struct {
+	Name    string             `mapstructure:"name"`
+	Debug   *bool              `mapstructure:"debug"`
+	Tags    []string           `mapstructure:"tags"`
+	Labels  map[string]string  `mapstructure:"labels"`
+	Server  MergeServerConfig  `mapstructure:"server"`
+	Backup  *MergeServerConfig `mapstructure:"backup"`
+	Started time.Time          `mapstructure:"started"`
+}
+
+func TestMerge(t *testing.T) {
+	debug := true
+	noDebug := false
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	dst := MergeTestConfig{
+		Name:   "base",
+		Debug:  &debug,
+		Tags:   []string{"a", "b"},
+		Labels: map[string]string{"team": "core", "env": "dev"},
+		Server: MergeServerConfig{Host: "localhost", Port: 8080},
+		Backup: &MergeServerConfig{Host: "backup"},
+	}
+	src := MergeTestConfig{
+		Debug:   &noDebug,
+		Tags:    []string{"c"},
+		Labels:  map[string]string{"env": "prod"},
+		Server:  MergeServerConfig{Port: 9090},
+		Started: started,
+	}
+
+	if err := Merge(&dst, &src); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	expected := MergeTestConfig{
+		Name:    "base",
+		Debug:   &noDebug,
+		Tags:    []string{"c"},
+		Labels:  map[string]string{"team": "core", "env": "prod"},
+		Server:  MergeServerConfig{Host: "localhost", Port: 9090},
+		Backup:  &MergeServerConfig{Host: "backup"},
+		Started: started,
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Unexpected merge result: %+v", dst)
+	}
+
+	// Заменённый срез не должен разделять память с src
+	dst.Tags[0] = "changed"
+	if src.Tags[0] != "c" {
+		t.Errorf("Merge should copy replaced slices, src.Tags = %v", src.Tags)
+	}
+}
+
+func TestMerge_AppendSlices(t *testing.T) {
+	dst := MergeTestConfig{Tags: []string{"a", "b"}}
+	src := MergeTestConfig{Tags: []string{"c"}}
+
+	if err := Merge(&dst, src, WithAppendSlices()); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !reflect.DeepEqual(dst.Tags, []string{"a", "b", "c"}) {
+		t.Errorf("Expected appended tags, got %v", dst.Tags)
+	}
+}
+
+func TestMerge_NilMapInDst(t *testing.T) {
+	var dst MergeTestConfig
+	src := MergeTestConfig{Labels: map[string]string{"env": "prod"}}
+
+	if err := Merge(&dst, src); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	dst.Labels["team"] = "core"
+	if len(src.Labels) != 1 {
+		t.Errorf("Merge should not share the src map, src.Labels = %v", src.Labels)
+	}
+}
+
+// URL и сети заменяются целиком, а не по полям
+func TestMerge_TextTypes(t *testing.T) {
+	type Config struct {
+		Endpoint url.URL   `mapstructure:"endpoint"`
+		Network  net.IPNet `mapstructure:"network"`
+	}
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+
+	dst := Config{Endpoint: url.URL{Scheme: "https", Host: "base", Path: "/api"}}
+	src := Config{Endpoint: url.URL{Scheme: "http", Host: "override"}, Network: *network}
+	if err := Merge(&dst, src); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !reflect.DeepEqual(dst, src) {
+		t.Errorf("Expected the values of src, got %+v", dst)
+	}
+}
+
+func TestMerge_Errors(t *testing.T) {
+	var dst MergeTestConfig
+	if err := Merge(dst, MergeTestConfig{}); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for a non-pointer dst, got %v", err)
+	}
+	if err := Merge(&dst, MergeServerConfig{}); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for a different src type, got %v", err)
+	}
+	if err := Merge(&dst, nil); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for a nil src, got %v", err)
+	}
+	if err := Merge(&dst, (*MergeTestConfig)(nil)); err != nil {
+		t.Errorf("A nil src should be a no-op, got %v", err)
+	}
+}
+
+// Секция за указателем сливается по полям и не разделяет память с src
+func TestMerge_PointerSection(t *testing.T) {
+	dst := MergeTestConfig{Backup: &MergeServerConfig{Host: "backup", Port: 8080}}
+	src := MergeTestConfig{Backup: &MergeServerConfig{Port: 9090}}
+
+	if err := Merge(&dst, src); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !reflect.DeepEqual(dst.Backup, &MergeServerConfig{Host: "backup", Port: 9090}) {
+		t.Errorf("Expected the dst host to be kept, got %+v", dst.Backup)
+	}
+
+	var empty MergeTestConfig
+	if err := Merge(&empty, src); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if empty.Backup == src.Backup || empty.Debug != nil {
+		t.Fatalf("Expected a new Backup and a nil Debug, got %+v", empty)
+	}
+	empty.Backup.Host = "changed"
+	if src.Backup.Host != "" {
+		t.Errorf("Merge should not share the src pointer, src.Backup = %+v", src.Backup)
+	}
+}
@@ -0,0 +1,82 @@
+package configo
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// baseProfile is the section of a config file with profiles that holds the
+// values shared by all profiles.
+const baseProfile = "default"
+
+// WithProfile selects a profile of a config file organized in sections: the
+// `default` section holds the base values and every other top-level section,
+// e.g. `production` or `staging`, overrides them for one environment. The
+// named section is deep-merged over `default`: nested maps are merged key by
+// key and any other value of the profile, including lists, replaces the base
+// value. Sections of other profiles are ignored.
+//
+// Loading fails with ConfigParsingError if the file has no section for the
+// profile, so that a typo doesn't silently fall back to the base values.
+func WithProfile(name string) LoaderOption {
+	return func(l *loader) {
+		l.profile = name
+	}
+}
+
+// readProfile reads the config with read and sets the `default` section with
+// the profile section merged over it as the config of v.
+func readProfile(v *viper.Viper, profile string, read func(v *viper.Viper) error) error {
+	raw := viper.New()
+	if err := read(raw); err != nil {
+		return err
+	}
+
+	base, err := profileSection(raw, baseProfile)
+	if err != nil {
+		return err
+	}
+	if !raw.IsSet(profile) {
+		return fmt.Errorf("%w: profile %q not found in the config", ConfigParsingError, profile)
+	}
+	override, err := profileSection(raw, profile)
+	if err != nil {
+		return err
+	}
+
+	if file := raw.ConfigFileUsed(); file != "" {
+		v.SetConfigFile(file)
+	}
+	return v.MergeConfigMap(mergeSections(base, override))
+}
+
+// profileSection returns the section of the given name, or an empty one if
+// the config doesn't have it.
+func profileSection(raw *viper.Viper, name string) (map[string]interface{}, error) {
+	value := raw.Get(name)
+	if value == nil {
+		return map[string]interface{}{}, nil
+	}
+	section, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: profile section %q is not a map", ConfigParsingError, name)
+	}
+	return section, nil
+}
+
+// mergeSections deep-merges override into base and returns base. Maps present
+// in both are merged recursively, other values of override replace those of
+// base.
+func mergeSections(base, override map[string]interface{}) map[string]interface{} {
+	for key, value := range override {
+		baseMap, baseIsMap := base[key].(map[string]interface{})
+		overrideMap, overrideIsMap := value.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			base[key] = mergeSections(baseMap, overrideMap)
+			continue
+		}
+		base[key] = value
+	}
+	return base
+}
@@ -0,0 +1,109 @@
+package configo
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type ProfileTestConfig struct {
+	Host  string   `mapstructure:"host" default:"localhost"`
+	Port  int      `mapstructure:"port" default:"8080"`
+	Tags  []string `mapstructure:"tags"`
+	Cache struct {
+		Size int    `mapstructure:"size"`
+		Mode string `mapstructure:"mode"`
+	} `mapstructure:"cache"`
+	Labels map[string]string `mapstructure:"labels"`
+}
+
+const profileTestYAML = `default:
+  host: example.com
+  tags: [a, b]
+  cache:
+    size: 10
+    mode: lru
+  labels:
+    team: core
+production:
+  host: prod.example.com
+  tags: [c]
+  cache:
+    size: 100
+  labels:
+    env: prod
+staging:
+  port: 9000
+`
+
+func TestLoad_Profile(t *testing.T) {
+	configPath := createTempYAMLConfig(t, profileTestYAML)
+	defer os.Remove(configPath)
+
+	var cfg ProfileTestConfig
+	result, err := LoadWithResult(&cfg, WithFile(configPath), WithProfile("production"), WithStrict())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Профиль перекрывает значения default, вложенные секции сливаются
+	if cfg.Host != "prod.example.com" {
+		t.Errorf("Expected Host from the profile, got '%s'", cfg.Host)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("Expected Port from the default tag, got %d", cfg.Port)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"c"}) {
+		t.Errorf("Expected the profile list to replace the base one, got %v", cfg.Tags)
+	}
+	if cfg.Cache.Size != 100 || cfg.Cache.Mode != "lru" {
+		t.Errorf("Expected cache {100 lru}, got %+v", cfg.Cache)
+	}
+	expectedLabels := map[string]string{"team": "core", "env": "prod"}
+	if !reflect.DeepEqual(cfg.Labels, expectedLabels) {
+		t.Errorf("Expected labels %v, got %v", expectedLabels, cfg.Labels)
+	}
+	if source, origin := result.Source("cache.mode"); source != SourceFile || origin != configPath {
+		t.Errorf("Expected cache.mode from the file, got %v (%s)", source, origin)
+	}
+}
+
+func TestLoad_ProfileEnvOverride(t *testing.T) {
+	configPath := createTempYAMLConfig(t, profileTestYAML)
+	defer os.Remove(configPath)
+
+	setEnv(t, "HOST", "env.example.com")
+	defer unsetEnv(t, "HOST")
+
+	var cfg ProfileTestConfig
+	if err := Load(&cfg, WithFile(configPath), WithProfile("staging")); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "env.example.com" || cfg.Port != 9000 || cfg.Cache.Size != 10 {
+		t.Errorf("Expected env over the staging profile over default, got %+v", cfg)
+	}
+}
+
+func TestLoad_ProfileNotFound(t *testing.T) {
+	configPath := createTempYAMLConfig(t, profileTestYAML)
+	defer os.Remove(configPath)
+
+	var cfg ProfileTestConfig
+	err := Load(&cfg, WithFile(configPath), WithProfile("prodution"))
+	if !errors.Is(err, ConfigParsingError) {
+		t.Fatalf("Expected ConfigParsingError, got %v", err)
+	}
+}
+
+func TestLoadFromBytes_Profile(t *testing.T) {
+	data := []byte(`{"default": {"host": "a"}, "production": {"port": 1}}`)
+
+	var cfg ProfileTestConfig
+	if err := LoadFromBytes(&cfg, data, JSON, WithProfile("production")); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "a" || cfg.Port != 1 {
+		t.Errorf("Expected host 'a' and port 1, got %+v", cfg)
+	}
+}
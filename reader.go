@@ -0,0 +1,74 @@
+package configo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+)
+
+// Format selects the parser of a config read by LoadFromReader or
+// LoadFromBytes.
+type Format int
+
+const (
+	// YAML parses the config as YAML.
+	YAML Format = iota
+
+	// JSON parses the config as JSON.
+	JSON
+
+	// TOML parses the config as TOML.
+	TOML
+)
+
+// configType returns the Viper config type of the format, or "" for an
+// unknown format.
+func (f Format) configType() string {
+	switch f {
+	case YAML:
+		return "yaml"
+	case JSON:
+		return "json"
+	case TOML:
+		return "toml"
+	default:
+		return ""
+	}
+}
+
+// LoadFromReader works like Load but reads the config from r, parsed as
+// format, instead of a file, e.g. a config received over the network.
+// Defaults, environment variables, the defaults file and the validation
+// apply exactly as with Load. WithFile is ignored.
+func LoadFromReader(cfg interface{}, r io.Reader, format Format, opts ...LoaderOption) error {
+	read, err := readFrom(r, format)
+	if err != nil {
+		return err
+	}
+	_, err = newLoader(opts).load(cfg, read)
+	return err
+}
+
+// readFrom returns the read step of a load that parses r as format.
+func readFrom(r io.Reader, format Format) (func(v *viper.Viper) error, error) {
+	configType := format.configType()
+	if configType == "" {
+		return nil, fmt.Errorf("%w: unknown config format %d", ConfigParsingError, format)
+	}
+
+	return func(v *viper.Viper) error {
+		v.SetConfigType(configType)
+		if err := v.ReadConfig(r); err != nil {
+			return fmt.Errorf("error reading config: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// LoadFromBytes works like LoadFromReader with the config held in data, e.g.
+// a file embedded with go:embed.
+func LoadFromBytes(cfg interface{}, data []byte, format Format, opts ...LoaderOption) error {
+	return LoadFromReader(cfg, bytes.NewReader(data), format, opts...)
+}
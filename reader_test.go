@@ -0,0 +1,125 @@
+package configo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Конфиг из io.Reader и []byte в разных форматах, env и default применяются как при Load
+func TestLoadFromReader_Formats(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+	}{
+		{"yaml", YAML, "host: readerhost\nmeta:\n  version: \"2.0\"\n"},
+		{"json", JSON, `{"host": "readerhost", "meta": {"version": "2.0"}}`},
+		{"toml", TOML, "host = \"readerhost\"\n[meta]\nversion = \"2.0\"\n"},
+	}
+
+	setEnv(t, "META_BUILD", "ci")
+	defer unsetEnv(t, "META_BUILD")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg LoaderTestConfig
+			if err := LoadFromReader(&cfg, strings.NewReader(tt.content), tt.format); err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+
+			expected := LoaderTestConfig{
+				Host: "readerhost",
+				Port: 8080,
+				Meta: LoaderMetaConfig{Version: "2.0", Build: "ci"},
+			}
+			if cfg != expected {
+				t.Errorf("Expected %+v, got %+v", expected, cfg)
+			}
+		})
+	}
+}
+
+func TestLoadFromBytes(t *testing.T) {
+	setEnv(t, "APP_HOST", "envhost")
+	defer unsetEnv(t, "APP_HOST")
+
+	var cfg LoaderTestConfig
+	err := LoadFromBytes(&cfg, []byte("host: bytehost\nport: 9090\n"), YAML, WithEnvPrefix("APP"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Host != "envhost" {
+		t.Errorf("Expected Host to be 'envhost', got '%s'", cfg.Host)
+	}
+	if cfg.Port != 9090 {
+		t.Errorf("Expected Port to be 9090, got %d", cfg.Port)
+	}
+	if cfg.Meta.Version != "1.0" {
+		t.Errorf("Expected Meta.Version to be '1.0', got '%s'", cfg.Meta.Version)
+	}
+}
+
+func TestLoadFromReader_Errors(t *testing.T) {
+	var cfg LoaderTestConfig
+	if err := LoadFromBytes(&cfg, []byte("host: x"), Format(42)); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for an unknown format, got %v", err)
+	}
+	if err := LoadFromBytes(&cfg, []byte("{not json"), JSON); err == nil {
+		t.Error("Expected an error for malformed JSON")
+	}
+	if err := LoadFromBytes(&cfg, []byte("host: x\nhots: y\n"), YAML, WithStrict()); !errors.Is(err, UnknownKeysError) {
+		t.Errorf("Expected UnknownKeysError, got %v", err)
+	}
+}
+
+// Ключи map разбираются в объявленный тип ключа
+func TestLoadFromBytes_NonStringMapKeys(t *testing.T) {
+	type Config struct {
+		Priorities map[int]string        `mapstructure:"priorities"`
+		Weights    map[uint8]map[int]int `mapstructure:"weights"`
+	}
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+	}{
+		{"yaml", YAML, "priorities:\n  1: high\n  2: low\nweights:\n  3:\n    4: 5\n"},
+		{"json", JSON, `{"priorities": {"1": "high", "2": "low"}, "weights": {"3": {"4": 5}}}`},
+		{"toml", TOML, "[priorities]\n1 = \"high\"\n2 = \"low\"\n[weights.3]\n4 = 5\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			if err := LoadFromBytes(&cfg, []byte(tt.content), tt.format); err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if len(cfg.Priorities) != 2 || cfg.Priorities[1] != "high" || cfg.Priorities[2] != "low" {
+				t.Errorf("Expected priorities map[1:high 2:low], got %v", cfg.Priorities)
+			}
+			if cfg.Weights[3][4] != 5 {
+				t.Errorf("Expected weights map[3:map[4:5]], got %v", cfg.Weights)
+			}
+		})
+	}
+
+	var bad Config
+	if err := LoadFromBytes(&bad, []byte("priorities:\n  high: 1\n"), YAML); err == nil {
+		t.Errorf("Expected an error for a key that is not an int")
+	}
+}
+
+// Map с ключами-структурами не поддерживается
+func TestLoadFromBytes_UnsupportedMapKey(t *testing.T) {
+	type Key struct{ ID int }
+	var cfg struct {
+		Index map[Key]string `mapstructure:"index"`
+	}
+
+	err := LoadFromBytes(&cfg, []byte("{}"), YAML)
+	if !errors.Is(err, ConfigParsingError) || !strings.Contains(err.Error(), "index: unsupported map key type") {
+		t.Errorf("Expected ConfigParsingError for a struct map key, got %v", err)
+	}
+}
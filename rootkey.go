@@ -0,0 +1,52 @@
+package configo
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// WithRootKey reads only the subtree of the config file under key, e.g.
+// "mytool" for the `mytool:` section of a file shared by several tools, or a
+// dotted key like "tools.mytool" for a nested one. The subtree is decoded as
+// if it were the whole file: sibling sections are ignored, also by WithStrict,
+// which only reports unknown keys within the subtree. Profiles (see
+// WithProfile) are sections of the subtree.
+//
+// Loading fails with ConfigParsingError if the file has no such key or its
+// value is not a map, so that a typo doesn't silently fall back to the
+// defaults. Without a file, e.g. with WithOptionalFile, there is nothing to
+// look the key up in and the defaults apply.
+func WithRootKey(key string) LoaderOption {
+	return func(l *loader) {
+		l.rootKey = key
+	}
+}
+
+// readRootKey returns a read function that reads the config with read and
+// sets the subtree under key as the config.
+func readRootKey(key string, read func(v *viper.Viper) error) func(v *viper.Viper) error {
+	return func(v *viper.Viper) error {
+		raw := viper.New()
+		if err := read(raw); err != nil {
+			return err
+		}
+		if len(raw.AllKeys()) == 0 && raw.ConfigFileUsed() == "" {
+			return nil
+		}
+
+		value := raw.Get(key)
+		if value == nil {
+			return fmt.Errorf("%w: root key %q not found in the config", ConfigParsingError, key)
+		}
+		section, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: root key %q is not a map", ConfigParsingError, key)
+		}
+
+		if file := raw.ConfigFileUsed(); file != "" {
+			v.SetConfigFile(file)
+		}
+		return v.MergeConfigMap(section)
+	}
+}
@@ -0,0 +1,108 @@
+package configo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type RootKeyTestConfig struct {
+	Host  string `mapstructure:"host" default:"localhost"`
+	Port  int    `mapstructure:"port" default:"8080"`
+	Cache struct {
+		Size int `mapstructure:"size"`
+	} `mapstructure:"cache"`
+}
+
+const rootKeyTestYAML = `othertool:
+  color: red
+  verbose: true
+mytool:
+  host: example.com
+  cache:
+    size: 10
+`
+
+func TestLoad_RootKey(t *testing.T) {
+	configPath := createTempYAMLConfig(t, rootKeyTestYAML)
+	defer os.Remove(configPath)
+
+	var cfg RootKeyTestConfig
+	result, err := LoadWithResult(&cfg, WithFile(configPath), WithRootKey("mytool"), WithStrict())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Соседние секции не считаются неизвестными ключами
+	if cfg.Host != "example.com" || cfg.Port != 8080 || cfg.Cache.Size != 10 {
+		t.Errorf("Expected the mytool section over the defaults, got %+v", cfg)
+	}
+	if source, origin := result.Source("cache.size"); source != SourceFile || origin != configPath {
+		t.Errorf("Expected cache.size from the file, got %v (%s)", source, origin)
+	}
+}
+
+func TestLoad_RootKeyStrictUnknownKey(t *testing.T) {
+	configPath := createTempYAMLConfig(t, rootKeyTestYAML+"  hots: typo\n")
+	defer os.Remove(configPath)
+
+	var cfg RootKeyTestConfig
+	err := Load(&cfg, WithFile(configPath), WithRootKey("mytool"), WithStrict())
+	if !errors.Is(err, UnknownKeysError) {
+		t.Fatalf("Expected UnknownKeysError, got %v", err)
+	}
+}
+
+func TestLoad_RootKeyEnvOverride(t *testing.T) {
+	configPath := createTempYAMLConfig(t, rootKeyTestYAML)
+	defer os.Remove(configPath)
+
+	setEnv(t, "HOST", "env.example.com")
+	defer unsetEnv(t, "HOST")
+
+	var cfg RootKeyTestConfig
+	if err := Load(&cfg, WithFile(configPath), WithRootKey("mytool")); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "env.example.com" || cfg.Cache.Size != 10 {
+		t.Errorf("Expected env over the mytool section, got %+v", cfg)
+	}
+}
+
+func TestLoad_RootKeyErrors(t *testing.T) {
+	configPath := createTempYAMLConfig(t, rootKeyTestYAML)
+	defer os.Remove(configPath)
+
+	for _, key := range []string{"mytol", "othertool.color"} {
+		var cfg RootKeyTestConfig
+		err := Load(&cfg, WithFile(configPath), WithRootKey(key))
+		if !errors.Is(err, ConfigParsingError) {
+			t.Errorf("WithRootKey(%q): expected ConfigParsingError, got %v", key, err)
+		}
+	}
+}
+
+func TestLoad_RootKeyOptionalFile(t *testing.T) {
+	var cfg RootKeyTestConfig
+	err := Load(&cfg, WithName("config"), WithSearchPaths(filepath.Join(t.TempDir(), "missing")),
+		WithOptionalFile(), WithRootKey("mytool"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "localhost" {
+		t.Errorf("Expected the default host without a file, got %+v", cfg)
+	}
+}
+
+func TestLoadFromBytes_RootKeyProfile(t *testing.T) {
+	data := []byte(`{"mytool": {"default": {"host": "a"}, "production": {"port": 1}}, "other": {"port": 2}}`)
+
+	var cfg RootKeyTestConfig
+	if err := LoadFromBytes(&cfg, data, JSON, WithRootKey("mytool"), WithProfile("production"), WithStrict()); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "a" || cfg.Port != 1 {
+		t.Errorf("Expected host 'a' and port 1, got %+v", cfg)
+	}
+}
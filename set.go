@@ -0,0 +1,261 @@
+package configo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// Set sets the field of the config struct cfg at the dotted bind key path,
+// e.g. "meta.version", from its string form. It is the inverse of FlattenKeys:
+// slice elements are addressed by index (servers.0.host) and map entries by
+// their key (labels.env).
+//
+// The value is parsed the way the loader parses environment variables:
+// durations like "30s", bools like "true", slices as comma-separated lists,
+// times in their `timeformat` layout (RFC3339 by default), []byte raw or
+// base64 with `encoding:"base64"`, and URLs, IP addresses, networks and
+// registered types (see RegisterType) from their text form.
+//
+// Nil pointers to sections and nil maps on the path are allocated, and the
+// index right after the last element of a slice appends a new one. cfg must
+// be a non-nil pointer to a struct. Unknown paths give an UnknownKeysError,
+// values that can't be parsed an InvalidValueError; cfg is left unchanged
+// in both cases.
+func Set(cfg interface{}, path string, value string) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected a non-nil pointer to a struct, got %T", ConfigParsingError, cfg)
+	}
+	if path == "" {
+		return fmt.Errorf("%w: empty path", UnknownKeysError)
+	}
+
+	s := setter{path: path, value: value}
+	return s.set(v.Elem(), "", strings.Split(path, "."))
+}
+
+// Get returns the value of the field of the config struct cfg (or a pointer
+// to one) at the dotted bind key path, using the same paths as Set and
+// FlattenKeys: servers.0.host, labels.env. Sections are returned as structs,
+// slices and maps as they are.
+//
+// The second result is false when the path doesn't exist: unknown fields,
+// missing map keys, out-of-range slice indices, or keys below a nil pointer.
+func Get(cfg interface{}, path string) (interface{}, bool) {
+	v := reflect.ValueOf(cfg)
+	if path == "" || !v.IsValid() {
+		return nil, false
+	}
+	for _, key := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil, false
+			}
+			v = v.Elem()
+		}
+
+		switch {
+		case isSection(v.Type()):
+			field, _, ok := lookupField(v, key)
+			if !ok {
+				return nil, false
+			}
+			v = field
+
+		case v.Kind() == reflect.Map:
+			mapKey := reflect.New(v.Type().Key()).Elem()
+			if err := decodeString(mapKey, "", key); err != nil {
+				return nil, false
+			}
+			v = v.MapIndex(mapKey)
+			if !v.IsValid() {
+				return nil, false
+			}
+
+		case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= v.Len() {
+				return nil, false
+			}
+			v = v.Index(index)
+
+		default:
+			return nil, false
+		}
+	}
+	return v.Interface(), true
+}
+
+// setter holds the path and value of a single Set call for error messages.
+type setter struct {
+	path  string
+	value string
+}
+
+// set sets the value at keys below the settable value v. tag is the tag of
+// the field v belongs to. Nothing is changed when an error is returned.
+func (s setter) set(v reflect.Value, tag reflect.StructTag, keys []string) error {
+	if v.Kind() == reflect.Ptr {
+		if !v.IsNil() {
+			return s.set(v.Elem(), tag, keys)
+		}
+		// Allocate the pointer only once the value below is set.
+		elem := reflect.New(v.Type().Elem())
+		if err := s.set(elem.Elem(), tag, keys); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if len(keys) == 0 {
+		if isSection(v.Type()) {
+			return fmt.Errorf("%w: %s is a section, not a value", InvalidValueError, s.path)
+		}
+		if err := decodeString(v, tag, s.value); err != nil {
+			return fmt.Errorf("%w: %s: cannot parse %q: %w", InvalidValueError, s.path, s.value, err)
+		}
+		return nil
+	}
+
+	switch {
+	case isSection(v.Type()):
+		field, fieldTag, ok := lookupField(v, keys[0])
+		if !ok {
+			return s.unknown()
+		}
+		return s.set(field, fieldTag, keys[1:])
+
+	case v.Kind() == reflect.Map:
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := decodeString(key, "", keys[0]); err != nil {
+			return s.unknown()
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := s.set(elem, tag, keys[1:]); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		index, err := strconv.Atoi(keys[0])
+		if err != nil || index < 0 || index > v.Len() || index == v.Len() && v.Kind() == reflect.Array {
+			return s.unknown()
+		}
+		if index < v.Len() {
+			return s.set(v.Index(index), tag, keys[1:])
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := s.set(elem, tag, keys[1:]); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, elem))
+		return nil
+
+	default:
+		return s.unknown()
+	}
+}
+
+func (s setter) unknown() error {
+	return fmt.Errorf("%w: %s", UnknownKeysError, s.path)
+}
+
+// isSection reports whether t is a struct with fields of its own, rather than
+// a type configured as a single string like time.Time or url.URL.
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !walker.TextType(t) && t != timeType
+}
+
+// lookupField returns the field of the struct value v with the given bind
+// key, looking into flattened embedded structs as well.
+func lookupField(v reflect.Value, key string) (reflect.Value, reflect.StructTag, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		if isFlattenedField(field) {
+			if found, tag, ok := lookupField(v.Field(i), key); ok {
+				return found, tag, true
+			}
+			continue
+		}
+		if strings.EqualFold(childBindKey(field, ""), key) {
+			return v.Field(i), field.Tag, true
+		}
+	}
+	return reflect.Value{}, "", false
+}
+
+// decodeString parses s into the settable value v with the decode hooks of
+// the loader. tag is the tag of the field v belongs to.
+func decodeString(v reflect.Value, tag reflect.StructTag, s string) error {
+	switch {
+	case v.Type() == timeType && tag.Get("timeformat") != "":
+		parsed, err := time.Parse(tag.Get("timeformat"), s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(parsed))
+		return nil
+
+	case v.Type() == bytesType && tag.Get("encoding") == "base64":
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		v.SetBytes(decoded)
+		return nil
+
+	case walker.IntBase(tag) != 10 && isInteger(v.Kind()):
+		parsed, err := parseInteger(v.Type(), s, walker.IntBase(tag))
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(parsed).Convert(v.Type()))
+		return nil
+	}
+	return decodeValue(v, s)
+}
+
+// decodeValue decodes data into the settable value v with the decode hooks
+// of the loader.
+func decodeValue(v reflect.Value, data interface{}) error {
+	// Decode into a copy so that a failed decode leaves v unchanged.
+	result := reflect.New(v.Type())
+	c := &mapstructure.DecoderConfig{
+		Result:           result.Interface(),
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	}
+	decoderConfig(c)
+	decoder, err := mapstructure.NewDecoder(c)
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(data); err != nil {
+		return err
+	}
+	v.Set(result.Elem())
+	return nil
+}
@@ -0,0 +1,206 @@
+package configo
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSet(t *testing.T) {
+	var cfg FlattenTestConfig
+	// Порядок важен: servers.0 нужно создать до servers.1.
+	steps := [][2]string{
+		{"version", "2.0"},
+		{"name", "app"},
+		{"debug", "true"},
+		{"ratio", "0.5"},
+		{"timeout", "30s"},
+		{"started", "2024-01-02"},
+		{"key", "c2VjcmV0"},
+		{"endpoint", "https://example.com/api"},
+		{"addr", "10.0.0.1"},
+		{"tags", "a,b"},
+		{"servers.0.host", "one"},
+		{"servers.0.port", "80"},
+		{"Servers.0.Port", "81"},
+		{"servers.1.host", "two"},
+		{"labels.env", "prod"},
+		{"labels.team", "core"},
+		{"backup.host", "spare"},
+		{"backup.port", "9090"},
+	}
+	for _, step := range steps {
+		if err := Set(&cfg, step[0], step[1]); err != nil {
+			t.Fatalf("Set(%q, %q): %v", step[0], step[1], err)
+		}
+	}
+
+	if cfg.Version != "2.0" || cfg.Name != "app" || !cfg.Debug || cfg.Ratio != 0.5 {
+		t.Errorf("unexpected scalars: %+v", cfg)
+	}
+	if cfg.Timeout != 30*time.Second {
+		t.Errorf("expected timeout 30s, got %v", cfg.Timeout)
+	}
+	if !cfg.Started.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected started 2024-01-02, got %v", cfg.Started)
+	}
+	if string(cfg.Key) != "secret" {
+		t.Errorf("expected base64 decoded key, got %q", cfg.Key)
+	}
+	if cfg.Endpoint.Host != "example.com" || !cfg.Addr.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("unexpected endpoint %v or addr %v", cfg.Endpoint, cfg.Addr)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Errorf("expected tags [a b], got %v", cfg.Tags)
+	}
+	expectedServers := []FlattenServer{{Host: "one", Port: 81}, {Host: "two"}}
+	if !reflect.DeepEqual(cfg.Servers, expectedServers) {
+		t.Errorf("expected servers %v, got %v", expectedServers, cfg.Servers)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]string{"env": "prod", "team": "core"}) {
+		t.Errorf("unexpected labels %v", cfg.Labels)
+	}
+	if cfg.Backup == nil || *cfg.Backup != (FlattenServer{Host: "spare", Port: 9090}) {
+		t.Errorf("expected allocated backup section, got %v", cfg.Backup)
+	}
+}
+
+func TestSet_Errors(t *testing.T) {
+	tests := []struct {
+		path, value string
+		want        error
+	}{
+		{"missing", "x", UnknownKeysError},
+		{"name.inner", "x", UnknownKeysError},
+		{"ignored", "x", UnknownKeysError},
+		{"internal", "x", UnknownKeysError},
+		{"servers.5.host", "x", UnknownKeysError},
+		{"servers.x.host", "x", UnknownKeysError},
+		{"backup.missing", "x", UnknownKeysError},
+		{"", "x", UnknownKeysError},
+		{"debug", "maybe", InvalidValueError},
+		{"timeout", "soon", InvalidValueError},
+		{"started", "2024-01-02T00:00:00Z", InvalidValueError},
+		{"key", "%%%", InvalidValueError},
+		{"backup", "x", InvalidValueError},
+		{"backup.port", "http", InvalidValueError},
+	}
+
+	for _, tt := range tests {
+		var cfg FlattenTestConfig
+		err := Set(&cfg, tt.path, tt.value)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Set(%q, %q): expected %v, got %v", tt.path, tt.value, tt.want, err)
+		}
+		// При ошибке конфигурация не меняется.
+		if !reflect.DeepEqual(cfg, FlattenTestConfig{}) {
+			t.Errorf("Set(%q, %q) changed the config: %+v", tt.path, tt.value, cfg)
+		}
+	}
+
+	if err := Set(FlattenTestConfig{}, "name", "x"); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("expected ConfigParsingError for a non-pointer, got %v", err)
+	}
+}
+
+func TestSet_FlattenKeysRoundTrip(t *testing.T) {
+	cfg := FlattenTestConfig{
+		FlattenMeta: FlattenMeta{Version: "1.0"},
+		Name:        "app",
+		Timeout:     time.Minute,
+		Started:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Key:         []byte("secret"),
+		Addr:        net.ParseIP("10.0.0.1"),
+		Labels:      map[string]string{"env": "prod"},
+		Backup:      &FlattenServer{Host: "spare", Port: 9090},
+	}
+
+	var restored FlattenTestConfig
+	for key, value := range FlattenKeys(cfg) {
+		if err := Set(&restored, key, value); err != nil {
+			t.Fatalf("Set(%q, %q): %v", key, value, err)
+		}
+	}
+	if !reflect.DeepEqual(FlattenKeys(restored), FlattenKeys(cfg)) {
+		t.Errorf("round trip mismatch:\n got %v\nwant %v", FlattenKeys(restored), FlattenKeys(cfg))
+	}
+}
+
+func TestGet(t *testing.T) {
+	cfg := FlattenTestConfig{
+		FlattenMeta: FlattenMeta{Version: "1.0"},
+		Timeout:     time.Minute,
+		Servers:     []FlattenServer{{Host: "one", Port: 80}},
+		Labels:      map[string]string{"env": "prod"},
+	}
+
+	tests := []struct {
+		path  string
+		want  interface{}
+		found bool
+	}{
+		{"version", "1.0", true},
+		{"timeout", time.Minute, true},
+		{"servers.0.host", "one", true},
+		{"Servers.0.Port", 80, true},
+		{"servers.0", FlattenServer{Host: "one", Port: 80}, true},
+		{"labels.env", "prod", true},
+		{"backup", (*FlattenServer)(nil), true},
+		{"servers.1.host", nil, false},
+		{"servers.-1.host", nil, false},
+		{"servers.x", nil, false},
+		{"labels.missing", nil, false},
+		{"backup.host", nil, false},
+		{"name.inner", nil, false},
+		{"ignored", nil, false},
+		{"internal", nil, false},
+		{"missing", nil, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		for _, in := range []interface{}{cfg, &cfg} {
+			got, found := Get(in, tt.path)
+			if found != tt.found || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get(%T, %q) = %#v, %v; want %#v, %v", in, tt.path, got, found, tt.want, tt.found)
+			}
+		}
+	}
+
+	if _, found := Get(nil, "name"); found {
+		t.Errorf("expected no value for a nil config")
+	}
+}
+
+func TestSet_IntBases(t *testing.T) {
+	var cfg IntBaseLoaderConfig
+	for _, step := range [][2]string{{"mode", "0600"}, {"umask", "22"}, {"flags", "0x0A"}, {"mask", "-0x10"}} {
+		if err := Set(&cfg, step[0], step[1]); err != nil {
+			t.Fatalf("Set(%q, %q): %v", step[0], step[1], err)
+		}
+	}
+	expected := IntBaseLoaderConfig{Mode: 0o600, Umask: 0o22, Flags: 0x0A, Mask: -0x10}
+	if cfg != expected {
+		t.Errorf("expected %+v, got %+v", expected, cfg)
+	}
+	if err := Set(&cfg, "mode", "0x1F"); !errors.Is(err, InvalidValueError) {
+		t.Errorf("expected InvalidValueError, got %v", err)
+	}
+
+	keys := FlattenKeys(cfg)
+	want := map[string]string{"mode": "0600", "umask": "022", "flags": "0xA", "mask": "-0x10"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected %v, got %v", want, keys)
+	}
+	var restored IntBaseLoaderConfig
+	for key, value := range keys {
+		if err := Set(&restored, key, value); err != nil {
+			t.Fatalf("Set(%q, %q): %v", key, value, err)
+		}
+	}
+	if restored != cfg {
+		t.Errorf("round trip mismatch: got %+v, want %+v", restored, cfg)
+	}
+}
@@ -0,0 +1,144 @@
+package configo
+
+import (
+	"os"
+	"reflect"
+
+	"github.com/spf13/viper"
+	"github.com/vsysa/configo/internal/parser/defaultValues"
+	"github.com/vsysa/configo/internal/parser/env"
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// Source tells where the loaded value of a field comes from.
+type Source int
+
+const (
+	// SourceNone means no source set the field: it keeps its zero value.
+	SourceNone Source = iota
+	// SourceDefault is the `default` tag of the field.
+	SourceDefault
+	// SourceDefaultsFile is the defaults file, see WithDefaultsFile.
+	SourceDefaultsFile
+	// SourceFile is the config file.
+	SourceFile
+	// SourceEnv is an environment variable.
+	SourceEnv
+	// SourceFlag is a command-line flag, see WithFlags.
+	SourceFlag
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceDefaultsFile:
+		return "defaults file"
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	default:
+		return "none"
+	}
+}
+
+// origin is the source of a field and its raw name.
+type origin struct {
+	Source Source
+	Name   string
+}
+
+// Source returns where the loaded value of the field with the dotted bind key
+// key, e.g. "meta.version", comes from, along with the raw origin: the path
+// of the (defaults) file, the name of the environment variable or the flag,
+// e.g. "--meta.version". The origin is empty for `default` tags and for
+// fields no source set. Keys of structs, rather than their fields, and
+// unknown keys give SourceNone.
+func (r *Result) Source(key string) (Source, string) {
+	o := r.sources[key]
+	return o.Source, o.Name
+}
+
+// collectSources records the source of every field with a value of its own,
+// going from the highest precedence down. envs holds the environment
+// variables of the fields bound to one; fields tagged with `env:"-"` have
+// none. defaults holds the values read from the defaults file, or nil, and
+// flags the names of the set flags by bind key.
+func collectSources(v, defaults *viper.Viper, cfg interface{}, envs []env.EnvInfo, flags map[string]string) (map[string]origin, error) {
+	tagDefaults, err := defaultValues.GetDefaultValues(cfg)
+	if err != nil {
+		return nil, err
+	}
+	hasTagDefault := make(map[string]bool, len(tagDefaults))
+	for _, d := range tagDefaults {
+		hasTagDefault[d.BindKey] = true
+	}
+
+	envVars := make(map[string]string, len(envs))
+	for _, e := range envs {
+		envVars[e.BindKey] = e.EnvVar
+	}
+
+	sources := make(map[string]origin)
+	for _, key := range leafBindKeys(reflect.TypeOf(cfg), "", walker.Expanding{}) {
+		envVar, bound := envVars[key]
+		value, inEnv := os.LookupEnv(envVar)
+		switch name, isFlag := flags[key]; {
+		case isFlag:
+			sources[key] = origin{Source: SourceFlag, Name: "--" + name}
+		case bound && inEnv && value != "": // Viper ignores empty variables
+			sources[key] = origin{Source: SourceEnv, Name: envVar}
+		case v.InConfig(key):
+			sources[key] = origin{Source: SourceFile, Name: v.ConfigFileUsed()}
+		case defaults != nil && defaults.InConfig(key):
+			sources[key] = origin{Source: SourceDefaultsFile, Name: defaults.ConfigFileUsed()}
+		case hasTagDefault[key]:
+			sources[key] = origin{Source: SourceDefault}
+		}
+	}
+	return sources, nil
+}
+
+// leafBindKeys collects the bind keys of all fields with a value of their own,
+// descending into nested structs and pointers to structs. Structs configured
+// as a single string, like time.Time, are values of their own. expanding
+// holds the struct types on the current path, so that a recursive type is not
+// expanded forever.
+func leafBindKeys(t reflect.Type, parentBindKey string, expanding walker.Expanding) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	expanding[t] = true
+	defer delete(expanding, t)
+
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported and ignored fields
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		if isFlattenedField(field) {
+			keys = append(keys, leafBindKeys(field.Type, parentBindKey, expanding)...)
+			continue
+		}
+
+		bindKey := childBindKey(field, parentBindKey)
+		switch {
+		case field.Type.Kind() == reflect.Struct && !isTextStruct(field.Type):
+			keys = append(keys, leafBindKeys(field.Type, bindKey, expanding)...)
+		case walker.SectionPointer(field.Type):
+			if !expanding.Recursive(field.Type) {
+				keys = append(keys, leafBindKeys(field.Type, bindKey, expanding)...)
+			}
+		default:
+			keys = append(keys, bindKey)
+		}
+	}
+	return keys
+}
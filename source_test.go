@@ -0,0 +1,115 @@
+package configo
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestLoadWithResult_Sources(t *testing.T) {
+	type Config struct {
+		Host   string `mapstructure:"host" default:"localhost"`
+		Port   int    `mapstructure:"port" default:"8080"`
+		Region string `mapstructure:"region"`
+		Level  string `mapstructure:"level" default:"info"`
+		Token  string `mapstructure:"token" required:"true"`
+		Mode   string `mapstructure:"mode" default:"fast"`
+		Meta   struct {
+			Version string `mapstructure:"version" default:"1.0"`
+			Build   string `mapstructure:"build"`
+		} `mapstructure:"meta"`
+	}
+
+	configPath := createTempYAMLConfig(t, "host: example.com\nport: 9090\nmeta:\n  version: \"2.0\"\n")
+	defer os.Remove(configPath)
+	defaultsPath := createTempYAMLConfig(t, "region: eu\nlevel: warn\n")
+	defer os.Remove(defaultsPath)
+
+	setEnv(t, "PORT", "7070")
+	defer unsetEnv(t, "PORT")
+	// Пустая переменная окружения не считается источником
+	setEnv(t, "LEVEL", "")
+	defer unsetEnv(t, "LEVEL")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := RegisterFlags(fs, Config{}); err != nil {
+		t.Fatalf("Failed to register flags: %v", err)
+	}
+	if err := fs.Parse([]string{"--token=secret", "--host=flag.example.com"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	var cfg Config
+	result, err := LoadWithResult(&cfg, WithFile(configPath), WithDefaultsFile(defaultsPath), WithFlags(fs))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Host != "flag.example.com" || cfg.Token != "secret" || cfg.Port != 7070 {
+		t.Errorf("Expected flags and env to override the file, got %+v", cfg)
+	}
+
+	tests := []struct {
+		key    string
+		source Source
+		origin string
+	}{
+		{"host", SourceFlag, "--host"},
+		{"token", SourceFlag, "--token"},
+		{"port", SourceEnv, "PORT"},
+		{"meta.version", SourceFile, configPath},
+		{"region", SourceDefaultsFile, defaultsPath},
+		{"level", SourceDefaultsFile, defaultsPath},
+		{"mode", SourceDefault, ""},
+		{"meta.build", SourceNone, ""},
+		{"meta", SourceNone, ""},
+		{"unknown", SourceNone, ""},
+	}
+	for _, tt := range tests {
+		source, origin := result.Source(tt.key)
+		if source != tt.source || origin != tt.origin {
+			t.Errorf("Source(%q) = %v, %q; expected %v, %q", tt.key, source, origin, tt.source, tt.origin)
+		}
+	}
+}
+
+// Поля без переменной окружения тоже получают источник из файла
+func TestLoadWithResult_SourcesWithoutEnv(t *testing.T) {
+	type Config struct {
+		Key   string `mapstructure:"key" env:"-"`
+		Inner struct {
+			Host string `mapstructure:"host"`
+			Port int    `mapstructure:"port" default:"80"`
+		} `mapstructure:"inner" env:"-"`
+	}
+
+	configPath := createTempYAMLConfig(t, "key: value\ninner:\n  host: example.com\n")
+	defer os.Remove(configPath)
+	setEnv(t, "KEY", "fromenv")
+	defer unsetEnv(t, "KEY")
+
+	var cfg Config
+	result, err := LoadWithResult(&cfg, WithFile(configPath))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Key != "value" {
+		t.Errorf("Expected key from the file, got %q", cfg.Key)
+	}
+
+	tests := []struct {
+		key    string
+		source Source
+		origin string
+	}{
+		{"key", SourceFile, configPath},
+		{"inner.host", SourceFile, configPath},
+		{"inner.port", SourceDefault, ""},
+	}
+	for _, tt := range tests {
+		source, origin := result.Source(tt.key)
+		if source != tt.source || origin != tt.origin {
+			t.Errorf("Source(%q) = %v, %q; expected %v, %q", tt.key, source, origin, tt.source, tt.origin)
+		}
+	}
+}
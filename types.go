@@ -0,0 +1,67 @@
+package configo
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// RegisterType makes t, e.g. a Money type parsed from "$4.99", configurable
+// by its string form without implementing encoding.TextUnmarshaler on it.
+//
+// The loader decodes string values of t fields (and *t fields) with parse,
+// which returns a value of type t or a pointer to one. Templates, the
+// environment help and the JSON Schema treat t as a string and show the
+// `default` tag as written; a default parse rejects is reported like any
+// malformed default. render converts the current value of a field back to
+// its string form, e.g. in GenerateYAMLFromValues or FlattenKeys. A nil
+// render falls back to fmt.Sprint.
+//
+// Registered types are looked up before the built-in special cases, so
+// registering time.Time, time.Duration, []byte, url.URL, net.IP or
+// net.IPNet replaces their built-in handling (including the `timeformat`
+// and `encoding` tags), and before encoding.TextUnmarshaler and the base64
+// binary form of encoding.BinaryUnmarshaler types. Registering t again
+// replaces its hooks. RegisterType is meant to be called during
+// initialization, before loading; it panics if t or parse is nil.
+func RegisterType(t reflect.Type, parse func(string) (interface{}, error), render func(interface{}) string) {
+	if t == nil || parse == nil {
+		panic("configo: RegisterType needs a type and a parse function")
+	}
+	walker.RegisterType(t, walker.TypeHooks{Parse: parse, Render: render})
+}
+
+// isRegisteredType reports whether t was registered with RegisterType.
+func isRegisteredType(t reflect.Type) bool {
+	_, ok := walker.CustomType(t)
+	return ok
+}
+
+// stringToRegisteredTypeHook parses strings into fields of registered types
+// with their parse function.
+func stringToRegisteredTypeHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+	hooks, ok := walker.CustomType(to)
+	if !ok {
+		return data, nil
+	}
+
+	value, err := hooks.Parse(reflect.ValueOf(data).String())
+	if err != nil {
+		return nil, err
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr && rv.Type().Elem() == to {
+		if rv.IsNil() {
+			return reflect.Zero(to).Interface(), nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || rv.Type() != to {
+		return nil, fmt.Errorf("parse function of %s returned %T", to, value)
+	}
+	return rv.Interface(), nil
+}
@@ -0,0 +1,205 @@
+package configo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Money хранит сумму в центах и задаётся строкой вида "$4.99"
+type Money struct {
+	Cents int64
+}
+
+func parseMoney(s string) (interface{}, error) {
+	amount, err := strconv.ParseFloat(strings.TrimPrefix(s, "$"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	return &Money{Cents: int64(amount*100 + 0.5)}, nil
+}
+
+func renderMoney(v interface{}) string {
+	m := v.(Money)
+	return fmt.Sprintf("$%d.%02d", m.Cents/100, m.Cents%100)
+}
+
+func init() {
+	RegisterType(reflect.TypeOf(Money{}), parseMoney, renderMoney)
+}
+
+type TypesTestConfig struct {
+	Price    Money  `mapstructure:"price" default:"$4.99" help:"Price"`
+	Discount *Money `mapstructure:"discount"`
+	Limit    Money  `mapstructure:"limit"`
+}
+
+func TestLoad_RegisteredType(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "discount: \"$0.50\"\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "LIMIT", "$100")
+	defer unsetEnv(t, "LIMIT")
+
+	var cfg TypesTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Price.Cents != 499 {
+		t.Errorf("Expected Price to be 499 cents, got %d", cfg.Price.Cents)
+	}
+	if cfg.Discount == nil || cfg.Discount.Cents != 50 {
+		t.Errorf("Expected Discount to be 50 cents, got %v", cfg.Discount)
+	}
+	if cfg.Limit.Cents != 10000 {
+		t.Errorf("Expected Limit to be 10000 cents, got %d", cfg.Limit.Cents)
+	}
+
+	if got := FlattenKeys(cfg)["discount"]; got != "$0.50" {
+		t.Errorf("Expected flattened discount to be '$0.50', got %q", got)
+	}
+}
+
+func TestLoad_RegisteredTypeInvalid(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "price: \"free\"\n")
+	defer os.Remove(configPath)
+
+	var cfg TypesTestConfig
+	err := Load(&cfg, WithFile(configPath))
+	if err == nil || !strings.Contains(err.Error(), `invalid amount "free"`) {
+		t.Errorf("Expected the parse error, got %v", err)
+	}
+
+	// Некорректный default сообщается как и для встроенных типов
+	var bad struct {
+		Price Money `mapstructure:"price" default:"free"`
+	}
+	if err := Load(&bad, WithFile(configPath)); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for a malformed default, got %v", err)
+	}
+}
+
+func TestGenerateYAML_RegisteredType(t *testing.T) {
+	template := GenerateYAMLTemplate(TypesTestConfig{}, true)
+	expected := `price: "$4.99" # Price
+discount: null
+limit: null
+`
+	if template != expected {
+		t.Errorf("Unexpected template:\n%s\nwant:\n%s", template, expected)
+	}
+
+	cfg := TypesTestConfig{Price: Money{Cents: 1250}, Limit: Money{Cents: 5}}
+	values := GenerateYAMLFromValues(cfg, false)
+	expected = `price: "$12.50"
+discount: "$0.00"
+limit: "$0.05"
+`
+	if values != expected {
+		t.Errorf("Unexpected values:\n%s\nwant:\n%s", values, expected)
+	}
+}
+
+// BinaryKey умеет только бинарную сериализацию, как некоторые криптографические ключи
+type BinaryKey struct {
+	material []byte
+}
+
+func (k BinaryKey) MarshalBinary() ([]byte, error) {
+	return append([]byte("key:"), k.material...), nil
+}
+
+func (k *BinaryKey) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte("key:")) {
+		return fmt.Errorf("invalid key %q", data)
+	}
+	k.material = bytes.Clone(data[len("key:"):])
+	return nil
+}
+
+type BinaryTypesTestConfig struct {
+	Signing BinaryKey  `mapstructure:"signing" default:"a2V5OmRlZmF1bHQ=" help:"Signing key"`
+	Backup  *BinaryKey `mapstructure:"backup"`
+	Rotated BinaryKey  `mapstructure:"rotated"`
+}
+
+func TestLoad_BinaryType(t *testing.T) {
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	configPath := createTempYAMLConfig(t, "backup: \""+encode("key:backup")+"\"\n")
+	defer os.Remove(configPath)
+
+	setEnv(t, "ROTATED", encode("key:rotated"))
+	defer unsetEnv(t, "ROTATED")
+
+	var cfg BinaryTypesTestConfig
+	if err := Load(&cfg, WithFile(configPath)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Значения задаются в base64 от бинарной формы
+	if string(cfg.Signing.material) != "default" {
+		t.Errorf("Expected the default signing key, got %q", cfg.Signing.material)
+	}
+	if cfg.Backup == nil || string(cfg.Backup.material) != "backup" {
+		t.Errorf("Expected the backup key from the file, got %v", cfg.Backup)
+	}
+	if string(cfg.Rotated.material) != "rotated" {
+		t.Errorf("Expected the rotated key from the env, got %q", cfg.Rotated.material)
+	}
+
+	if got := FlattenKeys(cfg)["signing"]; got != "a2V5OmRlZmF1bHQ=" {
+		t.Errorf("Expected flattened signing key in base64, got %q", got)
+	}
+
+	var merged BinaryTypesTestConfig
+	if err := Merge(&merged, cfg); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if string(merged.Rotated.material) != "rotated" {
+		t.Errorf("Expected the key to be merged as a whole, got %q", merged.Rotated.material)
+	}
+}
+
+func TestLoad_BinaryTypeInvalid(t *testing.T) {
+	for _, value := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("nokey"))} {
+		configPath := createTempYAMLConfig(t, "rotated: \""+value+"\"\n")
+		var cfg BinaryTypesTestConfig
+		if err := Load(&cfg, WithFile(configPath)); err == nil {
+			t.Errorf("Expected an error for rotated %q", value)
+		}
+		os.Remove(configPath)
+	}
+
+	var bad struct {
+		Signing BinaryKey `mapstructure:"signing" default:"%%%"`
+	}
+	if err := Load(&bad); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for a malformed default, got %v", err)
+	}
+}
+
+func TestGenerate_BinaryType(t *testing.T) {
+	template := GenerateYAMLTemplate(BinaryTypesTestConfig{}, true)
+	expected := `signing: "a2V5OmRlZmF1bHQ=" # Signing key
+backup: null
+rotated: null
+`
+	if template != expected {
+		t.Errorf("Unexpected template:\n%s\nwant:\n%s", template, expected)
+	}
+
+	schema, err := GenerateJSONSchema(BinaryTypesTestConfig{})
+	if err != nil {
+		t.Fatalf("Failed to generate the schema: %v", err)
+	}
+	if !strings.Contains(string(schema), `"contentEncoding": "base64"`) {
+		t.Errorf("Expected base64 content encoding in the schema:\n%s", schema)
+	}
+}
@@ -0,0 +1,130 @@
+package configo
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/vsysa/configo/validation"
+)
+
+// LoadAndValidate works like Load with the config read from the YAML file at
+// path, but reports every problem found at once instead of stopping at the
+// first one: missing required fields (RequiredFieldsError), values that
+// can't be decoded (ConfigParsingError), unknown keys with WithStrict
+// (UnknownKeysError) and tag constraint violations (InvalidValueError) are
+// joined into a single error. Use errors.Is to tell them apart, e.g. to exit
+// with a different status on a parse failure.
+//
+// A value that can't be decoded doesn't stop the validation of the other
+// fields; only the violations of the fields already reported as missing or
+// malformed are left out. Problems found before decoding, e.g. an unreadable
+// file or a malformed `timeformat` value, are returned alone, and the
+// Validate() method of the struct is only called once everything else
+// passed.
+func LoadAndValidate(cfg interface{}, path string, opts ...LoaderOption) error {
+	l := newLoader(opts)
+	l.configFilePath = path
+	l.aggregate = true
+	_, err := l.load(cfg, func(v *viper.Viper) error {
+		return readConfigFile(v, l.configFilePath)
+	})
+	return err
+}
+
+// ValidateBytes works like LoadAndValidate with the config held in data,
+// parsed as format, e.g. to lint a config file before a deploy. It decodes
+// into a new value of the type of cfg, a struct or a pointer to one, so cfg
+// itself is left untouched. Defaults and environment variables apply as with
+// Load, and WithStrict reports unknown keys.
+func ValidateBytes(cfg interface{}, data []byte, format Format, opts ...LoaderOption) error {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected a struct or a pointer to one, got %T", ConfigParsingError, cfg)
+	}
+
+	read, err := readFrom(bytes.NewReader(data), format)
+	if err != nil {
+		return err
+	}
+	l := newLoader(opts)
+	l.aggregate = true
+	_, err = l.load(reflect.New(t).Interface(), read)
+	return err
+}
+
+// decodeFailures returns the bind keys of the fields of t whose values in v
+// can't be decoded, descending into nested structs to find the innermost
+// ones.
+func decodeFailures(v *viper.Viper, t reflect.Type, parentBindKey string) []string {
+	var keys []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported and ignored fields
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		if isFlattenedField(field) {
+			keys = append(keys, decodeFailures(v, field.Type, parentBindKey)...)
+			continue
+		}
+
+		bindKey := childBindKey(field, parentBindKey)
+		if err := v.UnmarshalKey(bindKey, reflect.New(field.Type).Interface(), decoderConfig); err == nil {
+			continue
+		}
+
+		var nested []string
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			nested = decodeFailures(v, fieldType, bindKey)
+		}
+		if len(nested) == 0 {
+			nested = []string{bindKey}
+		}
+		keys = append(keys, nested...)
+	}
+
+	return keys
+}
+
+// skipViolations drops from the violations of err the ones of the fields
+// under the given keys, returning nil if none is left.
+func skipViolations(err error, keys []string) error {
+	violationsErr, ok := err.(validation.ViolationsError)
+	if !ok || len(keys) == 0 {
+		return err
+	}
+
+	var kept validation.Errors
+	for _, violation := range violationsErr.Violations() {
+		if !underAnyKey(violation.Field, keys) {
+			kept = append(kept, violation)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// underAnyKey reports whether path is one of keys or a path below one.
+func underAnyKey(path string, keys []string) bool {
+	for _, key := range keys {
+		if path == key || strings.HasPrefix(path, key+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package configo
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/vsysa/configo/validation"
+)
+
+type LoadAndValidateServer struct {
+	Host string `mapstructure:"host" validate:"required"`
+	Port int    `mapstructure:"port" min:"1" max:"65535"`
+}
+
+type LoadAndValidateConfig struct {
+	Name    string                `mapstructure:"name" required:"true" minlen:"3"`
+	Workers int                   `mapstructure:"workers" min:"1"`
+	Mode    string                `mapstructure:"mode" oneof:"fast safe"`
+	Server  LoadAndValidateServer `mapstructure:"server"`
+}
+
+func TestLoadAndValidate(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "name: app\nworkers: 2\nmode: fast\nserver:\n  host: localhost\n  port: 8080\n")
+	defer os.Remove(configPath)
+
+	var cfg LoadAndValidateConfig
+	if err := LoadAndValidate(&cfg, configPath); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Name != "app" || cfg.Server.Port != 8080 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+// Все ошибки собираются вместе, а поля с ошибкой разбора не проверяются повторно
+func TestLoadAndValidate_Aggregated(t *testing.T) {
+	configPath := createTempYAMLConfig(t, "workers: many\nmode: slow\nserver:\n  host: localhost\n  port: 70000\n")
+	defer os.Remove(configPath)
+
+	var cfg LoadAndValidateConfig
+	err := LoadAndValidate(&cfg, configPath)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for _, target := range []error{RequiredFieldsError, ConfigParsingError, InvalidValueError} {
+		if !errors.Is(err, target) {
+			t.Errorf("Expected error to match %v, got: %v", target, err)
+		}
+	}
+	if !strings.Contains(err.Error(), "name") || !strings.Contains(err.Error(), "workers") {
+		t.Errorf("Expected the missing and malformed fields to be reported, got: %v", err)
+	}
+
+	var violationsErr validation.ViolationsError
+	if !errors.As(err, &violationsErr) {
+		t.Fatalf("Expected a ViolationsError, got: %v", err)
+	}
+	var fields []string
+	for _, violation := range violationsErr.Violations() {
+		fields = append(fields, violation.Field)
+	}
+	if strings.Join(fields, ",") != "mode,server.port" {
+		t.Errorf("Expected violations of mode and server.port only, got %v", fields)
+	}
+
+	// Поля, которые удалось разобрать, заполнены
+	if cfg.Server.Host != "localhost" || cfg.Mode != "slow" {
+		t.Errorf("Expected the decoded fields to be set, got %+v", cfg)
+	}
+}
+
+func TestLoadAndValidate_MissingFile(t *testing.T) {
+	var cfg LoadAndValidateConfig
+	err := LoadAndValidate(&cfg, "does-not-exist.yaml")
+	if err == nil || errors.Is(err, InvalidValueError) {
+		t.Errorf("Expected a read error, got: %v", err)
+	}
+}
+
+func TestValidateBytes(t *testing.T) {
+	cfg := LoadAndValidateConfig{Name: "untouched"}
+	data := []byte(`{"name": "app", "workers": 2, "mode": "safe", "server": {"host": "localhost", "port": 8080}}`)
+	if err := ValidateBytes(&cfg, data, JSON); err != nil {
+		t.Fatalf("Expected a valid config, got: %v", err)
+	}
+	// Переданная структура не изменяется
+	if cfg != (LoadAndValidateConfig{Name: "untouched"}) {
+		t.Errorf("Expected cfg to be left untouched, got %+v", cfg)
+	}
+	if err := ValidateBytes(LoadAndValidateConfig{}, data, JSON); err != nil {
+		t.Errorf("Expected a struct value to be accepted, got: %v", err)
+	}
+}
+
+func TestValidateBytes_Aggregated(t *testing.T) {
+	data := []byte("workers: 0\nmode: slow\nextra: 1\nserver:\n  port: 70000\n")
+
+	err := ValidateBytes(LoadAndValidateConfig{}, data, YAML, WithStrict())
+	for _, target := range []error{RequiredFieldsError, UnknownKeysError, InvalidValueError} {
+		if !errors.Is(err, target) {
+			t.Errorf("Expected error to match %v, got: %v", target, err)
+		}
+	}
+	for _, path := range []string{"name", "extra", "workers", "mode", "server.host", "server.port"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("Expected %s to be reported, got: %v", path, err)
+		}
+	}
+
+	// Без WithStrict лишние ключи допустимы
+	if err := ValidateBytes(LoadAndValidateConfig{}, data, YAML); errors.Is(err, UnknownKeysError) {
+		t.Errorf("Expected unknown keys to be ignored without WithStrict, got: %v", err)
+	}
+}
+
+func TestValidateBytes_Errors(t *testing.T) {
+	if err := ValidateBytes(42, nil, YAML); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for a non-struct, got: %v", err)
+	}
+	if err := ValidateBytes(LoadAndValidateConfig{}, nil, Format(99)); !errors.Is(err, ConfigParsingError) {
+		t.Errorf("Expected ConfigParsingError for an unknown format, got: %v", err)
+	}
+	if err := ValidateBytes(LoadAndValidateConfig{}, []byte("name: [app"), YAML); err == nil {
+		t.Errorf("Expected an error for malformed YAML")
+	}
+}
@@ -0,0 +1,635 @@
+package validation
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Violation describes a single failed check of a config field.
+type Violation struct {
+	// Field is the dotted path of the field, e.g. "meta.version".
+	Field string
+	// Rule is the name of the failed rule, e.g. "required" or "max".
+	Rule string
+	// Message is a human-readable description of the failure.
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Field, v.Message)
+}
+
+// ViolationsError is implemented by errors that carry individual violations,
+// so that callers can render them in their own way.
+type ViolationsError interface {
+	error
+	Violations() []Violation
+}
+
+// Errors is the error returned by Validate. It lists every violation found.
+type Errors []Violation
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, v := range e {
+		messages = append(messages, v.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Violations returns the individual violations.
+func (e Errors) Violations() []Violation {
+	return e
+}
+
+var _ ViolationsError = Errors{}
+
+// Validate walks a config struct and checks every field against the rules
+// declared in its tags. It doesn't stop at the first failure: the returned
+// error lists all violations and implements ViolationsError.
+//
+// Supported rules of the `validate` tag (comma-separated, go-playground style):
+//
+//	required         the value must not be the zero value
+//	required_if=f v  required when the sibling field f has the value v
+//	min=N            minimum value for numbers, minimum length for strings, slices and maps
+//	max=N            maximum value for numbers, maximum length for strings, slices and maps
+//	len=N            exact length for strings, slices and maps, exact value for numbers
+//	minlen=N         minimum length for strings, slices and maps
+//	maxlen=N         maximum length for strings, slices and maps
+//	gt=N             numbers must be greater than N, durations than a duration like 1s
+//	gte=N            numbers and durations must be greater than or equal to N
+//	lt=N             numbers and durations must be less than N
+//	lte=N            numbers and durations must be less than or equal to N
+//	multipleof=N     ints and uints must be a multiple of N, e.g. a size aligned to 512
+//	oneof=a b        the value must be one of the space-separated values
+//	url              the value must be an absolute URL with a scheme and a host
+//	ip               the value must be an IPv4 or IPv6 address
+//	ipv4             the value must be an IPv4 address
+//	ipv6             the value must be an IPv6 address
+//	cidr             the value must be a network in CIDR notation, e.g. 10.0.0.0/8
+//	email            the value must be a bare email address, e.g. ops@example.com
+//	hostname         the value must be an RFC 1123 hostname, e.g. db-1.example.com
+//	uuid             the value must be a UUID, e.g. 123e4567-e89b-12d3-a456-426614174000
+//
+// The format rules, from url to uuid, check strings, and url checks url.URL
+// values as well. Empty values pass them; combine them with required if
+// needed.
+//
+// The comparators gt, gte, lt and lte compose, e.g. `validate:"gte=1,lt=100"`.
+// Unlike min and max they don't apply to lengths, and a time.Duration field
+// is compared with its parameter parsed as a duration, e.g. "500ms".
+//
+// The standalone `required:"true"`, `required_if:"f v"`, `oneof:"a b"`,
+// `min:"N"`, `max:"N"`, `len:"N"`, `minlen:"N"`, `maxlen:"N"`, `gt:"N"`,
+// `gte:"N"`, `lt:"N"`, `lte:"N"` and `multipleof:"N"` tags are supported as
+// well, and so is `format:"name"` for the format rules, e.g. `format:"email"`.
+// Lengths of strings are counted in characters.
+// Field paths are built from mapstructure keys, e.g. "meta.version".
+//
+// Fields of a struct sharing a `oneof_group:"name"` tag are mutually
+// exclusive: exactly one of them must be set (non-zero). A group spans the
+// fields of one struct, including its embedded structs, so the same name can
+// be reused in other structs. Both setting none and setting several fields of
+// a group is a violation listing the fields of the group.
+//
+// The field named by required_if is a sibling in the same struct (or one of
+// its embedded structs), given by its mapstructure key, e.g.
+// `required_if:"tls_enabled true"`. Its value is compared in its text form,
+// so "true", "8080" and "30s" match bools, numbers and durations.
+func Validate(cfg interface{}) error {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return fmt.Errorf("cannot validate nil %T", cfg)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot validate %T: not a struct", cfg)
+	}
+
+	var violations Errors
+	validateStruct(v, "", &violations)
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// rule is a single parsed rule of the `validate` tag.
+type rule struct {
+	Name  string
+	Param string
+}
+
+// group collects the fields of a `oneof_group` within a struct.
+type group struct {
+	Name   string
+	Fields []string
+	Set    []string
+}
+
+// condition is a `required_if` rule of a field, checked once all fields of
+// its struct are known.
+type condition struct {
+	Path  string
+	Value reflect.Value
+	Param string
+}
+
+// scope collects what the checks spanning the fields of a struct need: the
+// `oneof_group` groups, the `required_if` conditions and the values of the
+// fields by key.
+type scope struct {
+	Groups     []*group
+	Conditions []condition
+	Fields     map[string]reflect.Value
+}
+
+// validateStruct checks every field of a struct value and then the
+// `oneof_group` groups and `required_if` conditions of its fields.
+func validateStruct(v reflect.Value, parentPath string, violations *Errors) {
+	s := &scope{Fields: make(map[string]reflect.Value)}
+	validateFields(v, parentPath, s, violations)
+	for _, g := range s.Groups {
+		if field, msg := checkGroup(g); msg != "" {
+			*violations = append(*violations, Violation{Field: field, Rule: "oneof_group", Message: msg})
+		}
+	}
+	for _, c := range s.Conditions {
+		if msg := checkCondition(c, s.Fields, parentPath); msg != "" {
+			*violations = append(*violations, Violation{Field: c.Path, Rule: "required_if", Message: msg})
+		}
+	}
+}
+
+// validateFields checks the fields of a struct value, collecting the
+// struct-wide checks in s.
+func validateFields(v reflect.Value, parentPath string, s *scope, violations *Errors) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Skip unexported and ignored fields
+		if field.PkgPath != "" || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+
+		// Embedded structs without a mapstructure name and squashed structs
+		// share the parent path.
+		tagParts := strings.Split(field.Tag.Get("mapstructure"), ",")
+		if (field.Anonymous && tagParts[0] == "") || slices.Contains(tagParts[1:], "squash") {
+			if embedded := indirect(fieldValue); embedded.Kind() == reflect.Struct {
+				validateFields(embedded, parentPath, s, violations)
+				continue
+			}
+		}
+
+		path := fieldPath(field, parentPath)
+		s.Fields[fieldKey(field)] = fieldValue
+		if name := field.Tag.Get("oneof_group"); name != "" {
+			addToGroup(&s.Groups, name, path, !isZero(fieldValue))
+		}
+		for _, r := range parseRules(field.Tag) {
+			if r.Name == "required_if" {
+				s.Conditions = append(s.Conditions, condition{Path: path, Value: fieldValue, Param: r.Param})
+				continue
+			}
+			if msg := checkRule(r, fieldValue); msg != "" {
+				*violations = append(*violations, Violation{Field: path, Rule: r.Name, Message: msg})
+			}
+		}
+
+		validateNested(indirect(fieldValue), path, violations)
+	}
+}
+
+// validateNested descends into structs, including the elements of slices
+// and maps of structs. Slice elements get their index in the path.
+func validateNested(v reflect.Value, path string, violations *Errors) {
+	switch v.Kind() {
+	case reflect.Struct:
+		validateStruct(v, path, violations)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateNested(indirect(v.Index(i)), fmt.Sprintf("%s.%d", path, i), violations)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			validateNested(indirect(v.MapIndex(key)), fmt.Sprintf("%s.%v", path, key.Interface()), violations)
+		}
+	}
+}
+
+// addToGroup adds a field to the group of the given name, creating it on
+// first use.
+func addToGroup(groups *[]*group, name, path string, set bool) {
+	var g *group
+	for _, existing := range *groups {
+		if existing.Name == name {
+			g = existing
+			break
+		}
+	}
+	if g == nil {
+		g = &group{Name: name}
+		*groups = append(*groups, g)
+	}
+	g.Fields = append(g.Fields, path)
+	if set {
+		g.Set = append(g.Set, path)
+	}
+}
+
+// checkGroup validates that exactly one field of a group is set. It returns
+// the field the violation is reported on, the first conflicting one or the
+// first of the group, and the violation message, if any.
+func checkGroup(g *group) (string, string) {
+	switch len(g.Set) {
+	case 1:
+		return "", ""
+	case 0:
+		return g.Fields[0], fmt.Sprintf("exactly one of [%s] must be set (group %q)", strings.Join(g.Fields, ", "), g.Name)
+	default:
+		return g.Set[0], fmt.Sprintf("[%s] are mutually exclusive, only one of them may be set (group %q)", strings.Join(g.Set, ", "), g.Name)
+	}
+}
+
+// checkCondition checks a `required_if` condition against the fields of its
+// struct and returns the violation message, if any.
+func checkCondition(c condition, fields map[string]reflect.Value, parentPath string) string {
+	key, want, ok := strings.Cut(strings.TrimSpace(c.Param), " ")
+	if !ok {
+		return fmt.Sprintf("invalid required_if parameter %q", c.Param)
+	}
+	want = strings.TrimSpace(want)
+
+	other, found := fields[key]
+	if !found {
+		return fmt.Sprintf("required_if refers to unknown field %q", key)
+	}
+	if parentPath != "" {
+		key = parentPath + "." + key
+	}
+
+	other = indirect(other)
+	if other.Kind() == reflect.Ptr || other.Kind() == reflect.Interface || fmt.Sprint(other.Interface()) != want {
+		return ""
+	}
+	if isZero(c.Value) {
+		return fmt.Sprintf("required when %s=%s", key, want)
+	}
+	return ""
+}
+
+// parseRules collects the rules declared on a field.
+func parseRules(tag reflect.StructTag) []rule {
+	var rules []rule
+
+	if tag.Get("required") == "true" {
+		rules = append(rules, rule{Name: "required"})
+	}
+	if condition := tag.Get("required_if"); condition != "" {
+		rules = append(rules, rule{Name: "required_if", Param: condition})
+	}
+	if oneOf := tag.Get("oneof"); oneOf != "" {
+		rules = append(rules, rule{Name: "oneof", Param: oneOf})
+	}
+	for _, name := range []string{"min", "max", "len", "minlen", "maxlen", "gt", "gte", "lt", "lte", "multipleof"} {
+		if param := tag.Get(name); param != "" {
+			rules = append(rules, rule{Name: name, Param: param})
+		}
+	}
+	if format := tag.Get("format"); slices.Contains(formats, format) {
+		rules = append(rules, rule{Name: format})
+	} else if format != "" {
+		rules = append(rules, rule{Name: "format", Param: format})
+	}
+
+	for _, part := range strings.Split(tag.Get("validate"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		if name == "required" && slices.Contains(rules, rule{Name: "required"}) {
+			continue
+		}
+		rules = append(rules, rule{Name: name, Param: param})
+	}
+
+	return rules
+}
+
+// checkRule checks a single rule and returns the violation message, if any.
+func checkRule(r rule, v reflect.Value) string {
+	switch r.Name {
+	case "required":
+		if isZero(v) {
+			return "required"
+		}
+
+	case "oneof":
+		return checkOneOf(strings.Fields(r.Param), indirect(v))
+
+	case "min", "max", "len":
+		return checkBound(r, indirect(v))
+
+	case "minlen", "maxlen":
+		return checkLength(r, indirect(v))
+
+	case "gt", "gte", "lt", "lte":
+		return checkComparison(r, indirect(v))
+
+	case "multipleof":
+		return checkMultiple(r, indirect(v))
+
+	case "url", "ip", "ipv4", "ipv6", "cidr", "email", "hostname", "uuid":
+		return checkFormat(r.Name, indirect(v))
+
+	case "octal", "hex":
+		// The base of integers only changes how they are written.
+		return ""
+
+	case "format":
+		return fmt.Sprintf("unknown format %q", r.Param)
+	}
+	return ""
+}
+
+// formats are the names accepted by the `format` tag.
+var formats = []string{"url", "ip", "ipv4", "ipv6", "cidr", "email", "hostname", "uuid", "octal", "hex"}
+
+// uuidPattern matches a UUID in its canonical 8-4-4-4-12 hex form.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// checkFormat validates that a string is well-formed according to format,
+// e.g. a URL, an IP address or an email address. url.URL values are checked
+// to be absolute.
+func checkFormat(format string, v reflect.Value) string {
+	if u, ok := v.Interface().(url.URL); ok && format == "url" {
+		if u == (url.URL{}) {
+			return ""
+		}
+		return checkURL(&u)
+	}
+	if v.Kind() != reflect.String || v.String() == "" {
+		return ""
+	}
+
+	value := v.String()
+	switch format {
+	case "url":
+		if ok, _ := IsValidURL(value, "", false); !ok {
+			return fmt.Sprintf("value %q is not a valid URL", value)
+		}
+		u, _ := url.Parse(value)
+		return checkURL(u)
+	case "ip":
+		if net.ParseIP(value) == nil {
+			return fmt.Sprintf("value %q is not a valid IP address", value)
+		}
+	case "ipv4":
+		if ip := net.ParseIP(value); ip == nil || ip.To4() == nil || strings.Contains(value, ":") {
+			return fmt.Sprintf("value %q is not a valid IPv4 address", value)
+		}
+	case "ipv6":
+		if net.ParseIP(value) == nil || !strings.Contains(value, ":") {
+			return fmt.Sprintf("value %q is not a valid IPv6 address", value)
+		}
+	case "cidr":
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return fmt.Sprintf("value %q is not a valid CIDR network", value)
+		}
+	case "email":
+		if ok, _ := IsValidEmail(value, "", false); !ok {
+			return fmt.Sprintf("value %q is not a valid email address", value)
+		}
+	case "hostname":
+		if ok, _ := IsValidHostnameOrIP(value, "", false); !ok {
+			return fmt.Sprintf("value %q is not a valid hostname", value)
+		}
+	case "uuid":
+		if !uuidPattern.MatchString(value) {
+			return fmt.Sprintf("value %q is not a valid UUID", value)
+		}
+	}
+	return ""
+}
+
+// checkURL validates that a URL is absolute, with a scheme and a host.
+func checkURL(u *url.URL) string {
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Sprintf("value %q is not an absolute URL", u.String())
+	}
+	return ""
+}
+
+// checkOneOf validates string and integer values against the allowed values.
+func checkOneOf(allowed []string, v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return ""
+	}
+
+	value := fmt.Sprint(v.Interface())
+	if slices.Contains(allowed, value) {
+		return ""
+	}
+	return fmt.Sprintf("value %q is not one of [%s]", value, strings.Join(allowed, ", "))
+}
+
+// checkBound validates min, max and len rules. Numbers are compared by value,
+// strings, slices and maps by length.
+func checkBound(r rule, v reflect.Value) string {
+	limit, err := strconv.ParseFloat(r.Param, 64)
+	if err != nil {
+		return fmt.Sprintf("invalid %s parameter %q", r.Name, r.Param)
+	}
+
+	var actual float64
+	unit := ""
+	switch v.Kind() {
+	case reflect.String:
+		actual, unit = float64(len([]rune(v.String()))), "length "
+	case reflect.Slice, reflect.Array, reflect.Map:
+		actual, unit = float64(v.Len()), "length "
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = v.Float()
+	default:
+		return ""
+	}
+
+	switch {
+	case r.Name == "min" && actual < limit:
+		return fmt.Sprintf("%s%v is less than %s", unit, actual, r.Param)
+	case r.Name == "max" && actual > limit:
+		return fmt.Sprintf("%s%v is greater than %s", unit, actual, r.Param)
+	case r.Name == "len" && actual != limit:
+		return fmt.Sprintf("%s%v is not equal to %s", unit, actual, r.Param)
+	}
+	return ""
+}
+
+// checkLength validates minlen and maxlen rules. Unlike min and max they only
+// apply to the length of strings (in characters), slices and maps.
+func checkLength(r rule, v reflect.Value) string {
+	limit, err := strconv.Atoi(r.Param)
+	if err != nil {
+		return fmt.Sprintf("invalid %s parameter %q", r.Name, r.Param)
+	}
+
+	var actual int
+	switch v.Kind() {
+	case reflect.String:
+		actual = len([]rune(v.String()))
+	case reflect.Slice, reflect.Array, reflect.Map:
+		actual = v.Len()
+	default:
+		return ""
+	}
+
+	switch {
+	case r.Name == "minlen" && actual < limit:
+		return fmt.Sprintf("length %d is less than %d", actual, limit)
+	case r.Name == "maxlen" && actual > limit:
+		return fmt.Sprintf("length %d is greater than %d", actual, limit)
+	}
+	return ""
+}
+
+// durationType is used to compare time.Duration fields as durations rather
+// than as nanoseconds.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// checkComparison validates gt, gte, lt and lte rules. They only apply to
+// numbers and durations. Integers are compared exactly when the parameter is
+// an integer as well.
+func checkComparison(r rule, v reflect.Value) string {
+	order, actual, err := compareTo(v, r.Param)
+	if err != nil {
+		return fmt.Sprintf("invalid %s parameter %q", r.Name, r.Param)
+	}
+
+	switch {
+	case actual == "":
+		return ""
+	case r.Name == "gt" && order <= 0:
+		return fmt.Sprintf("value %s is not greater than %s", actual, r.Param)
+	case r.Name == "gte" && order < 0:
+		return fmt.Sprintf("value %s is less than %s", actual, r.Param)
+	case r.Name == "lt" && order >= 0:
+		return fmt.Sprintf("value %s is not less than %s", actual, r.Param)
+	case r.Name == "lte" && order > 0:
+		return fmt.Sprintf("value %s is greater than %s", actual, r.Param)
+	}
+	return ""
+}
+
+// compareTo compares a number or duration with param, returning -1, 0 or +1
+// and the value in its text form. The text is empty for other kinds, which
+// are not compared.
+func compareTo(v reflect.Value, param string) (int, string, error) {
+	if v.Type() == durationType {
+		limit, err := time.ParseDuration(param)
+		actual := time.Duration(v.Int())
+		return cmp.Compare(actual, limit), actual.String(), err
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if limit, err := strconv.ParseInt(param, 10, 64); err == nil {
+			return cmp.Compare(v.Int(), limit), strconv.FormatInt(v.Int(), 10), nil
+		}
+		limit, err := strconv.ParseFloat(param, 64)
+		return cmp.Compare(float64(v.Int()), limit), strconv.FormatInt(v.Int(), 10), err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if limit, err := strconv.ParseUint(param, 10, 64); err == nil {
+			return cmp.Compare(v.Uint(), limit), strconv.FormatUint(v.Uint(), 10), nil
+		}
+		limit, err := strconv.ParseFloat(param, 64)
+		return cmp.Compare(float64(v.Uint()), limit), strconv.FormatUint(v.Uint(), 10), err
+	case reflect.Float32, reflect.Float64:
+		limit, err := strconv.ParseFloat(param, 64)
+		return cmp.Compare(v.Float(), limit), strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), err
+	default:
+		return 0, "", nil
+	}
+}
+
+// checkMultiple validates multipleof rules. They only apply to int and uint
+// kinds; the parameter must be a positive integer.
+func checkMultiple(r rule, v reflect.Value) string {
+	step, err := strconv.ParseInt(r.Param, 10, 64)
+	if err != nil || step <= 0 {
+		return fmt.Sprintf("invalid %s parameter %q", r.Name, r.Param)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int()%step != 0 {
+			return fmt.Sprintf("value %d is not a multiple of %d", v.Int(), step)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint()%uint64(step) != 0 {
+			return fmt.Sprintf("value %d is not a multiple of %d", v.Uint(), step)
+		}
+	}
+	return ""
+}
+
+// isZero reports whether a value is unset: nil pointers, empty collections
+// and zero primitives.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// indirect dereferences pointers and interfaces until a non-nil value is found.
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// fieldKey returns the key of a struct field: the mapstructure tag or the
+// lowercase field name.
+func fieldKey(field reflect.StructField) string {
+	if msKey := strings.Split(field.Tag.Get("mapstructure"), ",")[0]; msKey != "" {
+		return msKey
+	}
+	return strings.ToLower(field.Name)
+}
+
+// fieldPath builds the dotted path of a struct field from its key.
+func fieldPath(field reflect.StructField, parentPath string) string {
+	name := fieldKey(field)
+	if parentPath != "" {
+		return parentPath + "." + name
+	}
+	return name
+}
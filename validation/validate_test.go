@@ -0,0 +1,355 @@
+package validation
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateMeta struct {
+	Version string `mapstructure:"version" validate:"required"`
+}
+
+type validateItem struct {
+	Name string `mapstructure:"name" validate:"required"`
+}
+
+type validateConfig struct {
+	Host     string            `mapstructure:"host" required:"true"`
+	Port     int               `mapstructure:"port" validate:"min=1,max=65535"`
+	LogLevel string            `mapstructure:"log_level" validate:"oneof=debug info"`
+	Mode     int               `mapstructure:"mode" oneof:"1 2"`
+	Key      string            `mapstructure:"key" validate:"len=4"`
+	Tags     []string          `mapstructure:"tags" validate:"min=1"`
+	Labels   map[string]string `mapstructure:"labels" validate:"max=1"`
+	Meta     validateMeta      `mapstructure:"meta"`
+	Items    []validateItem    `mapstructure:"items"`
+	Optional *validateMeta     `mapstructure:"optional"`
+}
+
+func TestValidate(t *testing.T) {
+	cfg := validateConfig{
+		Port:     70000,
+		LogLevel: "trace",
+		Mode:     3,
+		Key:      "abc",
+		Labels:   map[string]string{"a": "1", "b": "2"},
+		Items:    []validateItem{{Name: "ok"}, {}},
+	}
+
+	err := Validate(cfg)
+	require.Error(t, err)
+
+	var violationsErr ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+
+	expected := []Violation{
+		{Field: "host", Rule: "required", Message: "required"},
+		{Field: "port", Rule: "max", Message: "70000 is greater than 65535"},
+		{Field: "log_level", Rule: "oneof", Message: `value "trace" is not one of [debug, info]`},
+		{Field: "mode", Rule: "oneof", Message: `value "3" is not one of [1, 2]`},
+		{Field: "key", Rule: "len", Message: "length 3 is not equal to 4"},
+		{Field: "tags", Rule: "min", Message: "length 0 is less than 1"},
+		{Field: "labels", Rule: "max", Message: "length 2 is greater than 1"},
+		{Field: "meta.version", Rule: "required", Message: "required"},
+		{Field: "items.1.name", Rule: "required", Message: "required"},
+	}
+	assert.Equal(t, expected, violationsErr.Violations())
+	assert.Contains(t, err.Error(), "host: required; port: 70000 is greater than 65535")
+}
+
+func TestValidate_Valid(t *testing.T) {
+	cfg := &validateConfig{
+		Host:     "localhost",
+		Port:     8080,
+		LogLevel: "info",
+		Mode:     1,
+		Key:      "abcd",
+		Tags:     []string{"a"},
+		Meta:     validateMeta{Version: "1.0"},
+		Optional: &validateMeta{Version: "2.0"},
+	}
+
+	assert.NoError(t, Validate(cfg))
+}
+
+func TestValidate_NotAStruct(t *testing.T) {
+	assert.Error(t, Validate(42))
+	assert.Error(t, Validate((*validateConfig)(nil)))
+}
+
+func TestValidate_RangeTags(t *testing.T) {
+	type Config struct {
+		Port    int     `mapstructure:"port" min:"1" max:"65535"`
+		Workers uint    `mapstructure:"workers" min:"1"`
+		Ratio   float64 `mapstructure:"ratio" max:"0.5"`
+	}
+
+	assert.NoError(t, Validate(Config{Port: 8080, Workers: 2, Ratio: 0.5}))
+
+	err := Validate(Config{Port: 0, Workers: 0, Ratio: 0.75})
+	var violationsErr ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "port", Rule: "min", Message: "0 is less than 1"},
+		{Field: "workers", Rule: "min", Message: "0 is less than 1"},
+		{Field: "ratio", Rule: "max", Message: "0.75 is greater than 0.5"},
+	}, violationsErr.Violations())
+}
+
+func TestValidate_LengthTags(t *testing.T) {
+	type Config struct {
+		APIKey   string         `mapstructure:"api_key" len:"4"`
+		Password string         `mapstructure:"password" minlen:"8" maxlen:"16"`
+		Name     string         `mapstructure:"name" validate:"maxlen=3"`
+		Hosts    []string       `mapstructure:"hosts" validate:"minlen=1"`
+		Limits   map[string]int `mapstructure:"limits" maxlen:"1"`
+		Port     int            `mapstructure:"port" minlen:"10"`
+	}
+
+	assert.NoError(t, Validate(Config{
+		APIKey:   "abcd",
+		Password: "password",
+		Name:     "ёжи",
+		Hosts:    []string{"a"},
+		Port:     1,
+	}))
+
+	err := Validate(Config{
+		APIKey:   "abc",
+		Password: "short",
+		Name:     "four",
+		Limits:   map[string]int{"a": 1, "b": 2},
+	})
+	var violationsErr ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "api_key", Rule: "len", Message: "length 3 is not equal to 4"},
+		{Field: "password", Rule: "minlen", Message: "length 5 is less than 8"},
+		{Field: "name", Rule: "maxlen", Message: "length 4 is greater than 3"},
+		{Field: "hosts", Rule: "minlen", Message: "length 0 is less than 1"},
+		{Field: "limits", Rule: "maxlen", Message: "length 2 is greater than 1"},
+	}, violationsErr.Violations())
+}
+
+func TestValidate_OneOfGroup(t *testing.T) {
+	type Listener struct {
+		Socket string `mapstructure:"socket" oneof_group:"address"`
+	}
+	type TLS struct {
+		Listener   `mapstructure:",squash"`
+		CertFile   string `mapstructure:"cert_file" oneof_group:"cert"`
+		CertInline string `mapstructure:"cert_inline" oneof_group:"cert"`
+		Port       *int   `mapstructure:"port" oneof_group:"address"`
+		Name       string `mapstructure:"name"`
+	}
+	type Config struct {
+		TLS TLS `mapstructure:"tls"`
+	}
+
+	port := 0
+	assert.NoError(t, Validate(Config{TLS: TLS{CertFile: "cert.pem", Port: &port}}))
+	assert.NoError(t, Validate(Config{TLS: TLS{CertInline: "PEM", Listener: Listener{Socket: "/run/app.sock"}}}))
+
+	err := Validate(Config{TLS: TLS{CertFile: "cert.pem", CertInline: "PEM"}})
+	var violationsErr ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "tls.socket", Rule: "oneof_group", Message: `exactly one of [tls.socket, tls.port] must be set (group "address")`},
+		{Field: "tls.cert_file", Rule: "oneof_group", Message: `[tls.cert_file, tls.cert_inline] are mutually exclusive, only one of them may be set (group "cert")`},
+	}, violationsErr.Violations())
+}
+
+func TestValidate_Formats(t *testing.T) {
+	type Config struct {
+		BaseURL  string  `mapstructure:"base_url" validate:"url"`
+		Callback url.URL `mapstructure:"callback" validate:"url"`
+		BindIP   string  `mapstructure:"bind_ip" validate:"ip"`
+		Allowed  string  `mapstructure:"allowed_cidr" validate:"cidr"`
+		Optional string  `mapstructure:"optional" validate:"url"`
+	}
+
+	assert.NoError(t, Validate(Config{
+		BaseURL:  "https://example.com/api",
+		Callback: url.URL{Scheme: "http", Host: "localhost:8080"},
+		BindIP:   "::1",
+		Allowed:  "10.0.0.0/8",
+	}))
+
+	err := Validate(Config{
+		BaseURL:  "example.com/api",
+		Callback: url.URL{Path: "/hook"},
+		BindIP:   "300.0.0.1",
+		Allowed:  "10.0.0.1",
+	})
+	var violationsErr ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "base_url", Rule: "url", Message: `value "example.com/api" is not a valid URL`},
+		{Field: "callback", Rule: "url", Message: `value "/hook" is not an absolute URL`},
+		{Field: "bind_ip", Rule: "ip", Message: `value "300.0.0.1" is not a valid IP address`},
+		{Field: "allowed_cidr", Rule: "cidr", Message: `value "10.0.0.1" is not a valid CIDR network`},
+	}, violationsErr.Violations())
+}
+
+func TestValidate_MultipleOf(t *testing.T) {
+	type Config struct {
+		ChunkSize int    `mapstructure:"chunk_size" multipleof:"512"`
+		Offset    int64  `mapstructure:"offset" validate:"multipleof=4"`
+		Pages     *uint  `mapstructure:"pages" multipleof:"8"`
+		Ratio     string `mapstructure:"ratio" multipleof:"2"`
+		Broken    int    `mapstructure:"broken" multipleof:"0"`
+	}
+
+	pages := uint(16)
+	err := Validate(Config{ChunkSize: 1024, Offset: -8, Pages: &pages, Ratio: "odd"})
+	var violationsErr ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "broken", Rule: "multipleof", Message: `invalid multipleof parameter "0"`},
+	}, violationsErr.Violations())
+
+	pages = 12
+	err = Validate(Config{ChunkSize: 1000, Offset: 6, Pages: &pages})
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "chunk_size", Rule: "multipleof", Message: "value 1000 is not a multiple of 512"},
+		{Field: "offset", Rule: "multipleof", Message: "value 6 is not a multiple of 4"},
+		{Field: "pages", Rule: "multipleof", Message: "value 12 is not a multiple of 8"},
+		{Field: "broken", Rule: "multipleof", Message: `invalid multipleof parameter "0"`},
+	}, violationsErr.Violations())
+}
+
+func TestValidate_Comparators(t *testing.T) {
+	type Config struct {
+		Workers  int            `mapstructure:"workers" validate:"gte=1,lt=100"`
+		Ratio    float64        `mapstructure:"ratio" gt:"0" lte:"1"`
+		Size     uint64         `mapstructure:"size" validate:"lte=18446744073709551615,gt=-1"`
+		Big      int64          `mapstructure:"big" lt:"9007199254740993"`
+		Timeout  time.Duration  `mapstructure:"timeout" validate:"gt=0s,lte=1m"`
+		Interval *time.Duration `mapstructure:"interval" gte:"500ms"`
+		Name     string         `mapstructure:"name" gt:"3"`
+		Broken   int            `mapstructure:"broken" gt:"many"`
+		Invalid  time.Duration  `mapstructure:"invalid" lt:"10"`
+	}
+
+	interval := time.Second
+	valid := Config{Workers: 1, Ratio: 1, Size: 1 << 63, Big: 9007199254740992, Timeout: time.Minute, Interval: &interval, Name: "a"}
+	err := Validate(valid)
+	var violationsErr ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "broken", Rule: "gt", Message: `invalid gt parameter "many"`},
+		{Field: "invalid", Rule: "lt", Message: `invalid lt parameter "10"`},
+	}, violationsErr.Violations())
+
+	interval = 100 * time.Millisecond
+	err = Validate(Config{Workers: 100, Ratio: 0, Big: 9007199254740993, Timeout: 90 * time.Second, Interval: &interval})
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "workers", Rule: "lt", Message: "value 100 is not less than 100"},
+		{Field: "ratio", Rule: "gt", Message: "value 0 is not greater than 0"},
+		{Field: "big", Rule: "lt", Message: "value 9007199254740993 is not less than 9007199254740993"},
+		{Field: "timeout", Rule: "lte", Message: "value 1m30s is greater than 1m"},
+		{Field: "interval", Rule: "gte", Message: "value 100ms is less than 500ms"},
+		{Field: "broken", Rule: "gt", Message: `invalid gt parameter "many"`},
+		{Field: "invalid", Rule: "lt", Message: `invalid lt parameter "10"`},
+	}, violationsErr.Violations())
+
+	err = Validate(Config{Workers: 0, Ratio: 1.5, Timeout: 0, Interval: &interval})
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Contains(t, violationsErr.Violations(), Violation{Field: "workers", Rule: "gte", Message: "value 0 is less than 1"})
+	assert.Contains(t, violationsErr.Violations(), Violation{Field: "ratio", Rule: "lte", Message: "value 1.5 is greater than 1"})
+	assert.Contains(t, violationsErr.Violations(), Violation{Field: "timeout", Rule: "gt", Message: "value 0s is not greater than 0s"})
+}
+
+func TestValidate_RequiredIf(t *testing.T) {
+	type TLS struct {
+		Enabled *bool  `mapstructure:"enabled"`
+		Cert    string `mapstructure:"cert" required_if:"enabled true"`
+	}
+	type Config struct {
+		Mode       string        `mapstructure:"mode"`
+		TLSCert    string        `mapstructure:"tls_cert" required_if:"tls_enabled true"`
+		Timeout    time.Duration `mapstructure:"timeout" validate:"required_if=mode remote"`
+		TLSEnabled bool          `mapstructure:"tls_enabled"`
+		TLS        TLS           `mapstructure:"tls"`
+		Broken     string        `mapstructure:"broken" required_if:"missing yes"`
+		Malformed  string        `mapstructure:"malformed" required_if:"mode"`
+	}
+
+	enabled := true
+	err := Validate(Config{TLSEnabled: true, Mode: "remote", TLS: TLS{Enabled: &enabled}})
+	var violationsErr ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "tls.cert", Rule: "required_if", Message: "required when tls.enabled=true"},
+		{Field: "tls_cert", Rule: "required_if", Message: "required when tls_enabled=true"},
+		{Field: "timeout", Rule: "required_if", Message: "required when mode=remote"},
+		{Field: "broken", Rule: "required_if", Message: `required_if refers to unknown field "missing"`},
+		{Field: "malformed", Rule: "required_if", Message: `invalid required_if parameter "mode"`},
+	}, violationsErr.Violations())
+
+	err = Validate(Config{TLSCert: "cert.pem", TLSEnabled: true, Mode: "local"})
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "broken", Rule: "required_if", Message: `required_if refers to unknown field "missing"`},
+		{Field: "malformed", Rule: "required_if", Message: `invalid required_if parameter "mode"`},
+	}, violationsErr.Violations())
+}
+
+func TestValidate_FormatTag(t *testing.T) {
+	type Config struct {
+		Contact  string `mapstructure:"contact" format:"email"`
+		Host     string `mapstructure:"host" format:"hostname"`
+		ID       string `mapstructure:"id" format:"uuid"`
+		Endpoint string `mapstructure:"endpoint" format:"url"`
+		V4       string `mapstructure:"v4" format:"ipv4"`
+		V6       string `mapstructure:"v6" validate:"ipv6"`
+		Unset    string `mapstructure:"unset" format:"email"`
+		Broken   string `mapstructure:"broken" format:"phone"`
+	}
+
+	err := Validate(Config{
+		Contact:  "ops@example.com",
+		Host:     "db-1.example.com",
+		ID:       "123e4567-e89b-12d3-a456-426614174000",
+		Endpoint: "https://example.com",
+		V4:       "10.0.0.1",
+		V6:       "::1",
+	})
+	var violationsErr ViolationsError
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "broken", Rule: "format", Message: `unknown format "phone"`},
+	}, violationsErr.Violations())
+
+	err = Validate(Config{
+		Contact:  "Ops <ops@example.com>",
+		Host:     "-db.example.com",
+		ID:       "123e4567e89b12d3a456426614174000",
+		Endpoint: "example.com",
+		V4:       "::ffff:10.0.0.1",
+		V6:       "10.0.0.1",
+	})
+	require.True(t, errors.As(err, &violationsErr))
+	assert.Equal(t, []Violation{
+		{Field: "contact", Rule: "email", Message: `value "Ops <ops@example.com>" is not a valid email address`},
+		{Field: "host", Rule: "hostname", Message: `value "-db.example.com" is not a valid hostname`},
+		{Field: "id", Rule: "uuid", Message: `value "123e4567e89b12d3a456426614174000" is not a valid UUID`},
+		{Field: "endpoint", Rule: "url", Message: `value "example.com" is not a valid URL`},
+		{Field: "v4", Rule: "ipv4", Message: `value "::ffff:10.0.0.1" is not a valid IPv4 address`},
+		{Field: "v6", Rule: "ipv6", Message: `value "10.0.0.1" is not a valid IPv6 address`},
+		{Field: "broken", Rule: "format", Message: `unknown format "phone"`},
+	}, violationsErr.Violations())
+
+	assert.Empty(t, checkFormat("hostname", reflect.ValueOf("localhost")))
+	assert.NotEmpty(t, checkFormat("hostname", reflect.ValueOf(strings.Repeat("a", 64)+".com")))
+	assert.Equal(t, `value "/hook" is not an absolute URL`, checkFormat("url", reflect.ValueOf("/hook")))
+}
@@ -0,0 +1,30 @@
+package configo
+
+import (
+	"reflect"
+
+	"github.com/vsysa/configo/internal/parser/walker"
+)
+
+// FieldInfo describes a config field visited by Walk: its dotted path and
+// bind key, dereferenced type and kind, tags, default, help text and unit,
+// and the enclosing struct field as Parent.
+type FieldInfo = walker.Field
+
+// SkipStruct can be returned by the visit function of Walk for a struct field
+// to skip its nested fields.
+var SkipStruct = walker.SkipStruct
+
+// Walk calls visit for every field of the config struct cfg in declaration
+// order, applying the same key-name priority and ignore rules as the template
+// generators: unexported fields and fields tagged with `yaml:"-"` or
+// `mapstructure:"-"` are skipped, embedded and squashed structs are flattened.
+//
+// Struct fields are visited before their nested fields. Slices and maps are
+// visited as single fields. A field referring back to a struct being walked,
+// like Children []Node inside Node, is visited with Recursive set and is not
+// descended into again. Walking stops at the first error returned by
+// visit, except SkipStruct, and that error is returned.
+func Walk(cfg interface{}, visit func(FieldInfo) error) error {
+	return walker.Walk(reflect.TypeOf(cfg), visit)
+}
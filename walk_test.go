@@ -0,0 +1,26 @@
+package configo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	var paths []string
+	err := Walk(&LoaderTestConfig{}, func(f FieldInfo) error {
+		paths = append(paths, f.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	expected := []string{"host", "port", "meta", "meta.version", "meta.build"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Unexpected paths: %v", paths)
+	}
+
+	if err := Walk(42, func(FieldInfo) error { return nil }); err == nil {
+		t.Error("Expected an error for a non-struct value")
+	}
+}
@@ -0,0 +1,100 @@
+package configo
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchDebounce is the delay Watch waits after the last change of the file
+// before reloading it. Editors often write a file several times in a row.
+var WatchDebounce = 100 * time.Millisecond
+
+// Watch loads the YAML file at path into cfg, which must be a non-nil pointer
+// to a struct, and then reloads it whenever the file changes.
+//
+// Every reload decodes and validates the file into a fresh value of the same
+// type, exactly like Load. onReload is called with the previous and the new
+// config (both pointers of the type of cfg) only if the reload succeeds;
+// otherwise the previous config is kept and the error is sent on the
+// returned channel. cfg itself is populated only by the initial load.
+//
+// The error channel has a buffer of one; errors are dropped while it is full.
+// Watching stops and the channel is closed when ctx is cancelled.
+func Watch(ctx context.Context, path string, cfg interface{}, onReload func(oldCfg, newCfg interface{}), opts ...LoaderOption) (<-chan error, error) {
+	// The options are copied, so that a spare capacity of the caller's slice
+	// is not written to.
+	opts = append(append([]LoaderOption(nil), opts...), WithFile(path))
+	if err := Load(cfg, opts...); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating file watcher: %w", err)
+	}
+	// The directory is watched, so that files replaced by a rename (as many
+	// editors save them) are still tracked.
+	file := filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("error watching %s: %w", path, err)
+	}
+
+	errs := make(chan error, 1)
+	sendErr := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	go func() {
+		defer close(errs)
+		defer watcher.Close()
+
+		current := cfg
+		cfgType := reflect.TypeOf(cfg).Elem()
+
+		timer := time.NewTimer(WatchDebounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != file || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				timer.Reset(WatchDebounce)
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				sendErr(err)
+
+			case <-timer.C:
+				newCfg := reflect.New(cfgType).Interface()
+				if err := Load(newCfg, opts...); err != nil {
+					sendErr(fmt.Errorf("Unable to load config on update: %w", err))
+					continue
+				}
+				onReload(current, newCfg)
+				current = newCfg
+			}
+		}
+	}()
+
+	return errs, nil
+}
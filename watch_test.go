@@ -0,0 +1,104 @@
+package configo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type WatchTestConfig struct {
+	Port int `mapstructure:"port" default:"8080" max:"65535"`
+}
+
+// Проверка перезагрузки: валидное изменение вызывает callback,
+// невалидное — возвращает ошибку и сохраняет старую конфигурацию
+func TestWatch(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("port: 9000\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type reload struct{ old, new *WatchTestConfig }
+	reloads := make(chan reload, 10)
+
+	var cfg WatchTestConfig
+	errs, err := Watch(ctx, configPath, &cfg, func(oldCfg, newCfg interface{}) {
+		reloads <- reload{oldCfg.(*WatchTestConfig), newCfg.(*WatchTestConfig)}
+	})
+	if err != nil {
+		t.Fatalf("Failed to start watching: %v", err)
+	}
+	if cfg.Port != 9000 {
+		t.Fatalf("Expected initial Port to be 9000, got %d", cfg.Port)
+	}
+
+	// Несколько быстрых записей подряд дают одну перезагрузку
+	for _, content := range []string{"port: 9001\n", "port: 9002\n"} {
+		if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	select {
+	case r := <-reloads:
+		if r.old.Port != 9000 || r.new.Port != 9002 {
+			t.Errorf("Expected reload 9000 -> 9002, got %d -> %d", r.old.Port, r.new.Port)
+		}
+	case err := <-errs:
+		t.Fatalf("Unexpected reload error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reload")
+	}
+
+	if err := os.WriteFile(configPath, []byte("port: 70000\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, InvalidValueError) {
+			t.Errorf("Expected InvalidValueError, got %v", err)
+		}
+	case r := <-reloads:
+		t.Fatalf("Unexpected reload to %d", r.new.Port)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reload error")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-errs:
+		if ok {
+			t.Error("Expected error channel to be closed after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for error channel to close")
+	}
+}
+
+func TestWatch_InitialLoadError(t *testing.T) {
+	_, err := Watch(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"), &WatchTestConfig{}, func(_, _ interface{}) {})
+	if err == nil {
+		t.Error("Expected error for missing config file")
+	}
+}
+
+// Опции вызывающего кода не должны изменяться
+func TestWatch_DoesNotModifyOptions(t *testing.T) {
+	opts := make([]LoaderOption, 1, 2)
+	opts[0] = WithEnvPrefix("WATCH")
+
+	_, err := Watch(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"), &WatchTestConfig{}, func(_, _ interface{}) {}, opts...)
+	if err == nil {
+		t.Error("Expected error for missing config file")
+	}
+	if opts[:2][1] != nil {
+		t.Error("Watch must not append to the caller's slice")
+	}
+}